@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeClipboard is a ClipboardProvider that plays back canned reads, one per
+// call, repeating the last one when they run out
+type fakeClipboard struct {
+	reads   []fakeRead
+	calls   int
+	written []string
+}
+
+type fakeRead struct {
+	text string
+	err  error
+}
+
+func (c *fakeClipboard) Read() (string, error) {
+	read := c.reads[min(c.calls, len(c.reads)-1)]
+	c.calls++
+	return read.text, read.err
+}
+
+func (c *fakeClipboard) Write(text string) error {
+	c.written = append(c.written, text)
+	return nil
+}
+
+// useFakeClipboard makes the clipboard play back reads for the rest of the
+// test
+func useFakeClipboard(t *testing.T, retries int, reads ...fakeRead) *fakeClipboard {
+	t.Helper()
+	fake := &fakeClipboard{reads: reads}
+	previous, previousRetries := clipboardProvider, appConfig.ClipboardRetries
+	clipboardProvider, appConfig.ClipboardRetries = fake, retries
+	t.Cleanup(func() { clipboardProvider, appConfig.ClipboardRetries = previous, previousRetries })
+	return fake
+}
+
+func TestGetClipboardTextRetries(t *testing.T) {
+	errBusy := errors.New("selection owner changed")
+
+	tests := []struct {
+		name      string
+		retries   int
+		reads     []fakeRead
+		want      string
+		wantErr   bool
+		wantCalls int
+	}{
+		{"first read", 2, []fakeRead{{text: "hello"}}, "hello", false, 1},
+		{"empty then text", 2, []fakeRead{{}, {text: "hello"}}, "hello", false, 2},
+		{"error then text", 2, []fakeRead{{err: errBusy}, {text: "hello"}}, "hello", false, 2},
+		{"really empty", 2, []fakeRead{{}}, "", false, 3},
+		{"keeps failing", 2, []fakeRead{{err: errBusy}}, "", true, 3},
+		{"no retries", 0, []fakeRead{{}, {text: "late"}}, "", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := useFakeClipboard(t, tt.retries, tt.reads...)
+			got, err := getClipboardText()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errBusy) {
+				t.Errorf("err = %v, want it to wrap the read error", err)
+			}
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+			if fake.calls != tt.wantCalls {
+				t.Errorf("%d read(s), want %d", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetClipboardTextSizeLimit(t *testing.T) {
+	limit := appConfig.MaxClipboardSize
+	appConfig.MaxClipboardSize = 16
+	t.Cleanup(func() { appConfig.MaxClipboardSize = limit })
+
+	useFakeClipboard(t, 0, fakeRead{text: strings.Repeat("x", 16)})
+	if text, err := getClipboardText(); err != nil || len(text) != 16 {
+		t.Errorf("at the limit: got %d bytes, %v", len(text), err)
+	}
+
+	useFakeClipboard(t, 0, fakeRead{text: strings.Repeat("x", 17)})
+	if _, err := getClipboardText(); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("over the limit: got %v, want a too large error", err)
+	}
+}
+
+func TestLexerExtension(t *testing.T) {
+	tests := map[string]string{
+		"json":      ".json",
+		"python":    ".py",
+		"go":        ".go",
+		"bash":      ".sh",
+		"":          "",
+		"no-such-x": "",
+	}
+	for name, want := range tests {
+		if got := lexerExtension(name); got != want {
+			t.Errorf("lexerExtension(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCollectCommitsGroupsByCommitID(t *testing.T) {
+	ptDir := t.TempDir()
+	base := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+
+	backups := []struct {
+		name     string
+		commitID string
+		offset   time.Duration
+	}{
+		{"a.txt.1", "c1", 0},
+		{"b.txt.1", "c1", 10 * time.Second},
+		{"a.txt.2", "c2", 30 * time.Second}, // same message, inside the window
+		{"a.txt.3", "", time.Hour},          // no commit_id: grouped by time
+		{"b.txt.2", "", time.Hour + time.Second},
+	}
+	for _, b := range backups {
+		backupPath := filepath.Join(ptDir, b.name)
+		if err := os.WriteFile(backupPath, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		metadata := &BackupMetadata{Comment: "commit: wip", Timestamp: base.Add(b.offset), CommitID: b.commitID}
+		if err := writeBackupMetadata(backupPath, metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commits, err := collectCommits(ptDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int
+	for _, commit := range commits {
+		sizes = append(sizes, len(commit.Files))
+	}
+	if len(sizes) != 3 || sizes[0]+sizes[1]+sizes[2] != 5 {
+		t.Fatalf("got commits of %v files, want 3 commits covering 5 backups", sizes)
+	}
+}
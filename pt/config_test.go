@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigRulesAgree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pt.yml")
+	data := "clipboard_retries: 99\nnotification_batch_ms: -1\nnormalize_line_endings: cr\nbackup_dir_name: .pt\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configEnvVar, path)
+	t.Chdir(t.TempDir())
+	origins, source, local := configOrigins, configSource, localConfigPath
+	t.Cleanup(func() { configOrigins, configSource, localConfigPath = origins, source, local })
+
+	problems, err := validateConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 3 {
+		t.Fatalf("validate found %q, want 3 problems", problems)
+	}
+	for _, key := range []string{"clipboard_retries", "notification_batch_ms", "normalize_line_endings"} {
+		if !strings.Contains(strings.Join(problems, "\n"), key) {
+			t.Errorf("validate did not report %s: %q", key, problems)
+		}
+	}
+
+	// loadConfig falls back on exactly the values validate rejects
+	config := loadConfig()
+	defaults := getDefaultConfig()
+	if config.ClipboardRetries != defaults.ClipboardRetries ||
+		config.NotificationBatchMs != defaults.NotificationBatchMs ||
+		config.NormalizeLineEndings != defaults.NormalizeLineEndings {
+		t.Errorf("loadConfig kept a rejected value: %+v", config)
+	}
+	for _, key := range []string{"clipboard_retries", "notification_batch_ms", "normalize_line_endings"} {
+		if !strings.HasPrefix(configOrigins[key], "default") {
+			t.Errorf("origin of %s = %q, want a default", key, configOrigins[key])
+		}
+	}
+}
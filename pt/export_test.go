@@ -0,0 +1,34 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExportRelPath(t *testing.T) {
+	ptDir := filepath.Join(t.TempDir(), "proj", appConfig.BackupDirName)
+	root := filepath.Dir(ptDir)
+	oldRoot := filepath.Join(filepath.Dir(root), "old-name")
+
+	tests := []struct {
+		name      string
+		backupDir string
+		original  string
+		want      string
+		ok        bool
+	}{
+		{"inside the project", "src_m.txt", filepath.Join(root, "src", "m.txt"), filepath.Join("src", "m.txt"), true},
+		{"project moved", "src_m.txt", filepath.Join(oldRoot, "src", "m.txt"), filepath.Join("src", "m.txt"), true},
+		{"top-level file, project moved", "t.txt", filepath.Join(oldRoot, "t.txt"), "t.txt", true},
+		{"original names another file", "src_m.txt", filepath.Join(oldRoot, "lib", "x.txt"), "", false},
+		{"outside any project", "m.txt", "/elsewhere", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := exportRelPath(ptDir, filepath.Join(ptDir, tt.backupDir), tt.original)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("exportRelPath = %q, %v; want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newIndexedStore creates a .pt store whose index holds n entries and one
+// real backup with its sidecar, returning the store and the backup path
+func newIndexedStore(tb testing.TB, n int) (string, string) {
+	tb.Helper()
+	ptDir := filepath.Join(tb.TempDir(), appConfig.BackupDirName)
+	backupDir := filepath.Join(ptDir, "main.go")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		tb.Fatal(err)
+	}
+
+	idx := &backupIndex{Version: 1, Entries: make(map[string]backupIndexEntry, n)}
+	for i := 0; i < n; i++ {
+		idx.Entries[fmt.Sprintf("file%d.go/file%d_go.%d", i%50, i%50, i)] = backupIndexEntry{
+			Metadata:    BackupMetadata{Comment: "commit: some message", Timestamp: time.Now(), Size: 1234, Original: "/src/file.go"},
+			SidecarSize: 200,
+			SidecarTime: time.Now(),
+		}
+	}
+	if err := writeBackupIndex(ptDir, idx); err != nil {
+		tb.Fatal(err)
+	}
+
+	backupPath := filepath.Join(backupDir, "main_go.20250101_120000.000000_abcd1234")
+	if err := os.WriteFile(backupPath, []byte("package main\n"), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	if err := writeBackupMetadata(backupPath, &BackupMetadata{Comment: "first"}); err != nil {
+		tb.Fatal(err)
+	}
+	return ptDir, backupPath
+}
+
+func TestBackupIndexJournalReplay(t *testing.T) {
+	ptDir, backupPath := newIndexedStore(t, 10)
+
+	if err := writeBackupMetadata(backupPath, &BackupMetadata{Comment: "second"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line torn by a crash must not hide the entries around it
+	journal := filepath.Join(ptDir, backupIndexJournal)
+	f, err := os.OpenFile(journal, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("journal not written: %v", err)
+	}
+	f.WriteString(`{"key":"torn","entr` + "\n")
+	f.Close()
+
+	idx := readBackupIndex(ptDir)
+	if idx == nil {
+		t.Fatal("index not readable")
+	}
+	if len(idx.Entries) != 11 {
+		t.Errorf("got %d entries, want 11", len(idx.Entries))
+	}
+	entry, ok := idx.Entries["main.go/main_go.20250101_120000.000000_abcd1234"]
+	if !ok || entry.Metadata.Comment != "second" {
+		t.Errorf("journal entry = %+v (found %v), want comment %q", entry.Metadata, ok, "second")
+	}
+
+	// The journal only appends: index.json itself is untouched
+	data, err := os.ReadFile(filepath.Join(ptDir, backupIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var onDisk backupIndex
+	if err := json.Unmarshal(data, &onDisk); err != nil {
+		t.Fatal(err)
+	}
+	if len(onDisk.Entries) != 10 {
+		t.Errorf("index.json has %d entries, want 10", len(onDisk.Entries))
+	}
+}
+
+func TestUpdateBackupIndexWithoutIndex(t *testing.T) {
+	ptDir := filepath.Join(t.TempDir(), appConfig.BackupDirName)
+	backupPath := filepath.Join(ptDir, "a.txt", "a_txt.1")
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(backupPath, []byte("a"), 0644)
+
+	if err := writeBackupMetadata(backupPath, &BackupMetadata{Comment: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(ptDir, backupIndexJournal)); !os.IsNotExist(err) {
+		t.Errorf("a store without index.json got a journal (err=%v)", err)
+	}
+}
+
+// benchmarkIndexSize is the number of entries in the benchmark stores, the
+// size of a store with a long history
+const benchmarkIndexSize = 20000
+
+// BenchmarkBackupIndexRewrite is what every backup used to cost: read,
+// parse and rewrite the whole index
+func BenchmarkBackupIndexRewrite(b *testing.B) {
+	ptDir, backupPath := newIndexedStore(b, benchmarkIndexSize)
+	sidecar, err := os.Stat(backupPath + ".meta.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := readBackupIndex(ptDir)
+		idx.Entries["main.go/main_go.20250101_120000.000000_abcd1234"] = backupIndexEntry{
+			Metadata:    BackupMetadata{Comment: "x"},
+			SidecarSize: sidecar.Size(),
+			SidecarTime: sidecar.ModTime(),
+		}
+		if err := writeBackupIndex(ptDir, idx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkUpdateBackupIndex is the journal append a backup costs now
+func BenchmarkUpdateBackupIndex(b *testing.B) {
+	_, backupPath := newIndexedStore(b, benchmarkIndexSize)
+	metadata := &BackupMetadata{Comment: "x"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		updateBackupIndex(backupPath, metadata)
+	}
+}
+
+func TestBackupIndexBesideSameNamedFile(t *testing.T) {
+	root := t.TempDir()
+	ptDir := filepath.Join(root, appConfig.BackupDirName)
+	for _, name := range []string{"index.json", backupIndexFile, lockFileName} {
+		dir, err := localStore{}.Dir(ptDir, filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base := filepath.Base(dir); containsString(storeReservedNames, base) {
+			t.Errorf("backups of %s go to %s, which pt uses itself", name, dir)
+		}
+	}
+
+	if err := os.MkdirAll(ptDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeBackupIndex(ptDir, &backupIndex{Version: 1, Entries: map[string]backupIndexEntry{}}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(ptDir, backupIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0644 {
+		t.Errorf("index mode = %o, want 644", perm)
+	}
+}
+
+func TestUpdateBackupIndexNeedsRegularFile(t *testing.T) {
+	ptDir := filepath.Join(t.TempDir(), appConfig.BackupDirName)
+	backupDir := filepath.Join(ptDir, "main.go")
+	// A directory in the index's place is not an index
+	if err := os.MkdirAll(filepath.Join(ptDir, backupIndexFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	backupPath := filepath.Join(backupDir, "main_go.20250101_120000.000000_abcd1234")
+	if err := writeBackupMetadata(backupPath, &BackupMetadata{Comment: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(ptDir, backupIndexJournal)); !os.IsNotExist(err) {
+		t.Errorf("journal written next to a directory: %v", err)
+	}
+}
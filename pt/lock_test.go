@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// deadPID returns the pid of a process that has already exited
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestAcquirePTLockUsesStoreRoot(t *testing.T) {
+	root := t.TempDir()
+	ptDir := filepath.Join(root, appConfig.BackupDirName)
+	sub := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(ptDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file below the root locks the root's store, not a .pt of its own
+	if err := acquirePTLock(filepath.Join(sub, "main.go")); err != nil {
+		t.Fatalf("acquirePTLock: %v", err)
+	}
+	lockPath := filepath.Join(ptDir, lockFileName)
+	if pid := readLockPID(lockPath); pid != os.Getpid() {
+		t.Errorf("lock pid = %d, want %d", pid, os.Getpid())
+	}
+
+	releasePTLock()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock still there after release: %v", err)
+	}
+}
+
+func TestAcquirePTLockRemovesStaleLock(t *testing.T) {
+	root := t.TempDir()
+	ptDir := filepath.Join(root, appConfig.BackupDirName)
+	if err := os.MkdirAll(ptDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lockPath := filepath.Join(ptDir, lockFileName)
+
+	for name, pid := range map[string]int{
+		"dead holder": deadPID(t),
+		"own pid":     os.Getpid(),
+	} {
+		if err := os.WriteFile(lockPath, []byte(fmt.Sprintf("%d\n", pid)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := acquirePTLock(root); err != nil {
+			t.Fatalf("%s: acquirePTLock: %v", name, err)
+		}
+		releasePTLock()
+	}
+}
+
+func TestAcquirePTLockWithoutStore(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := acquirePTLock(dir); err != nil {
+		t.Fatalf("acquirePTLock: %v", err)
+	}
+	if activeLock != "" {
+		t.Errorf("locked %s with no backup directory", activeLock)
+	}
+}
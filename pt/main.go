@@ -12,17 +12,26 @@ import (
 	"runtime"
 	// "syscall"
 	"crypto/rand"
+	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"context"
@@ -34,6 +43,7 @@ import (
 	// "github.com/alecthomas/chroma/v2/quick" // Import chroma quick for syntax highlighting
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
 	"golang.org/x/term"
@@ -51,6 +61,11 @@ const (
 	DefaultMaxFilenameLen   = 200                // Max filename length
 	DefaultBackupDirName    = ".pt"              // Git-like hidden directory
 	DefaultMaxSearchDepth   = 10                 // Max directory depth for recursive search
+	DefaultMaxWorkers       = 0                  // Status workers (0 = one per CPU)
+	DefaultLogFileMaxSize   = 10 * 1024 * 1024   // Rotate log_file past 10MB
+	DefaultClipboardRetries = 2                  // Extra clipboard reads after a failed one
+	DefaultNotificationBatchMs = 1000            // Monitor notifications within 1s are sent as one
+	DefaultMonitorThrottleMs = 2000              // Monitor backs up each file at most every 2s
 )
 
 // Version will be loaded from VERSION file
@@ -66,14 +81,41 @@ type MenuIconsConfig struct {
 	Exit         string `yaml:"exit"`
 }
 
+// MonitorConfig is the default watch profile for pt monitor
+type MonitorConfig struct {
+	Paths      []string `yaml:"paths"`      // Watched when pt monitor is given no paths
+	Exceptions []string `yaml:"exceptions"` // Always excluded, on top of -e
+}
+
 type Config struct {
 	MaxClipboardSize int              `yaml:"max_clipboard_size"`
 	MaxBackupCount   int              `yaml:"max_backup_count"`
 	MaxFilenameLen   int              `yaml:"max_filename_length"`
 	BackupDirName    string           `yaml:"backup_dir_name"`
 	MaxSearchDepth   int              `yaml:"max_search_depth"`
+	FollowSymlinks   bool             `yaml:"follow_symlinks"`
+	MaxWorkers       int              `yaml:"max_workers"`
 	DiffTool         string           `yaml:"diff_tool"`
+	Theme            string           `yaml:"theme"`            // Default show/-z theme, or "auto"
 	AutoBackup      *bool             `yaml:"auto_backup"`
+	OnChangeCmd     string            `yaml:"on_change_cmd"`    // Command monitor runs after a change
+	SplitMarker     string            `yaml:"split_marker"`     // Section header regexp for pt split
+	TabWidth        int               `yaml:"tab_width"`        // pt show tab stops (0 = leave tabs alone)
+	LogFile         string            `yaml:"log_file"`         // Persistent audit log, written even without --debug
+	ClipboardRetries int              `yaml:"clipboard_retries"` // Re-reads after a failed/empty clipboard read
+	UseTrash        bool              `yaml:"use_trash"`        // pt -rm moves files to the OS trash
+	CheckIgnoreWhitespace bool        `yaml:"check_ignore_whitespace"` // pt check ignores trailing whitespace/line endings
+	NormalizeLineEndings string       `yaml:"normalize_line_endings"` // off, lf or crlf: compare files with line endings unified
+	NormalizeStoredLineEndings bool   `yaml:"normalize_stored_line_endings"` // Also write backups with normalize_line_endings applied
+	MergeTool       string            `yaml:"merge_tool"`       // Three-way merge tool for pt merge (kdiff3, meld, ...)
+	TimeFormat      string            `yaml:"time_format"`      // Go layout for displayed times, or "iso8601"
+	UseUTC          bool              `yaml:"use_utc"`          // Show and name backups in UTC instead of local time
+	UpdateCheck     *bool             `yaml:"update_check"`     // Allow pt version --check to query for releases
+	UpdateURL       string            `yaml:"update_url"`       // Release feed for pt version --check
+	Monitor         MonitorConfig     `yaml:"monitor"`          // Default paths/exceptions for pt monitor
+	NotificationBackend string        `yaml:"notification_backend"` // Monitor notifications: auto, gntp, libnotify, toast, stdout, none
+	NotificationBatchMs int           `yaml:"notification_batch_ms"` // Window for merging monitor notifications (0 = one per file)
+	MonitorThrottleMs int             `yaml:"monitor_throttle_ms"` // Least time between two monitor backups of a file (0 = no limit)
 	TrayIcon        string            `yaml:"tray_icon"`        // Main tray icon
 	MenuIconsDir    string            `yaml:"menu_icons_dir"`   // Directory for menu icons
 	MenuIcons       MenuIconsConfig   `yaml:"menu_icons"`       // Individual menu icon names
@@ -83,8 +125,107 @@ type Config struct {
 var appConfig *Config
 var debugMode bool = false
 var difftool string = "delta"
+
+// infoOut receives the progress messages of the backup helpers ("Backup
+// created", "Comparing with last backup", store notes, file search). pt
+// monitor --quiet points it at io.Discard before it starts watching, and
+// pt -r --print-path at os.Stderr so stdout carries only the path.
+var infoOut io.Writer = os.Stdout
+var diffContext = -1 // Lines of context from --context; -1 keeps each tool's default
+var diffExternal bool = false // --external: prefer a GUI diff tool
+var diffNoHighlight bool = false // --no-highlight: built-in diff without syntax highlighting
+var activeCommitID string // Set while pt commit runs; stamped on every backup it makes
+var statusIgnoreWhitespace bool = false // pt check --ignore-whitespace; status only, never what is backed up
 var foundZ bool = false
 var checkBefore bool = false
+var followSymlinks bool = false
+// Exit codes returned by main so scripts can tell failure classes apart
+const (
+	exitOK             = 0
+	exitError          = 1 // any other failure
+	exitNotFound       = 2 // file could not be resolved
+	exitClipboardEmpty = 3 // clipboard had nothing to write/diff
+	exitNoBackups      = 4 // file has no backups to restore/diff
+	exitToolMissing    = 5 // external diff tool not installed
+	exitInterrupted    = 130 // stopped by Ctrl+C / SIGTERM (128 + SIGINT)
+)
+
+// Sentinel errors mapped to the exit codes above by exitCodeFor. Wrap them
+// with %w so the message still reads naturally.
+var (
+	errNotFound       = errors.New("not found")
+	errClipboardEmpty = errors.New("clipboard is empty")
+	errNoBackups      = errors.New("no backups found")
+	errToolMissing    = errors.New("is not installed")
+	errInterrupted    = errors.New("interrupted")
+)
+
+// exitCodeFor maps an error returned by a command handler to an exit code
+func exitCodeFor(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errNotFound):
+		return exitNotFound
+	case errors.Is(err, errClipboardEmpty):
+		return exitClipboardEmpty
+	case errors.Is(err, errNoBackups):
+		return exitNoBackups
+	case errors.Is(err, errToolMissing):
+		return exitToolMissing
+	case errors.Is(err, errInterrupted):
+		return exitInterrupted
+	default:
+		return exitError
+	}
+}
+
+// hintError is an error with a suggestion for fixing it, shown dimmed on
+// the line below the error message
+type hintError struct {
+	err  error
+	hint string
+}
+
+func (e *hintError) Error() string { return e.err.Error() }
+func (e *hintError) Unwrap() error { return e.err }
+
+// withHint attaches a remediation hint to err; errors.Is still sees through it
+func withHint(err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &hintError{err: err, hint: hint}
+}
+
+// errorHint returns the hint attached with withHint, or a generic one for
+// the sentinel errors above
+func errorHint(err error) string {
+	var he *hintError
+	if errors.As(err, &he) {
+		return he.hint
+	}
+	switch {
+	case errors.Is(err, errNotFound):
+		return "check the name, or run pt from the directory that contains the file"
+	case errors.Is(err, errClipboardEmpty):
+		return "copy something first; 'pt doctor' checks that the clipboard is reachable"
+	case errors.Is(err, errNoBackups):
+		return "create one with 'pt backup <file>' or 'pt commit'"
+	case errors.Is(err, errToolMissing):
+		return "use --tool pdiff2 for the built-in diff, or set diff_tool with 'pt config edit'"
+	}
+	return ""
+}
+
+// printError prints a command error and its hint, if any
+func printError(err error) {
+	fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
+	if hint := errorHint(err); hint != "" {
+		fmt.Printf("%s   💡 %s%s\n", ColorGray, hint, ColorReset)
+	}
+}
+
 // Global filesystem variable - defaults to OS filesystem
 var fs afero.Fs = afero.NewOsFs()
 
@@ -153,16 +294,27 @@ type BackupInfo struct {
 	ModTime time.Time
 	Size    int64
 	Comment string
+	Pinned  bool
 }
 
 // BackupMetadata stores metadata for backup files
 type BackupMetadata struct {
-	Comment   string    `json:"comment"`
-	Timestamp time.Time `json:"timestamp"`
-	Size      int64     `json:"size"`
-	Original  string    `json:"original_file"`
+	Comment         string    `json:"comment"`
+	Timestamp       time.Time `json:"timestamp"`
+	Size            int64     `json:"size"`
+	Original        string    `json:"original_file"`
+	OriginalModTime time.Time `json:"original_mtime,omitzero"` // mtime of the file when it was backed up
+	Pinned          bool      `json:"pinned,omitempty"`        // exempt from the max_backup_count cap
+	CommitID        string    `json:"commit_id,omitempty"`     // shared by the backups of one pt commit
+}
+
+// BackupDirConfig holds per-file settings stored next to a file's backups
+type BackupDirConfig struct {
+	Keep int `json:"keep,omitempty"` // overrides max_backup_count when > 0
 }
 
+const backupDirConfigFile = ".config.json"
+
 type CommandInfo struct {
     Command    string
     Files      []string
@@ -212,13 +364,14 @@ func (fs FileStatus) Color() string {
 
 // FileStatusInfo holds file status information
 type FileStatusInfo struct {
-	Path     string
-	RelPath  string
-	Status   FileStatus
-	Size     int64
-	ModTime  time.Time
-	IsDir    bool
-	Children []*FileStatusInfo
+	Path       string
+	RelPath    string
+	Status     FileStatus
+	Size       int64
+	ModTime    time.Time
+	LastBackup time.Time // zero when the file has no backup
+	IsDir      bool
+	Children   []*FileStatusInfo
 }
 
 // FileSearchResult for recursive file search
@@ -234,7 +387,15 @@ type FileSearchResult struct {
 type OrphanedBackup struct {
 	BackupDir    string
 	ExpectedPath string
-	ActualFiles  []string
+	Candidates   []fixCandidate // best match first
+}
+
+// fixCandidate is a file that may be where an orphaned backup's file went
+type fixCandidate struct {
+	Path       string
+	NameMatch  bool    // same base name as the missing file
+	Identical  bool    // same content as the newest backup
+	Similarity float64 // lineSimilarity with the newest backup
 }
 
 // TreeNode represents a node in the directory tree
@@ -243,12 +404,17 @@ type TreeNode struct {
 	Path     string
 	IsDir    bool
 	Size     int64
+	ModTime  time.Time
 	Children []*TreeNode
 }
 
 // GitIgnore holds gitignore patterns
 type GitIgnore struct {
 	patterns []string
+	// includes holds .ptinclude patterns; when non-empty only matching
+	// files are tracked
+	includes []string
+	root     string
 }
 
 // Logger for audit trail
@@ -270,12 +436,105 @@ func init() {
 }
 
 // setupLogger initializes the global logger based on the debugMode flag.
+// With log_file set, output is also appended to that file.
 func setupLogger() {
+	var writers []io.Writer
 	if debugMode {
-		logger = log.New(os.Stderr, "", log.LstdFlags)
-	} else {
-		logger = log.New(&discardWriter{}, "", log.LstdFlags)
+		writers = append(writers, os.Stderr)
+	}
+	if appConfig.LogFile != "" {
+		file, err := openLogFile(appConfig.LogFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s⚠️  Warning: cannot open log_file: %v%s\n", ColorYellow, err, ColorReset)
+		} else {
+			writers = append(writers, file)
+		}
+	}
+
+	flags := log.LstdFlags
+	if appConfig.UseUTC {
+		flags |= log.LUTC
+	}
+
+	switch len(writers) {
+	case 0:
+		logger = log.New(&discardWriter{}, "", flags)
+	case 1:
+		logger = log.New(writers[0], "", flags)
+	default:
+		logger = log.New(io.MultiWriter(writers...), "", flags)
+	}
+}
+
+// expandHome replaces a leading ~/ in a path from the config with the home
+// directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// openLogFile opens path for appending. A file that has grown past
+// DefaultLogFileMaxSize is first rotated to path.1, replacing the previous one.
+func openLogFile(path string) (*os.File, error) {
+	path = expandHome(path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() >= DefaultLogFileMaxSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return nil, fmt.Errorf("failed to rotate %s: %w", path, err)
+		}
+	}
+
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+}
+
+// auditLog records an operation that changed a file as one key=value line,
+// e.g. audit op=backup file="/src/a.go" size=120 backup="a_go.2025...".
+// extra holds further key, value pairs.
+func auditLog(op, file string, size int64, extra ...string) {
+	line := fmt.Sprintf("audit op=%s file=%q size=%d", op, file, size)
+	for i := 0; i+1 < len(extra); i += 2 {
+		line += fmt.Sprintf(" %s=%q", extra[i], extra[i+1])
+	}
+	logger.Print(line)
+}
+
+// Themes picked by --theme auto
+const (
+	autoThemeDark  = "monokai"
+	autoThemeLight = "github"
+)
+
+// resolveTheme maps "auto" to a light or dark chroma style based on the
+// terminal background advertised in $COLORFGBG ("fg;bg", set by rxvt,
+// Konsole, iTerm2 and others). Any other name is returned unchanged; when
+// the background can't be determined a dark theme is assumed.
+func resolveTheme(name string) string {
+	if !strings.EqualFold(name, "auto") {
+		return name
+	}
+
+	fields := strings.Split(os.Getenv("COLORFGBG"), ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		logger.Printf("Theme auto: no usable COLORFGBG, using %s", autoThemeDark)
+		return autoThemeDark
+	}
+
+	// ANSI colours 7 (white) and 9-15 (bright) are light backgrounds
+	if bg == 7 || (bg >= 9 && bg <= 15) {
+		logger.Printf("Theme auto: light background (COLORFGBG bg=%d), using %s", bg, autoThemeLight)
+		return autoThemeLight
 	}
+
+	logger.Printf("Theme auto: dark background (COLORFGBG bg=%d), using %s", bg, autoThemeDark)
+	return autoThemeDark
 }
 
 func getTerminalWidth() int {
@@ -286,10 +545,157 @@ func getTerminalWidth() int {
     return width
 }
 
+// tableColumn is one column of a box-drawn table. width is the content
+// width; every column adds two spaces of padding and one border.
+type tableColumn struct {
+	header string
+	width  int
+	right  bool
+}
+
+// cellWidth returns the number of terminal columns s occupies, skipping ANSI
+// escape sequences and counting emoji (like the pin marker) as two columns.
+func cellWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+			}
+		case r == '\x1b':
+			inEscape = true
+		case r >= 0x1F300:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// truncateCell shortens s to at most width columns, ending in "..." when
+// anything was cut.
+func truncateCell(s string, width int) string {
+	if cellWidth(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return strings.Repeat(".", max(width, 0))
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := cellWidth(string(r))
+		if used+w > width-3 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + "..."
+}
+
+// truncateCellLeft is truncateCell for paths: it keeps the end of s, which
+// is usually the part that tells entries apart.
+func truncateCellLeft(s string, width int) string {
+	if cellWidth(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return strings.Repeat(".", max(width, 0))
+	}
+	runes := []rune(s)
+	used := 0
+	start := len(runes)
+	for start > 0 {
+		w := cellWidth(string(runes[start-1]))
+		if used+w > width-3 {
+			break
+		}
+		used += w
+		start--
+	}
+	return "..." + string(runes[start:])
+}
+
+func padCell(s string, width int, right bool) string {
+	gap := width - cellWidth(s)
+	if gap <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", gap) + s
+	}
+	return s + strings.Repeat(" ", gap)
+}
+
+// tableRule draws a horizontal border such as ┌───┬───┐ matching cols.
+func tableRule(left, mid, right string, cols []tableColumn) string {
+	parts := make([]string, len(cols))
+	for i, col := range cols {
+		parts[i] = strings.Repeat("─", col.width+2)
+	}
+	return ColorGray + left + strings.Join(parts, mid) + right + ColorReset
+}
+
+// tableRow draws one row of cells, which must already fit their columns.
+func tableRow(cols []tableColumn, cells []string) string {
+	var b strings.Builder
+	b.WriteString(ColorGray + "│" + ColorReset)
+	for i, col := range cols {
+		b.WriteString(" " + padCell(cells[i], col.width, col.right) + " ")
+		b.WriteString(ColorGray + "│" + ColorReset)
+	}
+	return b.String()
+}
+
+// tableHeader draws the header row of cols.
+func tableHeader(cols []tableColumn) string {
+	cells := make([]string, len(cols))
+	for i, col := range cols {
+		cells[i] = ColorBold + ColorYellow + truncateCell(col.header, col.width) + ColorReset
+	}
+	return tableRow(cols, cells)
+}
+
+// tableOverhead is the width taken by borders and padding in a table of n
+// columns.
+func tableOverhead(n int) int {
+	return 3*n + 1
+}
+
 // ============================================================================
 // SHOW COMMAND - Display file content with syntax highlighting (like bat)
 // ============================================================================
 
+// expandTabs replaces each tab with spaces up to the next multiple of width,
+// so columns line up the same way they do in an editor
+func expandTabs(content string, width int) string {
+	if width <= 0 || !strings.Contains(content, "\t") {
+		return content
+	}
+
+	var b strings.Builder
+	col := 0
+	for _, r := range content {
+		switch r {
+		case '\t':
+			spaces := width - col%width
+			b.WriteString(strings.Repeat(" ", spaces))
+			col += spaces
+		case '\n':
+			b.WriteRune(r)
+			col = 0
+		default:
+			b.WriteRune(r)
+			col++
+		}
+	}
+	return b.String()
+}
+
 func handleShowCommand(args []string) error {
 	if len(args) < 1 {
 		return fmt.Errorf("filename required for show command")
@@ -298,12 +704,80 @@ func handleShowCommand(args []string) error {
 	filename := args[0]
 	lexerName := ""
 	themeName := "fruity"
+	if appConfig.Theme != "" {
+		themeName = appConfig.Theme
+	}
 	showLineNumbers := true
 	showGrid := true
+	// Page only output taller than the terminal unless --pager forces it
 	usePager := true
+	forcePager := false
+	showDiff := false
+	plain := false
+	wrap := false
+	showWhitespace := false
+	tabWidth := appConfig.TabWidth
+	findText := ""
+	findWindow := 20
+	exportFormat := ""
+	outPath := ""
+	maxLines := 0
+	follow := false
+	onlyChanges := false
+	contextSet := false
 
 	for i := 1; i < len(args); i++ {
 		switch args[i] {
+		case "--follow", "-F":
+			follow = true
+		case "--changes":
+			onlyChanges = true
+		case "--max-lines":
+			if i+1 < len(args) {
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n < 0 {
+					return fmt.Errorf("--max-lines requires a non-negative number, got %q", args[i+1])
+				}
+				maxLines = n
+				i++
+			}
+		case "--output":
+			if i+1 < len(args) {
+				exportFormat = strings.ToLower(args[i+1])
+				i++
+			}
+			if exportFormat != "html" && exportFormat != "svg" {
+				return fmt.Errorf("--output must be html or svg, got %q", exportFormat)
+			}
+		case "--out", "-o":
+			if i+1 < len(args) {
+				outPath = args[i+1]
+				i++
+			}
+		case "--find":
+			if i+1 < len(args) {
+				findText = args[i+1]
+				i++
+			}
+		case "--context":
+			if i+1 < len(args) {
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n < 0 {
+					return fmt.Errorf("--context requires a non-negative number, got %q", args[i+1])
+				}
+				findWindow = n
+				contextSet = true
+				i++
+			}
+		case "--tabs":
+			if i+1 < len(args) {
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n < 0 || n > 16 {
+					return fmt.Errorf("--tabs requires a number from 0 to 16, got %q", args[i+1])
+				}
+				tabWidth = n
+				i++
+			}
 		case "--lexer", "-l":
 			if i+1 < len(args) {
 				lexerName = args[i+1]
@@ -318,36 +792,118 @@ func handleShowCommand(args []string) error {
 			showLineNumbers = false
 		case "--no-grid", "-ng":
 			showGrid = false
+		case "--pager", "-p":
+			usePager, forcePager = true, true
 		case "--no-pager", "-np":
-			usePager = false
+			usePager, forcePager = false, false
+		case "--diff":
+			showDiff = true
+		case "--plain":
+			plain = true
+		case "--wrap", "-w":
+			wrap = true
+		case "--no-wrap":
+			wrap = false
+		case "--show-whitespace", "--highlight-trailing-whitespace":
+			showWhitespace = true
 		}
 	}
+	themeName = resolveTheme(themeName)
 
-	filePath, err := resolveFilePath(filename)
-	if err != nil {
-		return fmt.Errorf("file not found: %w", err)
+	// "-" reads the content from stdin (cat foo.go | pt show -): there is no
+	// file to stat, compare with backups or match a lexer by name
+	fromStdin := filename == "-"
+
+	if onlyChanges {
+		if fromStdin {
+			return fmt.Errorf("--changes needs a file, it can't be used with stdin")
+		}
+		if findText != "" || follow {
+			return fmt.Errorf("--changes can't be combined with --find or --follow")
+		}
+		// Changed lines get the gutter markers too
+		showDiff = true
+		if !contextSet {
+			findWindow = 3
+		}
 	}
 
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
+	if follow {
+		if fromStdin {
+			return fmt.Errorf("--follow needs a file, it can't be used with stdin")
+		}
+		// New lines are printed as they arrive, which a pager would hold back
+		usePager = false
+	}
+
+	var filePath, relPath string
+	var fileInfo os.FileInfo
+	var content []byte
+	var err error
+	status := FileStatusUnchanged
+
+	if fromStdin {
+		if showDiff {
+			return fmt.Errorf("--diff needs a file, it can't be used with stdin")
+		}
+		content, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		relPath = "<stdin>"
+	} else {
+		filePath, err = resolveFilePath(filename)
+		if err != nil {
+			return fmt.Errorf("file not found: %w", err)
+		}
+
+		fileInfo, err = os.Stat(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to stat file: %w", err)
+		}
+
+		if fileInfo.IsDir() {
+			return fmt.Errorf("cannot show directory, file required")
+		}
+
+		content, err = os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		status, _ = compareFileWithBackup(filePath)
+		relPath, _ = filepath.Rel(".", filePath)
 	}
 
-	if fileInfo.IsDir() {
-		return fmt.Errorf("cannot show directory, file required")
+	// --find narrows the output to a window around the first matching line
+	matchLine := -1
+	windowStart, windowEnd := 0, -1
+	if findText != "" {
+		for i, line := range strings.Split(string(content), "\n") {
+			if strings.Contains(line, findText) {
+				matchLine = i
+				break
+			}
+		}
+		if matchLine < 0 {
+			return fmt.Errorf("%q not found in %s", findText, filename)
+		}
+		windowStart = max(matchLine-findWindow, 0)
+		windowEnd = matchLine + findWindow
 	}
 
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+	if exportFormat != "" {
+		lexer := resolveShowLexer(lexerName, filePath, fromStdin, string(content))
+		return exportHighlighted(expandTabs(string(content), tabWidth), lexer, themeName, exportFormat, outPath, showLineNumbers)
 	}
 
-	status, _ := compareFileWithBackup(filePath)
+	// --follow continues after what was read now
+	followOffset := int64(len(content))
+	followNextLine := strings.Count(string(content), "\n") + 1
 
 	var output bytes.Buffer
 
 	// Print header
-	relPath, _ := filepath.Rel(".", filePath)
 	statusColor := status.Color()
 	statusSymbol := "●"
 
@@ -358,7 +914,7 @@ func handleShowCommand(args []string) error {
 	// }
 
 	if showGrid {
-	    line := "───────┬" + strings.Repeat("─", width-10)
+	    line := "───────┬" + strings.Repeat("─", max(width-10, 0))
 	    output.WriteString(fmt.Sprintf("%s%s%s\n", ColorGray, line, ColorReset))
 	}
 
@@ -368,11 +924,17 @@ func handleShowCommand(args []string) error {
 	}
 	output.WriteString("\n")
 
-	modTime := fileInfo.ModTime().Format("2006-01-02 15:04:05")
-	output.WriteString(fmt.Sprintf("%s       │%s %sSize:%s %s  %sModified:%s %s\n",
-		ColorGray, ColorReset,
-		ColorCyan, ColorReset, formatSize(fileInfo.Size()),
-		ColorCyan, ColorReset, modTime))
+	if fromStdin {
+		output.WriteString(fmt.Sprintf("%s       │%s %sSize:%s %s\n",
+			ColorGray, ColorReset,
+			ColorCyan, ColorReset, formatSize(int64(len(content)))))
+	} else {
+		modTime := formatTime(fileInfo.ModTime())
+		output.WriteString(fmt.Sprintf("%s       │%s %sSize:%s %s  %sModified:%s %s\n",
+			ColorGray, ColorReset,
+			ColorCyan, ColorReset, formatSize(fileInfo.Size()),
+			ColorCyan, ColorReset, modTime))
+	}
 
 	if lexerName != "" {
 		output.WriteString(fmt.Sprintf("%s       │%s %sLexer:%s %s  %sTheme:%s %s\n",
@@ -381,76 +943,241 @@ func handleShowCommand(args []string) error {
 			ColorCyan, ColorReset, themeName))
 	}
 
+	if matchLine >= 0 {
+		output.WriteString(fmt.Sprintf("%s       │%s %sFind:%s %q at line %d (±%d lines)\n",
+			ColorGray, ColorReset,
+			ColorCyan, ColorReset, findText, matchLine+1, findWindow))
+	}
+
+	// Gutter markers relative to the last backup (--diff); --changes also
+	// keeps only the changed regions plus findWindow lines around them
+	var markers []byte
+	var visible []bool
+	if showDiff {
+		backups, _ := listBackups(filePath)
+		if onlyChanges && len(backups) == 0 {
+			return withHint(fmt.Errorf("%w for: %s, nothing to compare against", errNoBackups, filePath),
+				"back it up first with: pt -b "+filename)
+		}
+		if len(backups) > 0 {
+			backupContent, err := backupStore.Read(backups[0].Path)
+			if err != nil {
+				return fmt.Errorf("failed to read backup: %w", err)
+			}
+			oldLines, newLines := splitLines(string(backupContent)), splitLines(string(content))
+			markers = lineChangeMarkers(oldLines, newLines)
+			if onlyChanges {
+				ranges := changedLineRanges(oldLines, newLines)
+				if len(ranges) == 0 {
+					fmt.Printf("%s✓ No changes since the last backup (%s)%s\n", ColorGreen, formatTime(backups[0].ModTime), ColorReset)
+					return nil
+				}
+				visible = make([]bool, len(newLines)+1)
+				for _, r := range ranges {
+					for i := max(r[0]-findWindow, 0); i <= min(r[1]+findWindow, len(visible)-1); i++ {
+						visible[i] = true
+					}
+				}
+				output.WriteString(fmt.Sprintf("%s       │%s %sChanges:%s %d region(s) (±%d lines)\n",
+					ColorGray, ColorReset, ColorCyan, ColorReset, len(ranges), findWindow))
+			}
+			output.WriteString(fmt.Sprintf("%s       │%s %sDiff:%s vs last backup %s  %s+%s added  %s~%s changed\n",
+				ColorGray, ColorReset,
+				ColorCyan, ColorReset, formatTime(backups[0].ModTime),
+				ColorGreen, ColorReset, ColorYellow, ColorReset))
+		} else {
+			output.WriteString(fmt.Sprintf("%s       │%s %sDiff:%s no backup to compare against\n",
+				ColorGray, ColorReset, ColorCyan, ColorReset))
+		}
+	}
+
 	// if showGrid {
 	// 	output.WriteString(fmt.Sprintf("%s───────┼────────────────────────────────────────────────────────────────%s\n", ColorGray, ColorReset))
 	// }
 
 	if showGrid {
-	    line := "───────┼" + strings.Repeat("─", width-10)
+	    line := "───────┼" + strings.Repeat("─", max(width-10, 0))
 	    output.WriteString(fmt.Sprintf("%s%s%s\n", ColorGray, line, ColorReset))
 	}
 
-	// Apply syntax highlighting
-	var lexer chroma.Lexer
-	if lexerName != "" {
-		lexer = lexers.Get(lexerName)
-	} else {
-		lexer = lexers.Match(filePath)
-	}
+	// Expand after the --diff markers were computed against the raw content
+	content = []byte(expandTabs(string(content), tabWidth))
 
-	if lexer == nil {
-		lexer = lexers.Fallback
-	}
-	lexer = chroma.Coalesce(lexer)
+	var contentBuf bytes.Buffer
+	if plain {
+		// --plain skips chroma entirely: faster on huge files and safe for
+		// content the lexers would mangle
+		contentBuf.Write(content)
+	} else {
+		// Apply syntax highlighting
+		lexer := resolveShowLexer(lexerName, filePath, fromStdin, string(content))
 
-	style := styles.Get(themeName)
-	if style == nil {
-		// style = styles.Monokai
-		style = styles.Get("monokai")
-	}
+		style := styles.Get(themeName)
+		if style == nil {
+			// style = styles.Monokai
+			style = styles.Get("monokai")
+		}
 
-	formatter := formatters.TTY16m
+		formatter := formatters.TTY16m
 
-	iterator, err := lexer.Tokenise(nil, string(content))
-	if err != nil {
-		return fmt.Errorf("failed to tokenize: %w", err)
-	}
+		iterator, err := lexer.Tokenise(nil, string(content))
+		if err != nil {
+			return fmt.Errorf("failed to tokenize: %w", err)
+		}
 
-	var contentBuf bytes.Buffer
-	err = formatter.Format(&contentBuf, style, iterator)
-	if err != nil {
-		return fmt.Errorf("failed to format: %w", err)
+		err = formatter.Format(&contentBuf, style, iterator)
+		if err != nil {
+			return fmt.Errorf("failed to format: %w", err)
+		}
 	}
 
 	// Add line numbers
-	if showLineNumbers {
+	if showLineNumbers || markers != nil || wrap || showWhitespace || matchLine >= 0 || maxLines > 0 || visible != nil {
 		lines := strings.Split(contentBuf.String(), "\n")
-		maxLineNum := len(lines)
+		if matchLine >= 0 {
+			windowEnd = min(windowEnd, len(lines)-1)
+		} else {
+			windowEnd = len(lines) - 1
+		}
+		maxLineNum := windowEnd + 1
 		lineNumWidth := len(fmt.Sprintf("%d", maxLineNum))
 
+		// The empty string after a final newline is not a line of its own
+		lastLine := windowEnd
+		if lastLine == len(lines)-1 && lastLine > 0 && lines[lastLine] == "" {
+			lastLine--
+		}
+
+		emitted := 0
+		prevShown := -1
 		for i, line := range lines {
+			// Slicing the highlighted lines keeps the original numbering
+			if i < windowStart || i > windowEnd {
+				continue
+			}
+			if visible != nil {
+				if i > lastLine || !visible[i] {
+					continue
+				}
+				// Mark the unchanged stretch skipped between two regions
+				if prevShown >= 0 && i != prevShown+1 {
+					output.WriteString(fmt.Sprintf("%s%*s%s\n", ColorGray, lineNumWidth+1, "⋮", ColorReset))
+				}
+				prevShown = i
+			}
+			// Followed lines pick up where the file ends
+			if follow && i > lastLine {
+				break
+			}
+			if maxLines > 0 && emitted == maxLines && i <= lastLine {
+				hint := "use --pager"
+				if usePager {
+					hint = "raise --max-lines"
+				}
+				output.WriteString(fmt.Sprintf("%s... (%d more lines, %s)%s\n", ColorYellow, lastLine-i+1, hint, ColorReset))
+				break
+			}
+			emitted++
 			lineNum := i + 1
-			if showGrid {
-				output.WriteString(fmt.Sprintf("%s%*d │%s %s\n", ColorGray, lineNumWidth, lineNum, ColorReset, line))
-			} else {
-				output.WriteString(fmt.Sprintf("%s%*d %s %s\n", ColorGray, lineNumWidth, lineNum, ColorReset, line))
+
+			// gutter prefixes the first row of a line, blank prefixes rows
+			// produced by --wrap; gutterWidth is their visible width
+			var gutter, blank string
+			gutterWidth := 0
+			if showLineNumbers {
+				pad := strings.Repeat(" ", lineNumWidth)
+				if markers != nil {
+					marker := renderGutterMarker(markers, i)
+					if showGrid {
+						gutter = fmt.Sprintf("%s%*d%s%s│%s ", ColorGray, lineNumWidth, lineNum, marker, ColorGray, ColorReset)
+						blank = fmt.Sprintf("%s%s │%s ", ColorGray, pad, ColorReset)
+						gutterWidth = lineNumWidth + 3
+					} else {
+						gutter = fmt.Sprintf("%s%*d%s%s ", ColorGray, lineNumWidth, lineNum, marker, ColorReset)
+						blank = pad + "  "
+						gutterWidth = lineNumWidth + 2
+					}
+				} else if showGrid {
+					gutter = fmt.Sprintf("%s%*d │%s ", ColorGray, lineNumWidth, lineNum, ColorReset)
+					blank = fmt.Sprintf("%s%s │%s ", ColorGray, pad, ColorReset)
+					gutterWidth = lineNumWidth + 3
+				} else {
+					gutter = fmt.Sprintf("%s%*d %s ", ColorGray, lineNumWidth, lineNum, ColorReset)
+					blank = pad + "  "
+					gutterWidth = lineNumWidth + 2
+				}
+			} else if markers != nil {
+				gutter = fmt.Sprintf("%s%s ", renderGutterMarker(markers, i), ColorReset)
+				blank = "  "
+				gutterWidth = 2
 			}
-		}
-	} else {
-		output.WriteString(contentBuf.String())
-	}
 
-	// Footer
-	// if showGrid {
-	// 	output.WriteString(fmt.Sprintf("%s───────┴────────────────────────────────────────────────────────────────%s\n", ColorGray, ColorReset))
-	// }
+			// The --find match gets a yellow gutter (or a ▶ without numbers)
+			if i == matchLine {
+				if showLineNumbers {
+					gutter = ColorYellow + ColorBold + strings.Replace(gutter, ColorGray, "", 1)
+				} else {
+					gutter = ColorYellow + "▶" + ColorReset + " " + gutter
+					gutterWidth += 2
+				}
+			} else if matchLine >= 0 && !showLineNumbers {
+				gutter = "  " + gutter
+				blank = "  " + blank
+				gutterWidth += 2
+			}
+
+			if showWhitespace {
+				line = markWhitespace(line)
+			}
+
+			rows := []string{line}
+			if wrap {
+				rows = wrapANSILine(line, width-gutterWidth)
+			}
+
+			output.WriteString(gutter + rows[0] + "\n")
+			for _, row := range rows[1:] {
+				output.WriteString(blank + row + "\n")
+			}
+		}
+	} else {
+		output.WriteString(contentBuf.String())
+	}
+
+	// Footer
+	// if showGrid {
+	// 	output.WriteString(fmt.Sprintf("%s───────┴────────────────────────────────────────────────────────────────%s\n", ColorGray, ColorReset))
+	// }
+
+	if follow {
+		// No footer: the followed lines continue the listing
+		fmt.Print(output.String())
+		opts := &followOptions{
+			TabWidth:       tabWidth,
+			LineNumbers:    showLineNumbers,
+			Grid:           showGrid,
+			ShowWhitespace: showWhitespace,
+			NumWidth:       len(strconv.Itoa(followNextLine)),
+			NextLine:       followNextLine,
+		}
+		if !plain {
+			opts.Lexer = resolveShowLexer(lexerName, filePath, fromStdin, string(content))
+			if opts.Style = styles.Get(themeName); opts.Style == nil {
+				opts.Style = styles.Get("monokai")
+			}
+		}
+		return followFile(filePath, followOffset, opts)
+	}
 
 	if showGrid {
-	    line := strings.Repeat("─", width)
+	    line := strings.Repeat("─", max(width, 0))
 	    output.WriteString(fmt.Sprintf("%s%s%s\n", ColorGray, line, ColorReset))
 	}
 	output.WriteString("\n")
 
+	if usePager && !forcePager && !exceedsTerminalHeight(output.String()) {
+		usePager = false
+	}
 	if usePager {
 		return displayWithPager(output.String())
 	} else {
@@ -460,6 +1187,386 @@ func handleShowCommand(args []string) error {
 	return nil
 }
 
+// resolveShowLexer picks the lexer pt show highlights with: --lexer if
+// given, else a guess from stdin content or a match on the file name
+func resolveShowLexer(lexerName, filePath string, fromStdin bool, content string) chroma.Lexer {
+	var lexer chroma.Lexer
+	if lexerName != "" {
+		lexer = lexers.Get(lexerName)
+	} else if fromStdin {
+		lexer = lexers.Analyse(content)
+	} else {
+		lexer = lexers.Match(filePath)
+	}
+
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// exportHighlighted renders content as a standalone HTML page (CSS embedded)
+// or an SVG image and writes it to outPath, or stdout when outPath is empty
+func exportHighlighted(content string, lexer chroma.Lexer, themeName, format, outPath string, lineNumbers bool) error {
+	style := styles.Get(themeName)
+	if style == nil {
+		style = styles.Get("monokai")
+	}
+
+	var formatter chroma.Formatter
+	switch format {
+	case "html":
+		formatter = html.New(html.Standalone(true), html.WithClasses(true), html.WithLineNumbers(lineNumbers))
+	case "svg":
+		formatter = formatters.SVG
+	}
+
+	iterator, err := lexer.Tokenise(nil, content)
+	if err != nil {
+		return fmt.Errorf("failed to tokenize: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, style, iterator); err != nil {
+		return fmt.Errorf("failed to format %s: %w", format, err)
+	}
+
+	if outPath == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	if err := os.WriteFile(outPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outPath, err)
+	}
+	fmt.Printf("✅ Exported %s to %s (%s)\n", strings.ToUpper(format), outPath, formatSize(int64(buf.Len())))
+	return nil
+}
+
+// markWhitespace makes whitespace visible in a highlighted line: tabs become
+// a grey "→" and trailing spaces a reverse-video "·". Escape sequences are
+// kept, and the colour active before a tab marker is restored after it.
+func markWhitespace(line string) string {
+	// Byte offset where the trailing run of spaces and tabs starts
+	trailing := len(line)
+	for i := 0; i < len(line); {
+		if line[i] == '\033' {
+			i += len(leadingEscape(line[i:]))
+			continue
+		}
+		if line[i] != ' ' && line[i] != '\t' && line[i] != '\r' {
+			trailing = len(line)
+		} else if trailing == len(line) {
+			trailing = i
+		}
+		i++
+	}
+
+	var b strings.Builder
+	active := ""
+	for i := 0; i < len(line); {
+		if line[i] == '\033' {
+			seq := leadingEscape(line[i:])
+			if seq == ColorReset || seq == "\033[m" {
+				active = ""
+			} else if strings.HasSuffix(seq, "m") {
+				active += seq
+			}
+			b.WriteString(seq)
+			i += len(seq)
+			continue
+		}
+
+		switch {
+		case line[i] == '\t' && i >= trailing:
+			b.WriteString("\033[7m→\033[27m")
+		case line[i] == '\t':
+			b.WriteString(ColorGray + "→" + ColorReset + active)
+		case line[i] == ' ' && i >= trailing:
+			b.WriteString("\033[7m·\033[27m")
+		default:
+			b.WriteByte(line[i])
+		}
+		i++
+	}
+	return b.String()
+}
+
+// leadingEscape returns the ANSI escape sequence s starts with
+func leadingEscape(s string) string {
+	j := 1
+	if j < len(s) && s[j] == '[' {
+		j++
+		for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+			j++
+		}
+		if j < len(s) {
+			j++
+		}
+	}
+	return s[:j]
+}
+
+// wrapANSILine soft-wraps a highlighted line into rows of at most width
+// visible runes. Escape sequences don't count towards the width, and the
+// colour active at a break is closed and re-opened on the next row.
+func wrapANSILine(line string, width int) []string {
+	if width <= 0 {
+		return []string{line}
+	}
+
+	var rows []string
+	var row strings.Builder
+	active := ""
+	visible := 0
+
+	for i := 0; i < len(line); {
+		// Copy escape sequences through without counting them
+		if line[i] == '\033' {
+			j := i + 1
+			if j < len(line) && line[j] == '[' {
+				j++
+				for j < len(line) && (line[j] < 0x40 || line[j] > 0x7e) {
+					j++
+				}
+				if j < len(line) {
+					j++
+				}
+			}
+			seq := line[i:j]
+			if seq == ColorReset || seq == "\033[m" {
+				active = ""
+			} else if strings.HasSuffix(seq, "m") {
+				active += seq
+			}
+			row.WriteString(seq)
+			i = j
+			continue
+		}
+
+		if visible == width {
+			if active != "" {
+				row.WriteString(ColorReset)
+			}
+			rows = append(rows, row.String())
+			row.Reset()
+			row.WriteString(active)
+			visible = 0
+		}
+
+		_, size := utf8.DecodeRuneInString(line[i:])
+		row.WriteString(line[i : i+size])
+		visible++
+		i += size
+	}
+
+	return append(rows, row.String())
+}
+
+// ============================================================================
+// LINE DIFF - Minimal line-level diff used by show --diff and friends
+// ============================================================================
+
+// Line diff operation kinds
+const (
+	lineEqual = iota
+	lineInsert
+	lineDelete
+)
+
+// lineOp is a single step of a line diff: a line kept, inserted or deleted
+type lineOp struct {
+	Kind int
+	Text string
+}
+
+// maxLCSCells bounds the memory used by the LCS table; larger middles are
+// reported as a block replacement instead
+const maxLCSCells = 16 * 1024 * 1024
+
+// splitLines splits text into lines, dropping the empty element produced by
+// a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// computeLineDiff returns the sequence of operations that turns oldLines
+// into newLines. Common prefix/suffix are trimmed first and the remaining
+// middle is aligned with a longest-common-subsequence table.
+func computeLineDiff(oldLines, newLines []string) []lineOp {
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]lineOp, 0, len(oldLines)+len(newLines))
+	for _, line := range newLines[:prefix] {
+		ops = append(ops, lineOp{Kind: lineEqual, Text: line})
+	}
+
+	a := oldLines[prefix : len(oldLines)-suffix]
+	b := newLines[prefix : len(newLines)-suffix]
+
+	if (len(a)+1)*(len(b)+1) > maxLCSCells {
+		for _, line := range a {
+			ops = append(ops, lineOp{Kind: lineDelete, Text: line})
+		}
+		for _, line := range b {
+			ops = append(ops, lineOp{Kind: lineInsert, Text: line})
+		}
+	} else {
+		// lcs[i][j] = length of LCS of a[i:] and b[j:]
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(a) && j < len(b) {
+			if a[i] == b[j] {
+				ops = append(ops, lineOp{Kind: lineEqual, Text: b[j]})
+				i++
+				j++
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				ops = append(ops, lineOp{Kind: lineDelete, Text: a[i]})
+				i++
+			} else {
+				ops = append(ops, lineOp{Kind: lineInsert, Text: b[j]})
+				j++
+			}
+		}
+		for ; i < len(a); i++ {
+			ops = append(ops, lineOp{Kind: lineDelete, Text: a[i]})
+		}
+		for ; j < len(b); j++ {
+			ops = append(ops, lineOp{Kind: lineInsert, Text: b[j]})
+		}
+	}
+
+	for _, line := range newLines[len(newLines)-suffix:] {
+		ops = append(ops, lineOp{Kind: lineEqual, Text: line})
+	}
+
+	return ops
+}
+
+// lineChangeMarkers returns one gutter marker per line of newLines:
+// ' ' unchanged, '+' added, '~' changed (inserted where old lines were removed)
+func lineChangeMarkers(oldLines, newLines []string) []byte {
+	ops := computeLineDiff(oldLines, newLines)
+	markers := make([]byte, 0, len(newLines))
+
+	for k := 0; k < len(ops); {
+		if ops[k].Kind == lineEqual {
+			markers = append(markers, ' ')
+			k++
+			continue
+		}
+
+		// A block of deletes/inserts between two unchanged lines
+		deleted := 0
+		var inserted int
+		for ; k < len(ops) && ops[k].Kind != lineEqual; k++ {
+			if ops[k].Kind == lineDelete {
+				deleted++
+			} else {
+				inserted++
+			}
+		}
+		for n := 0; n < inserted; n++ {
+			if n < deleted {
+				markers = append(markers, '~')
+			} else {
+				markers = append(markers, '+')
+			}
+		}
+	}
+
+	return markers
+}
+
+// lineDiffStat counts the lines added and removed going from oldLines to
+// newLines, like git diff --numstat
+func lineDiffStat(oldLines, newLines []string) (added, removed int) {
+	for _, op := range computeLineDiff(oldLines, newLines) {
+		switch op.Kind {
+		case lineInsert:
+			added++
+		case lineDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// changedLineRanges returns the first and last index in newLines of each
+// change against oldLines. A pure deletion is reported at the line that
+// now takes its place.
+func changedLineRanges(oldLines, newLines []string) [][2]int {
+	ops := computeLineDiff(oldLines, newLines)
+	last := max(len(newLines)-1, 0)
+
+	var ranges [][2]int
+	newIdx := 0
+	for k := 0; k < len(ops); {
+		if ops[k].Kind == lineEqual {
+			newIdx++
+			k++
+			continue
+		}
+
+		start := newIdx
+		for ; k < len(ops) && ops[k].Kind != lineEqual; k++ {
+			if ops[k].Kind != lineDelete {
+				newIdx++
+			}
+		}
+		end := max(newIdx-1, start)
+		ranges = append(ranges, [2]int{min(start, last), min(end, last)})
+	}
+
+	return ranges
+}
+
+// renderGutterMarker returns the colored marker for line i (blank if unchanged)
+func renderGutterMarker(markers []byte, i int) string {
+	if i >= len(markers) {
+		return " "
+	}
+	switch markers[i] {
+	case '+':
+		return ColorGreen + "+"
+	case '~':
+		return ColorYellow + "~"
+	default:
+		return " "
+	}
+}
+
 // ============================================================================
 // TEMP COMMAND (-z) - Display clipboard content with syntax highlighting
 // ============================================================================
@@ -471,17 +1578,46 @@ func handleTempCommand(args []string) error {
 	}
 
 	if text == "" {
-		return fmt.Errorf("clipboard is empty")
+		return withHint(errClipboardEmpty, "copy something to preview first")
 	}
 
 	lexerName := ""
 	themeName := "monokai"
+	if appConfig.Theme != "" {
+		themeName = appConfig.Theme
+	}
 	usePager := false
 	showLineNumbers := true
 	showGrid := true
+	statsOnly := false
+	appendPath := ""
+	savePath := ""
+	comment := ""
+	assumeYes := false
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
+		case "--stats", "--count":
+			statsOnly = true
+		case "--strip-ansi":
+			text = stripANSI(text)
+		case "--append":
+			if i+1 < len(args) {
+				appendPath = args[i+1]
+				i++
+			}
+		case "--save":
+			if i+1 < len(args) {
+				savePath = args[i+1]
+				i++
+			}
+		case "-m", "--message":
+			if i+1 < len(args) {
+				comment = args[i+1]
+				i++
+			}
+		case "--yes", "-y":
+			assumeYes = true
 		case "--lexer", "-l":
 			if i+1 < len(args) {
 				lexerName = args[i+1]
@@ -500,6 +1636,25 @@ func handleTempCommand(args []string) error {
 			showGrid = false
 		}
 	}
+	themeName = resolveTheme(themeName)
+
+	if appendPath != "" && savePath != "" {
+		return fmt.Errorf("use either --append or --save, not both")
+	}
+
+	if statsOnly {
+		printClipboardStats(text)
+		return nil
+	}
+
+	// Guess the language from the content when no lexer was given
+	lexerLabel := lexerName
+	if lexerName == "" {
+		lexerName = detectLexer(text)
+		if lexerName != "" {
+			lexerLabel = lexerName + " (auto)"
+		}
+	}
 
 	var output bytes.Buffer
 
@@ -509,12 +1664,12 @@ func handleTempCommand(args []string) error {
 	output.WriteString(fmt.Sprintf("%s       │%s %sSize:%s %s  %sTime:%s %s\n",
 		ColorGray, ColorReset,
 		ColorCyan, ColorReset, formatSize(int64(len(text))),
-		ColorCyan, ColorReset, time.Now().Format("2006-01-02 15:04:05")))
+		ColorCyan, ColorReset, formatTime(time.Now())))
 
 	if lexerName != "" {
 		output.WriteString(fmt.Sprintf("%s       │%s %sLexer:%s %s  %sTheme:%s %s\n",
 			ColorGray, ColorReset,
-			ColorCyan, ColorReset, lexerName,
+			ColorCyan, ColorReset, lexerLabel,
 			ColorCyan, ColorReset, themeName))
 	}
 
@@ -573,64 +1728,251 @@ func handleTempCommand(args []string) error {
 	output.WriteString(fmt.Sprintf("%s───────┴────────────────────────────────────────────────────────────────%s\n", ColorGray, ColorReset))
 
 	if usePager {
-		return displayWithPager(output.String())
+		if err := displayWithPager(output.String()); err != nil {
+			return err
+		}
 	} else {
 		fmt.Print(output.String())
 	}
 
+	if appendPath != "" {
+		return appendPreviewedClipboard(appendPath, text, comment, assumeYes)
+	}
+	if savePath != "" {
+		return savePreviewedClipboard(savePath, text, comment, assumeYes)
+	}
+
 	return nil
 }
 
-// displayWithPager displays content using less/more in streaming mode.
-func displayWithPager(content string) error {
-    pagers := []string{"less", "more"}
-    var pagerCmd string
+// appendPreviewedClipboard appends the clipboard text that was just shown to
+// filePath once the user confirms (pt -z --append <file>)
+func appendPreviewedClipboard(filePath, text, comment string, assumeYes bool) error {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		filePath = absPath
+	}
 
-    for _, p := range pagers {
-        if _, err := exec.LookPath(p); err == nil {
-            pagerCmd = p
-            break
-        }
-    }
+	prompt := fmt.Sprintf("Append %s to %s? (y/N): ", formatSize(int64(len(text))), filepath.Base(filePath))
+	confirmed, err := confirmAction(prompt, assumeYes, "y", "yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("❌ Append cancelled")
+		return nil
+	}
 
-    if pagerCmd == "" {
-        fmt.Print(content)
-        return nil
-    }
+	return writeFile(filePath, text, true, false, comment, false)
+}
 
-    var cmd *exec.Cmd
-    if pagerCmd == "less" {
-        cmd = exec.Command("less", "-R", "-F", "-X")
-    } else {
-        cmd = exec.Command(pagerCmd)
-    }
+// savePreviewedClipboard writes the clipboard text that was just shown to
+// filePath once the user confirms (pt -z --save <file>). An existing file
+// is backed up first, as with pt <file>. A new file named without an
+// extension is offered the one of the detected lexer.
+func savePreviewedClipboard(filePath, text, comment string, assumeYes bool) error {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		filePath = absPath
+	}
 
-    stdin, err := cmd.StdinPipe()
-    if err != nil {
-        fmt.Print(content)
-        return nil
-    }
+	if _, err := os.Stat(filePath); os.IsNotExist(err) && filepath.Ext(filePath) == "" {
+		if ext := lexerExtension(detectLexer(text)); ext != "" {
+			prompt := fmt.Sprintf("Content looks like %s; save as %s? (y/N): ", strings.TrimPrefix(ext, "."), filepath.Base(filePath)+ext)
+			confirmed, err := confirmAction(prompt, assumeYes, "y", "yes")
+			if err != nil {
+				return err
+			}
+			if confirmed {
+				filePath += ext
+			}
+		}
+	}
 
-    cmd.Stdout = os.Stdout
-    cmd.Stderr = os.Stderr
+	// checkIfDifferent reports identical content itself
+	if !checkIfDifferent(filePath, text) {
+		return nil
+	}
 
-    if err := cmd.Start(); err != nil {
-        fmt.Print(content)
-        return nil
-    }
+	prompt := fmt.Sprintf("Save %s to %s? (y/N): ", formatSize(int64(len(text))), filepath.Base(filePath))
+	confirmed, err := confirmAction(prompt, assumeYes, "y", "yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("❌ Save cancelled")
+		return nil
+	}
 
-    // STREAM content
-    go func() {
-        defer stdin.Close()
+	return writeFile(filePath, text, false, false, comment, false)
+}
 
-        buf := []byte(content)
-        chunkSize := 4096
+// printClipboardStats prints size, line count, detected lexer and whether
+// the content looks binary, without rendering it (pt -z --stats)
+func printClipboardStats(text string) {
+	lines := strings.Count(text, "\n")
+	if !strings.HasSuffix(text, "\n") {
+		lines++
+	}
 
-        for len(buf) > 0 {
-            n := chunkSize
-            if len(buf) < chunkSize {
-                n = len(buf)
-            }
+	binary := looksBinary([]byte(text))
+	lexerName := "-"
+	if !binary {
+		if detected := detectLexer(text); detected != "" {
+			lexerName = detected
+		}
+	}
+	binaryLabel := "no"
+	if binary {
+		binaryLabel = "yes"
+	}
+
+	fmt.Printf("%sClipboard:%s\n", ColorBold, ColorReset)
+	fmt.Printf("  %sSize:%s   %s (%d bytes)\n", ColorCyan, ColorReset, formatSize(int64(len(text))), len(text))
+	fmt.Printf("  %sLines:%s  %d\n", ColorCyan, ColorReset, lines)
+	fmt.Printf("  %sLexer:%s  %s\n", ColorCyan, ColorReset, lexerName)
+	fmt.Printf("  %sBinary:%s %s\n", ColorCyan, ColorReset, binaryLabel)
+}
+
+// detectLexer guesses a chroma lexer name from content alone. Cheap
+// signature checks catch common cases that chroma's analysers miss (JSON,
+// shebangs, Go), then lexers.Analyse is consulted. Returns "" if unsure.
+func detectLexer(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return ""
+	}
+
+	firstLine := trimmed
+	if idx := strings.IndexByte(trimmed, '\n'); idx >= 0 {
+		firstLine = trimmed[:idx]
+	}
+
+	switch {
+	case (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)):
+		return "json"
+	case strings.HasPrefix(firstLine, "#!"):
+		for _, interp := range []string{"python", "bash", "zsh", "sh", "node", "perl", "ruby", "php"} {
+			if strings.Contains(firstLine, interp) {
+				if interp == "sh" || interp == "zsh" {
+					return "bash"
+				}
+				if interp == "node" {
+					return "javascript"
+				}
+				return interp
+			}
+		}
+	case strings.HasPrefix(trimmed, "<?php"):
+		return "php"
+	case strings.HasPrefix(trimmed, "<?xml"):
+		return "xml"
+	case strings.HasPrefix(strings.ToLower(trimmed), "<!doctype html") || strings.HasPrefix(strings.ToLower(trimmed), "<html"):
+		return "html"
+	case strings.HasPrefix(trimmed, "diff --git") || (strings.HasPrefix(trimmed, "--- ") && strings.Contains(trimmed, "\n+++ ")):
+		return "diff"
+	case strings.HasPrefix(trimmed, "package ") && (strings.Contains(trimmed, "\nfunc ") || strings.Contains(trimmed, "\nimport")):
+		return "go"
+	}
+
+	if lexer := lexers.Analyse(text); lexer != nil {
+		config := lexer.Config()
+		if len(config.Aliases) > 0 {
+			return config.Aliases[0]
+		}
+		return config.Name
+	}
+
+	return ""
+}
+
+// lexerExtension returns the file extension (with the dot) of a chroma
+// lexer's first plain filename pattern, e.g. ".py" for "python", or "" if
+// the lexer is unknown or has none
+func lexerExtension(lexerName string) string {
+	if lexerName == "" {
+		return ""
+	}
+	lexer := lexers.Get(lexerName)
+	if lexer == nil {
+		return ""
+	}
+	for _, pattern := range lexer.Config().Filenames {
+		ext, ok := strings.CutPrefix(pattern, "*.")
+		if ok && ext != "" && !strings.ContainsAny(ext, "*?[]{}") {
+			return "." + ext
+		}
+	}
+	return ""
+}
+
+// exceedsTerminalHeight reports whether content needs more rows than the
+// terminal has, counting a long line as the rows it wraps to. Output that
+// is not a terminal never does.
+func exceedsTerminalHeight(content string) bool {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return false
+	}
+
+	rows := 0
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		rows += max(1, (cellWidth(line)+width-1)/width)
+		if rows >= height {
+			return true
+		}
+	}
+	return false
+}
+
+// displayWithPager displays content using less/more in streaming mode.
+func displayWithPager(content string) error {
+    pagers := []string{"less", "more"}
+    var pagerCmd string
+
+    for _, p := range pagers {
+        if _, err := exec.LookPath(p); err == nil {
+            pagerCmd = p
+            break
+        }
+    }
+
+    if pagerCmd == "" {
+        fmt.Print(content)
+        return nil
+    }
+
+    var cmd *exec.Cmd
+    if pagerCmd == "less" {
+        cmd = exec.Command("less", "-R", "-F", "-X")
+    } else {
+        cmd = exec.Command(pagerCmd)
+    }
+
+    stdin, err := cmd.StdinPipe()
+    if err != nil {
+        fmt.Print(content)
+        return nil
+    }
+
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+
+    if err := cmd.Start(); err != nil {
+        fmt.Print(content)
+        return nil
+    }
+
+    // STREAM content
+    go func() {
+        defer stdin.Close()
+
+        buf := []byte(content)
+        chunkSize := 4096
+
+        for len(buf) > 0 {
+            n := chunkSize
+            if len(buf) < chunkSize {
+                n = len(buf)
+            }
 
             _, err := stdin.Write(buf[:n])
             if err != nil {
@@ -648,25 +1990,42 @@ func displayWithPager(content string) error {
 // DIFF COMMAND - Compare files or clipboard
 // ============================================================================
 
-func handleDiffClipboardToFile(fileName string) error {
+// handleDiffClipboardToFile diffs the clipboard against fileName, or against
+// its newest backup when againstLast is set (pt -d <file> -z --last)
+func handleDiffClipboardToFile(fileName string, againstLast bool) error {
 	// 1. Resolve the target file path (including recursive search)
 	filePath, err := resolveFilePath(fileName)
 	if err != nil {
 		return fmt.Errorf("failed to resolve file path: %w", err)
 	}
 
+	// With --last the diff target is the last backup, which must never be
+	// modified, so the tool's auto-backup of the target is disabled
+	target := filePath
+	if againstLast {
+		backups, err := listBackups(filePath)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return fmt.Errorf("%w for: %s", errNoBackups, filePath)
+		}
+		target = backups[0].Path
+		fmt.Printf("%s📊 Comparing clipboard with last backup: %s%s\n\n", ColorCyan, backups[0].Name, ColorReset)
+	}
+
 	// 2. Read clipboard content
 	clipboardText, err := getClipboardText()
 	if err != nil {
 		return fmt.Errorf("failed to read clipboard: %w", err)
 	}
 
-	if !checkIfDifferent(fileName, clipboardText) {
+	if !checkIfDifferent(target, clipboardText) {
 		return nil
 	}
 
 	if clipboardText == "" {
-		return fmt.Errorf("clipboard is empty, nothing to diff")
+		return fmt.Errorf("%w, nothing to diff", errClipboardEmpty)
 	}
 
 	// 3. Validate the resolved target file path
@@ -679,6 +2038,8 @@ func handleDiffClipboardToFile(fileName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create temporary file: %w", err)
 	}
+	trackTempFile(tempFile.Name())
+	defer untrackTempFile(tempFile.Name())
 	defer os.Remove(tempFile.Name()) // Clean up the temp file after the function exits
 	defer tempFile.Close()
 
@@ -694,7 +2055,11 @@ func handleDiffClipboardToFile(fileName string) error {
 	// 6. Run the core diff logic (runDelta) between the temp file and the resolved target file
 	// func runDiff(toolName, file1, file2 string) error {
 	// err = runDelta(tempFile.Name(), filePath)
-	err = runDiff(difftool, tempFile.Name(), filePath, true)
+	toolName := difftool
+	if diffExternal {
+		toolName = preferGUITool(toolName)
+	}
+	err = runDiff(toolName, tempFile.Name(), target, !againstLast)
 	if err != nil {
 		// runDelta already handles delta not found error and specific exit codes
 		return fmt.Errorf("failed to run diff tool (delta): %w", err)
@@ -714,6 +2079,8 @@ type DiffToolConfig struct {
     BinaryNames    []string // Names of binary possibilities
     NormalExitCode int      // Exit code that is considered normal (0 or 1)
     Args           []string // Additional arguments if needed
+    ContextArgs    []string // Replace Args for --context N ("%d" is replaced by N)
+    MergeArgs      []string // Three-way merge: %base, %local, %other, %output are replaced; empty if unsupported
 }
 
 var diffTools = map[string]DiffToolConfig{
@@ -726,6 +2093,7 @@ var diffTools = map[string]DiffToolConfig{
         InstallURL:     "https://github.com/dandavison/delta#installation",
         BinaryNames:    []string{"delta"},
         NormalExitCode: 1,
+        ContextArgs:    []string{"--diff-args=-U%d"},
     },
     "diff": {
         Name:           "GNU diff",
@@ -737,6 +2105,7 @@ var diffTools = map[string]DiffToolConfig{
         BinaryNames:    []string{"diff"},
         NormalExitCode: 1,
         Args:           []string{"-u"},
+        ContextArgs:    []string{"-U", "%d"},
     },
     "sdiff": {
         Name:           "GNU sdiff",
@@ -758,6 +2127,7 @@ var diffTools = map[string]DiffToolConfig{
         BinaryNames:    []string{"vimdiff", "nvim", "vim"},
         NormalExitCode: 0,
         Args:           []string{"-d"},
+        ContextArgs:    []string{"-c", "set diffopt+=context:%d"},
     },
     "meld": {
         Name:           "Meld",
@@ -768,6 +2138,7 @@ var diffTools = map[string]DiffToolConfig{
         InstallURL:     "https://meldmerge.org/#download",
         BinaryNames:    []string{"meld"},
         NormalExitCode: 1,
+        MergeArgs:      []string{"%local", "%base", "%other", "-o", "%output"},
     },
     "kdiff3": {
         Name:           "KDiff3",
@@ -778,6 +2149,7 @@ var diffTools = map[string]DiffToolConfig{
         InstallURL:     "https://download.kde.org/stable/kdiff3/",
         BinaryNames:    []string{"kdiff3"},
         NormalExitCode: 1,
+        MergeArgs:      []string{"%base", "%local", "%other", "-o", "%output"},
     },
     "diffmerge": {
         Name:           "DiffMerge",
@@ -788,6 +2160,7 @@ var diffTools = map[string]DiffToolConfig{
         InstallURL:     "https://sourcegear.com/diffmerge/downloads.php",
         BinaryNames:    []string{"diffmerge", "sgdm"},
         NormalExitCode: 1,
+        MergeArgs:      []string{"--merge", "--result=%output", "%local", "%base", "%other"},
     },
     "kompare": {
         Name:           "Kompare",
@@ -808,6 +2181,7 @@ var diffTools = map[string]DiffToolConfig{
         InstallURL:     "https://sourceforge.net/projects/tkdiff/files/",
         BinaryNames:    []string{"tkdiff"},
         NormalExitCode: 1,
+        MergeArgs:      []string{"-a", "%base", "-o", "%output", "%local", "%other"},
     },
     "bcompare": {
         Name:           "Beyond Compare",
@@ -818,6 +2192,7 @@ var diffTools = map[string]DiffToolConfig{
         InstallURL:     "https://www.scootersoftware.com/download.php",
         BinaryNames:    []string{"bcompare", "bcomp"},
         NormalExitCode: 1,
+        MergeArgs:      []string{"%local", "%other", "%base", "-mergeoutput=%output"},
     },
     "filemerge": {
         Name:           "FileMerge (Xcode)",
@@ -828,6 +2203,7 @@ var diffTools = map[string]DiffToolConfig{
         InstallURL:     "https://developer.apple.com/download/all/?q=xcode",
         BinaryNames:    []string{"opendiff"},
         NormalExitCode: 0,
+        MergeArgs:      []string{"%local", "%other", "-ancestor", "%base", "-merge", "%output"},
     },
     "kaleidoscope": {
         Name:           "Kaleidoscope",
@@ -895,7 +2271,8 @@ func runDiff(toolName, file1, file2 string, auto_backup bool) error {
     // Find binary
     binaryPath, found := findBinary(config.BinaryNames)
     if !found {
-        return fmt.Errorf("%s is not installed. Install from: %s", config.Name, config.InstallURL)
+        return withHint(fmt.Errorf("%s %w", config.Name, errToolMissing),
+            fmt.Sprintf("install it from %s, or use --tool pdiff2 for the built-in diff", config.InstallURL))
     }
     
     // Set up arguments
@@ -905,10 +2282,18 @@ func runDiff(toolName, file1, file2 string, auto_backup bool) error {
     if toolName == "vimdiff" && (filepath.Base(binaryPath) == "vim" || 
                                  filepath.Base(binaryPath) == "nvim") {
         args = append(args, "-d")
-    } else if len(config.Args) > 0 {
+    } else if len(config.Args) > 0 && (diffContext < 0 || len(config.ContextArgs) == 0) {
         args = append(args, config.Args...)
     }
     
+    if diffContext >= 0 {
+        if len(config.ContextArgs) > 0 {
+            args = append(args, contextArgs(config.ContextArgs, diffContext)...)
+        } else {
+            logger.Printf("%s has no context setting, ignoring --context %d", config.Name, diffContext)
+        }
+    }
+    
     args = append(args, file1, file2)
     
     // Execute command
@@ -916,10 +2301,31 @@ func runDiff(toolName, file1, file2 string, auto_backup bool) error {
     cmd.Stdout = os.Stdout
     cmd.Stderr = os.Stderr
     cmd.Stdin = os.Stdin
+
+    // Non-interactive output: a GUI tool is left running on its own rather
+    // than blocking, unless it is showing a temp file that is deleted on
+    // return. CLI tools get their color stripped.
+    var output *bytes.Buffer
+    if !term.IsTerminal(int(os.Stdout.Fd())) {
+        if isGUITool(toolName) && !isTrackedTempFile(file1) && !isTrackedTempFile(file2) {
+            if auto_backup {
+                logger.Printf("%s detached: edits made in it are not auto-backed up", config.Name)
+            }
+            return startDetached(cmd, config.Name)
+        }
+        if config.Type == "CLI" {
+            output = &bytes.Buffer{}
+            cmd.Stdout = output
+            cmd.Env = append(os.Environ(), "NO_COLOR=1", "TERM=dumb")
+        }
+    }
     
     // Handle execution
     err := cmd.Run()
     logger.Printf("runDif, err: %v", err)
+    if output != nil {
+        os.Stdout.Write(ansiEscape.ReplaceAll(output.Bytes(), nil))
+    }
     
     if err != nil {
         if exitErr, ok := err.(*exec.ExitError); ok {
@@ -951,6 +2357,157 @@ func runDiff(toolName, file1, file2 string, auto_backup bool) error {
     return nil
 }
 
+// ansiEscape matches terminal escape sequences: CSI (colors, cursor
+// moves) and OSC (titles, hyperlinks)
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// stripANSI removes terminal escape sequences from copied terminal output
+func stripANSI(text string) string {
+    return ansiEscape.ReplaceAllString(text, "")
+}
+
+// isGUITool reports whether a diff tool opens its own window
+func isGUITool(toolName string) bool {
+    return strings.HasPrefix(diffTools[toolName].Type, "GUI")
+}
+
+// startDetached launches a GUI diff tool without waiting for it, so
+// scripts and pipes are not held up until its window is closed
+func startDetached(cmd *exec.Cmd, name string) error {
+    cmd.Stdin, cmd.Stdout, cmd.Stderr = nil, nil, nil
+    detachProcess(cmd)
+    if err := cmd.Start(); err != nil {
+        return fmt.Errorf("failed to start %s: %v", name, err)
+    }
+    logger.Printf("Started %s detached (pid %d): %v", name, cmd.Process.Pid, cmd.Args)
+    fmt.Fprintf(os.Stderr, "%s opened in the background\n", name)
+    return cmd.Process.Release()
+}
+
+// preferGUITool returns toolName if it is a GUI tool, otherwise the first
+// installed GUI tool (the configured one first). Falls back to toolName.
+func preferGUITool(toolName string) string {
+    if isGUITool(toolName) {
+        return toolName
+    }
+    candidates := append([]string{appConfig.DiffTool}, mergeToolOrder...)
+    candidates = append(candidates, "kompare", "kaleidoscope")
+    for _, name := range candidates {
+        if isGUITool(name) && isPlatformCompatible(diffTools[name].Platform) && checkToolInstalled(name) {
+            return name
+        }
+    }
+    fmt.Printf("%sWarning: no GUI diff tool installed, using %s%s\n", ColorYellow, toolName, ColorReset)
+    return toolName
+}
+
+// resolveDiffTool picks the diff tool: --tool, then config, then -T, then delta
+func resolveDiffTool(toolFlag string) string {
+    if toolFlag != "" {
+        return toolFlag
+    }
+    if appConfig.DiffTool != "" {
+        return appConfig.DiffTool
+    }
+    if difftool != "" {
+        return difftool
+    }
+    return "delta"
+}
+
+// mergeToolOrder is the preference among installed three-way merge tools
+// when neither merge_tool nor the diff tool can merge
+var mergeToolOrder = []string{"kdiff3", "meld", "bcompare", "diffmerge", "tkdiff", "filemerge"}
+
+// resolveMergeTool picks the three-way merge tool: --tool, then merge_tool,
+// then the diff tool if it can merge, then the first installed one.
+// Returns "" when no merge-capable tool is installed.
+func resolveMergeTool(toolFlag string) string {
+    for _, name := range []string{toolFlag, appConfig.MergeTool} {
+        if name != "" {
+            return name
+        }
+    }
+    if name := resolveDiffTool(""); len(diffTools[name].MergeArgs) > 0 && checkToolInstalled(name) {
+        return name
+    }
+    for _, name := range mergeToolOrder {
+        if isPlatformCompatible(diffTools[name].Platform) && checkToolInstalled(name) {
+            return name
+        }
+    }
+    return ""
+}
+
+// runMerge opens a three-way merge of local and other against base in a
+// merge tool, which writes the result to output
+func runMerge(toolName, base, local, other, output string) error {
+    config, exists := diffTools[toolName]
+    if !exists {
+        return fmt.Errorf("merge tool '%s' not supported", toolName)
+    }
+    if len(config.MergeArgs) == 0 {
+        return fmt.Errorf("%s cannot do three-way merges", config.Name)
+    }
+    if !isPlatformCompatible(config.Platform) {
+        return fmt.Errorf("%s is not available on %s", config.Name, runtime.GOOS)
+    }
+
+    binaryPath, found := findBinary(config.BinaryNames)
+    if !found {
+        return withHint(fmt.Errorf("%s %w", config.Name, errToolMissing),
+            fmt.Sprintf("install it from %s, or set merge_tool in the config", config.InstallURL))
+    }
+
+    replacer := strings.NewReplacer("%base", base, "%local", local, "%other", other, "%output", output)
+    args := make([]string, len(config.MergeArgs))
+    for i, arg := range config.MergeArgs {
+        args[i] = replacer.Replace(arg)
+    }
+
+    cmd := exec.Command(binaryPath, args...)
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+    cmd.Stdin = os.Stdin
+
+    err := cmd.Run()
+    logger.Printf("runMerge %s %v, err: %v", binaryPath, args, err)
+    if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == config.NormalExitCode {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to run %s: %v", config.Name, err)
+    }
+    return nil
+}
+
+// contextArgs fills n into a tool's ContextArgs template
+func contextArgs(template []string, n int) []string {
+    args := make([]string, len(template))
+    for i, arg := range template {
+        args[i] = strings.ReplaceAll(arg, "%d", strconv.Itoa(n))
+    }
+    return args
+}
+
+// contextOrDefault returns the --context line count, or def when
+// --context was not given. 0 is a valid choice: changed lines only.
+func contextOrDefault(def int) int {
+    if diffContext < 0 {
+        return def
+    }
+    return diffContext
+}
+
+// parseContextArg validates the value given to --context
+func parseContextArg(value string) (int, error) {
+    n, err := strconv.Atoi(value)
+    if err != nil || n < 0 {
+        return 0, fmt.Errorf("invalid --context value '%s': must be a non-negative number", value)
+    }
+    return n, nil
+}
+
 func handleAutoBackup(auto_backup bool, filePath string, original []byte) error {
     if !auto_backup {
         return nil
@@ -973,30 +2530,89 @@ func handleDiffCommand(args []string) error {
     }
 
     filename := args[0]
-    useLast := len(args) > 1 && (args[1] == "--last" || args[1] == "-lt")
+    useLast := false
+    wordDiff := false
+    toolFlag := ""
+    againstName := ""
+    backupNumber := 0
+    for i := 1; i < len(args); i++ {
+        switch args[i] {
+        case "--last", "-lt":
+            useLast = true
+        case "--word-diff":
+            wordDiff = true
+        case "--against":
+            if i+1 < len(args) {
+                againstName = args[i+1]
+                i++
+            }
+        case "--backup":
+            if i+1 < len(args) {
+                n, err := parseBackupNumber(args[i+1])
+                if err != nil {
+                    return err
+                }
+                backupNumber = n
+                i++
+            }
+        case "--tool", "-T":
+            if i+1 < len(args) {
+                toolFlag = args[i+1]
+                i++
+            }
+        case "--context":
+            if i+1 < len(args) {
+                n, err := parseContextArg(args[i+1])
+                if err != nil {
+                    return err
+                }
+                diffContext = n
+                i++
+            }
+        }
+    }
 
     filePath, err := resolveFilePath(filename)
     if err != nil {
         return err
     }
 
-    backups, err := listBackups(filePath)
+    // --against takes the backups of another file, e.g. after splitting one
+    backupSource := filePath
+    if againstName != "" {
+        backupSource, err = resolveFilePath(againstName)
+        if err != nil {
+            return fmt.Errorf("--against %s: %w", againstName, err)
+        }
+    }
+    ofFile := ""
+    if backupSource != filePath {
+        ofFile = " of " + filepath.Base(backupSource)
+    }
+
+    backups, err := listBackups(backupSource)
     if err != nil {
         return err
     }
 
     if len(backups) == 0 {
-        return fmt.Errorf("no backups found for: %s (check %s/ directory)", 
-            filePath, appConfig.BackupDirName)
+        return fmt.Errorf("%w for: %s (check %s/ directory)", 
+            errNoBackups, backupSource, appConfig.BackupDirName)
     }
 
     var selectedBackup BackupInfo
 
-    if useLast {
+    if backupNumber > 0 {
+        selectedBackup, err = backupNumbered(backups, backupSource, backupNumber)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("%s📊 Comparing with backup #%d%s: %s%s\n\n", ColorCyan, backupNumber, ofFile, selectedBackup.Name, ColorReset)
+    } else if useLast {
         selectedBackup = backups[0]
-        fmt.Printf("%s📊 Comparing with last backup: %s%s\n\n", ColorCyan, selectedBackup.Name, ColorReset)
+        fmt.Printf("%s📊 Comparing with last backup%s: %s%s\n\n", ColorCyan, ofFile, selectedBackup.Name, ColorReset)
     } else {
-        printBackupTable(filePath, backups)
+        printBackupTable(backupSource, backups)
 
         reader := bufio.NewReader(os.Stdin)
         fmt.Printf("Enter backup number to compare (1-%d) or 0 to cancel: ", len(backups))
@@ -1021,26 +2637,34 @@ func handleDiffCommand(args []string) error {
         }
 
         selectedBackup = backups[choice-1]
-        fmt.Printf("\n%s📊 Comparing with: %s%s\n\n", ColorCyan, selectedBackup.Name, ColorReset)
+        fmt.Printf("\n%s📊 Comparing with%s: %s%s\n\n", ColorCyan, ofFile, selectedBackup.Name, ColorReset)
     }
 
     if !checkIfDifferent(filePath, selectedBackup.Path) {
     	return nil
     }
 
-    // Use tools from config or default to delta
-    toolName := appConfig.DiffTool
-    if toolName == "" {
-    	if difftool != "" {
-    		toolName = difftool
-    	} else {
-    		toolName = "delta"	
-    	}
-        
+    toolName := resolveDiffTool(toolFlag)
+    if diffExternal {
+        toolName = preferGUITool(toolName)
     }
     
     fmt.Printf("%sDiffing use%s %s%s`%s`%s\n", ColorMagenta, ColorReset, ColorWhite, ColorBlue, toolName, ColorReset)
 
+    // The internal renderer needs no external binary
+    if toolName == "pdiff" || toolName == "pdiff2" {
+        pdiff := &PDiff2{WordDiff: wordDiff, Context: max(diffContext, 0), NoHighlight: diffNoHighlight}
+        diff, err := pdiff.DiffFiles(selectedBackup.Path, filePath)
+        if err != nil {
+            return fmt.Errorf("diff failed: %w", err)
+        }
+        pdiff.PrintDiff(diff)
+        return nil
+    }
+    if wordDiff {
+        fmt.Printf("%sNote: --word-diff only applies to --tool pdiff%s\n", ColorYellow, ColorReset)
+    }
+
     // Validate the tool before execution
     if _, exists := diffTools[toolName]; !exists {
         fmt.Printf("%sWarning: diff tool '%s' not found, using default 'delta'%s\n", 
@@ -1058,9 +2682,8 @@ func handleDiffCommand(args []string) error {
     
     // Check installation
     if _, found := findBinary(config.BinaryNames); !found {
-        return fmt.Errorf("%s is not installed. Install from: %s\n"+
-            "You can change diff tool in config file or use: pt config diff_tool <toolname>", 
-            config.Name, config.InstallURL)
+        return withHint(fmt.Errorf("%s %w", config.Name, errToolMissing),
+            fmt.Sprintf("install it from %s, or pick another with --tool <name> or diff_tool in 'pt config edit'", config.InstallURL))
     }
     
     // Run diff
@@ -1080,51 +2703,192 @@ func handleDiffCommand(args []string) error {
     return nil
 }
 
-func handleDiffCommand2(args []string, isClipboard *bool) error {
+// handleDiffAllCommand prints a +added -removed line count for every file
+// that differs from its last backup (pt -d --all), optionally limited to
+// paths and globs as with pt commit
+func handleDiffAllCommand(scopeArgs []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
 
-	var filePath string
-    // var text string
-    useLast := false
-    var selectedBackup BackupInfo
-    // var err error
+	projectRoot := findProjectRoot(cwd)
+	tree, err := buildProjectStatusTree(projectRoot)
+	if err != nil {
+		return err
+	}
 
-    // Parse arguments
-    // for i := 0; i < len(args); i++ {
-    for i := range args {
-        arg := args[i]
-        
-        if arg == "--last" || arg == "-lt" {
-            useLast = true
-            continue
-        }
-        
-        // First non-flag argument is assumed to be file path
-        if filePath == "" && arg[0] != '-' {
-            filePath = arg
-            logger.Printf("filePath [0]: %s", filePath)
-        }
-    }
+	var changedFiles []*FileStatusInfo
+	collectChangedFiles(tree, &changedFiles, false)
 
-    logger.Printf("filePath [00]: %s", filePath)
+	var scope *commitScope
+	if len(scopeArgs) > 0 {
+		if scope, err = newCommitScope(scopeArgs); err != nil {
+			return err
+		}
+	}
 
-    if filePath != "" {
-        resolvedPath, err := resolveFilePath(filePath)
-        logger.Printf("resolvedPath: %s", resolvedPath)
-        if err != nil {
-            fmt.Printf("❎ %sfile%s %s%s%s %snot found!%s\n", 
-                ColorRed, ColorReset, ColorYellow, filePath, 
-                ColorReset, ColorRed, ColorReset)
-            return err
-        }
-        filePath = resolvedPath
-        logger.Printf("filePath [1]: %s", filePath)
-        
-        if !isFile(filePath) {
-            return fmt.Errorf("file does not exist: %s", filePath)
-        }
-    }
+	type fileStat struct {
+		path           string
+		added, removed int
+		binary         bool
+	}
+	var stats []fileStat
+	newFiles := 0
+	totalAdded, totalRemoved := 0, 0
+	for _, file := range changedFiles {
+		if scope != nil && !scope.matches(file.Path) {
+			continue
+		}
+		if file.Status == FileStatusNew {
+			newFiles++
+			continue
+		}
 
-    logger.Printf("filePath [2]: %s", filePath)
+		backups, err := listBackups(file.Path)
+		if err != nil || len(backups) == 0 {
+			continue
+		}
+		oldContent, err := backupStore.Read(backups[0].Path)
+		if err != nil {
+			return fmt.Errorf("failed to read backup of %s: %w", file.Path, err)
+		}
+		newContent, err := os.ReadFile(file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+
+		relPath, err := filepath.Rel(cwd, file.Path)
+		if err != nil {
+			relPath = file.Path
+		}
+		stat := fileStat{path: relPath}
+		if looksBinary(oldContent) || looksBinary(newContent) {
+			stat.binary = true
+		} else {
+			stat.added, stat.removed = lineDiffStat(splitLines(string(oldContent)), splitLines(string(newContent)))
+			totalAdded += stat.added
+			totalRemoved += stat.removed
+		}
+		stats = append(stats, stat)
+	}
+
+	if len(stats) == 0 {
+		fmt.Printf("%s✓ No modified files. All files match their last backups.%s\n", ColorGreen, ColorReset)
+	} else {
+		addedWidth, removedWidth := 1, 1
+		for _, stat := range stats {
+			addedWidth = max(addedWidth, len(strconv.Itoa(stat.added)))
+			removedWidth = max(removedWidth, len(strconv.Itoa(stat.removed)))
+		}
+
+		fmt.Printf("\n%sChanges since last backup:%s\n\n", ColorBold, ColorReset)
+		for _, stat := range stats {
+			if stat.binary {
+				pad := strings.Repeat(" ", max(addedWidth+removedWidth+3-len("binary"), 0))
+				fmt.Printf("  %sbinary%s%s  %s\n", ColorGray, ColorReset, pad, stat.path)
+				continue
+			}
+			fmt.Printf("  %s+%-*d%s %s-%-*d%s  %s\n",
+				ColorGreen, addedWidth, stat.added, ColorReset,
+				ColorRed, removedWidth, stat.removed, ColorReset, stat.path)
+		}
+		fmt.Printf("\n%d file(s) changed, %s%d insertion(s)(+)%s, %s%d deletion(s)(-)%s\n",
+			len(stats), ColorGreen, totalAdded, ColorReset, ColorRed, totalRemoved, ColorReset)
+	}
+	if newFiles > 0 {
+		fmt.Printf("%s%d new file(s) without a backup (see pt check)%s\n", ColorGray, newFiles, ColorReset)
+	}
+
+	return nil
+}
+
+// handleGitDiffCommand renders git's working tree (or, with cached, index)
+// changes with the built-in PDiff2 renderer, optionally limited to paths
+func handleGitDiffCommand(paths []string, cached bool, wordDiff bool) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if findGitRoot(cwd) == "" {
+		return fmt.Errorf("not a Git repository")
+	}
+
+	pdiff := &PDiff2{WordDiff: wordDiff, Context: max(diffContext, 0), NoHighlight: diffNoHighlight}
+	diffText, err := pdiff.GetGitDiff(cached, paths...)
+	if err != nil {
+		return fmt.Errorf("git diff failed: %w", err)
+	}
+
+	against := "working tree"
+	if cached {
+		against = "index"
+	}
+	fmt.Printf("%sDiffing use%s %s%s`%s`%s (%s)\n",
+		ColorMagenta, ColorReset, ColorWhite, ColorBlue, "PDiff2", ColorReset, against)
+	pdiff.PrintDiff(diffText)
+	return nil
+}
+
+func handleDiffCommand2(args []string, isClipboard *bool) error {
+
+	var filePath string
+    // var text string
+    useLast := false
+    wordDiff := false
+    var selectedBackup BackupInfo
+    // var err error
+
+    // Parse arguments
+    // for i := 0; i < len(args); i++ {
+    for i := 0; i < len(args); i++ {
+        arg := args[i]
+        
+        if arg == "--last" || arg == "-lt" {
+            useLast = true
+            continue
+        }
+        if arg == "--word-diff" {
+            wordDiff = true
+            continue
+        }
+        if arg == "--context" && i+1 < len(args) {
+            n, err := parseContextArg(args[i+1])
+            if err != nil {
+                return err
+            }
+            diffContext = n
+            i++
+            continue
+        }
+        
+        // First non-flag argument is assumed to be file path
+        if filePath == "" && arg[0] != '-' {
+            filePath = arg
+            logger.Printf("filePath [0]: %s", filePath)
+        }
+    }
+
+    logger.Printf("filePath [00]: %s", filePath)
+
+    if filePath != "" {
+        resolvedPath, err := resolveFilePath(filePath)
+        logger.Printf("resolvedPath: %s", resolvedPath)
+        if err != nil {
+            fmt.Printf("❎ %sfile%s %s%s%s %snot found!%s\n", 
+                ColorRed, ColorReset, ColorYellow, filePath, 
+                ColorReset, ColorRed, ColorReset)
+            return err
+        }
+        filePath = resolvedPath
+        logger.Printf("filePath [1]: %s", filePath)
+        
+        if !isFile(filePath) {
+            return fmt.Errorf("file does not exist: %s", filePath)
+        }
+    }
+
+    logger.Printf("filePath [2]: %s", filePath)
 
     if useLast {
         if filePath == "" {
@@ -1140,8 +2904,8 @@ func handleDiffCommand2(args []string, isClipboard *bool) error {
         }
 
         if len(backups) == 0 {
-            return fmt.Errorf("no backups found for: %s (check %s/ directory)", 
-                filePath, appConfig.BackupDirName)
+            return fmt.Errorf("%w for: %s (check %s/ directory)", 
+                errNoBackups, filePath, appConfig.BackupDirName)
         }
 
         selectedBackup = backups[0]
@@ -1153,7 +2917,7 @@ func handleDiffCommand2(args []string, isClipboard *bool) error {
         ColorMagenta, ColorReset, ColorWhite, ColorBlue, "PDiff2", ColorReset)
 
     // Run diff
-    pdiff := &PDiff2{}
+    pdiff := &PDiff2{WordDiff: wordDiff, Context: max(diffContext, 0), NoHighlight: diffNoHighlight}
 
 	// Handle different comparison scenarios
     if *isClipboard && filePath != "" {
@@ -1330,7 +3094,7 @@ func checkAMergeInstalled() string {
 
 func runDelta(file1, file2 string) error {
 	if checkDeltaInstalled() == "" {
-		return fmt.Errorf("delta is not installed. Install it from: https://github.com/dandavison/delta")
+		return fmt.Errorf("delta %w. Install it from: https://github.com/dandavison/delta", errToolMissing)
 	}
 
 	cmd := exec.Command("delta", file1, file2)
@@ -1357,7 +3121,7 @@ func runDelta(file1, file2 string) error {
 
 func runMeld(file1, file2 string) error {
 	if checkMeldInstalled() == "" {
-		return fmt.Errorf("meld is not installed. Install it from: https://meldmerge.org")
+		return fmt.Errorf("meld %w. Install it from: https://meldmerge.org", errToolMissing)
 	}
 
 	cmd := exec.Command("meld", file1, file2)
@@ -1384,8 +3148,8 @@ func runMeld(file1, file2 string) error {
 
 func runWinMerge(file1, file2 string) error {
 	exe := checkWinMergeInstalled()
-	if exe != "" {
-		return fmt.Errorf("winmerge is not installed. Install it from: https://winmerge.org")
+	if exe == "" {
+		return fmt.Errorf("winmerge %w. Install it from: https://winmerge.org", errToolMissing)
 	}
 
 	cmd := exec.Command(exe, file1, file2)
@@ -1412,8 +3176,8 @@ func runWinMerge(file1, file2 string) error {
 
 func runAMerge(file1, file2 string) error {
 	exe := checkAMergeInstalled()
-	if exe != "" {
-		return fmt.Errorf("winmerge is not installed. Install it from: https://www.araxis.com/merge")
+	if exe == "" {
+		return fmt.Errorf("araxis merge %w. Install it from: https://www.araxis.com/merge", errToolMissing)
 	}
 
 	cmd := exec.Command(exe, file1, file2)
@@ -1439,60 +3203,252 @@ func runAMerge(file1, file2 string) error {
 }
 
 
+// ============================================================================
+// SYMLINK TRAVERSAL - Opt-in following of directory symlinks
+// ============================================================================
+
+// markVisitedDir records the resolved location of a directory and reports
+// whether it had already been entered. Directories are keyed by their fully
+// resolved path, so two routes (real path and symlink) to the same directory
+// share one entry and a link pointing back at an ancestor is detected.
+func markVisitedDir(path string, visited map[string]bool) bool {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		realPath = path
+	}
+	if visited[realPath] {
+		return true
+	}
+	visited[realPath] = true
+	return false
+}
+
+// statForTraversal stats a path for tree/status walks and reports whether a
+// directory should be descended into. Symlinked directories are only entered
+// when --follow-symlinks (or follow_symlinks in config) is enabled, and each
+// resolved directory is entered at most once to protect against cycles.
+func statForTraversal(path string, visited map[string]bool) (os.FileInfo, bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		if followSymlinks && info.IsDir() && markVisitedDir(path, visited) {
+			return info, false, nil
+		}
+		return info, info.IsDir(), nil
+	}
+
+	// Symlinks to regular files are always resolved so their content is used
+	target, err := os.Stat(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !target.IsDir() {
+		return target, false, nil
+	}
+
+	if !followSymlinks {
+		logger.Printf("Not following directory symlink: %s", path)
+		return target, false, nil
+	}
+
+	if markVisitedDir(path, visited) {
+		logger.Printf("Symlink cycle detected, skipping: %s", path)
+		return target, false, nil
+	}
+
+	return target, true, nil
+}
+
+// walkFollowingSymlinks behaves like filepath.Walk but, when --follow-symlinks
+// is enabled, also descends into symlinked directories. Paths inside a followed
+// link are reported under the link's path, not the target's. Cycles are broken
+// by the shared visited set.
+func walkFollowingSymlinks(root string, visited map[string]bool, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !followSymlinks {
+			return fn(path, info, err)
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			if info.IsDir() && path == root {
+				markVisitedDir(path, visited)
+			} else if info.IsDir() && markVisitedDir(path, visited) {
+				return filepath.SkipDir
+			}
+			return fn(path, info, nil)
+		}
+
+		target, statErr := os.Stat(path)
+		if statErr != nil || !target.IsDir() {
+			return fn(path, info, nil)
+		}
+
+		if markVisitedDir(path, visited) {
+			logger.Printf("Symlink cycle detected, skipping: %s", path)
+			return nil
+		}
+
+		if err := fn(path, target, nil); err != nil {
+			if err == filepath.SkipDir {
+				return nil
+			}
+			return err
+		}
+
+		realPath, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil
+		}
+
+		return walkFollowingSymlinks(realPath, visited, func(p string, i os.FileInfo, e error) error {
+			if p == realPath {
+				return nil
+			}
+			rel, relErr := filepath.Rel(realPath, p)
+			if relErr != nil {
+				return nil
+			}
+			return fn(filepath.Join(path, rel), i, e)
+		})
+	})
+}
+
 // ============================================================================
 // CHECK/STATUS COMMAND - Show file status (git-like)
 // ============================================================================
 
 // compareFileWithBackup compares a file with its last backup
 func compareFileWithBackup(filePath string) (FileStatus, error) {
+	status, _, err := compareFileWithLastBackup(filePath)
+	return status, err
+}
+
+// compareFileWithLastBackup compares a file with its last backup and also
+// returns that backup's modification time (zero if there is no backup), so
+// callers can show its age without listing the backups a second time
+func compareFileWithLastBackup(filePath string) (FileStatus, time.Time, error) {
 	// Check if file exists
-	_, err := os.Stat(filePath)
+	fileInfo, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
-		return FileStatusDeleted, nil
+		return FileStatusDeleted, time.Time{}, nil
 	}
 	if err != nil {
-		return FileStatusUnchanged, err
+		return FileStatusUnchanged, time.Time{}, err
 	}
 
 	// Get last backup
 	backups, err := listBackups(filePath)
 	if err != nil {
-		return FileStatusUnchanged, err
+		return FileStatusUnchanged, time.Time{}, err
 	}
 
 	// No backups = new file
 	if len(backups) == 0 {
-		return FileStatusNew, nil
+		return FileStatusNew, time.Time{}, nil
 	}
 
-	// Get last backup content
+	// Fast path: same size and mtime as when the last backup was taken means
+	// the file was not touched since, so skip reading both contents
 	lastBackup := backups[0]
-	backupContent, err := os.ReadFile(lastBackup.Path)
+	if fileInfo.Size() == lastBackup.Size {
+		metadata, err := readBackupMetadata(lastBackup.Path)
+		if err == nil && metadata != nil && !metadata.OriginalModTime.IsZero() &&
+			metadata.Size == fileInfo.Size() && metadata.OriginalModTime.Equal(fileInfo.ModTime()) {
+			return FileStatusUnchanged, lastBackup.ModTime, nil
+		}
+	}
+
+	// Get last backup content
+	backupContent, err := backupStore.Read(lastBackup.Path)
 	if err != nil {
-		return FileStatusUnchanged, fmt.Errorf("failed to read backup: %w", err)
+		return FileStatusUnchanged, lastBackup.ModTime, fmt.Errorf("failed to read backup: %w", err)
 	}
 
 	// Get current file content
 	currentContent, err := os.ReadFile(filePath)
 	if err != nil {
-		return FileStatusUnchanged, fmt.Errorf("failed to read file: %w", err)
+		return FileStatusUnchanged, lastBackup.ModTime, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Compare content
 	if string(backupContent) == string(currentContent) {
-		return FileStatusUnchanged, nil
+		return FileStatusUnchanged, lastBackup.ModTime, nil
+	}
+	if mode := appConfig.NormalizeLineEndings; lineEndingModeOn(mode) &&
+		bytes.Equal(normalizeLineEndings(backupContent, mode), normalizeLineEndings(currentContent, mode)) {
+		return FileStatusUnchanged, lastBackup.ModTime, nil
+	}
+	if statusIgnoreWhitespace && normalizeWhitespace(string(backupContent)) == normalizeWhitespace(string(currentContent)) {
+		return FileStatusUnchanged, lastBackup.ModTime, nil
+	}
+
+	return FileStatusModified, lastBackup.ModTime, nil
+}
+
+// validLineEndingMode reports whether mode is a normalize_line_endings value
+func validLineEndingMode(mode string) bool {
+	return mode == "" || mode == "off" || lineEndingModeOn(mode)
+}
+
+// lineEndingModeOn reports whether mode actually normalizes anything
+func lineEndingModeOn(mode string) bool {
+	return mode == "lf" || mode == "crlf"
+}
+
+// normalizeLineEndings rewrites every line ending in content as mode ("lf" or
+// "crlf"). Binary content and the "off" mode leave content as is.
+func normalizeLineEndings(content []byte, mode string) []byte {
+	if !lineEndingModeOn(mode) || looksBinary(content) {
+		return content
+	}
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	if mode == "crlf" {
+		content = bytes.ReplaceAll(content, []byte("\n"), []byte("\r\n"))
+	}
+	return content
+}
+
+// normalizeWhitespace drops what formatters tend to churn: CRLF vs LF,
+// trailing spaces/tabs and trailing blank lines. It is only used to compare
+// for status, the content itself is backed up unchanged.
+func normalizeWhitespace(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
 	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
 
-	return FileStatusModified, nil
+// formatAge renders how long ago t was in a compact form ("5m ago", "2h ago")
+func formatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo ago", int(d.Hours()/(24*30)))
+	default:
+		return fmt.Sprintf("%dy ago", int(d.Hours()/(24*365)))
+	}
 }
 
 // buildStatusTree builds a tree with file status information
-func buildStatusTree(path string, gitignore *GitIgnore, exceptions map[string]bool, depth int, maxDepth int) (*FileStatusInfo, error) {
+func buildStatusTree(path string, gitignore *GitIgnore, exceptions map[string]bool, depth int, maxDepth int, visited map[string]bool) (*FileStatusInfo, error) {
 	if depth > maxDepth {
 		return nil, nil
 	}
 
-	info, err := os.Stat(path)
+	info, descend, err := statForTraversal(path, visited)
 	if err != nil {
 		return nil, err
 	}
@@ -1507,6 +3463,10 @@ func buildStatusTree(path string, gitignore *GitIgnore, exceptions map[string]bo
 		return nil, nil
 	}
 
+	if !gitignore.isIncluded(path, info.IsDir()) {
+		return nil, nil
+	}
+
 	relPath, _ := filepath.Rel(".", path)
 
 	node := &FileStatusInfo{
@@ -1518,18 +3478,7 @@ func buildStatusTree(path string, gitignore *GitIgnore, exceptions map[string]bo
 		Status:  FileStatusUnchanged,
 	}
 
-	// Check status for files only
-	if !info.IsDir() {
-		status, err := compareFileWithBackup(path)
-		if err != nil {
-			logger.Printf("Warning: failed to check status for %s: %v", path, err)
-			node.Status = FileStatusUnchanged
-		} else {
-			node.Status = status
-		}
-	}
-
-	if info.IsDir() {
+	if descend {
 		entries, err := os.ReadDir(path)
 		if err != nil {
 			return node, nil
@@ -1537,13 +3486,18 @@ func buildStatusTree(path string, gitignore *GitIgnore, exceptions map[string]bo
 
 		for _, entry := range entries {
 			childPath := filepath.Join(path, entry.Name())
-			childNode, err := buildStatusTree(childPath, gitignore, exceptions, depth+1, maxDepth)
+			childNode, err := buildStatusTree(childPath, gitignore, exceptions, depth+1, maxDepth, visited)
 			if err != nil || childNode == nil {
 				continue
 			}
 			node.Children = append(node.Children, childNode)
 		}
 
+		// With a whitelist, directories holding no included files are noise
+		if depth > 0 && len(node.Children) == 0 && gitignore != nil && len(gitignore.includes) > 0 {
+			return nil, nil
+		}
+
 		sort.Slice(node.Children, func(i, j int) bool {
 			if node.Children[i].IsDir != node.Children[j].IsDir {
 				return node.Children[i].IsDir
@@ -1552,9 +3506,65 @@ func buildStatusTree(path string, gitignore *GitIgnore, exceptions map[string]bo
 		})
 	}
 
+	// Status of files is computed once for the whole tree, in parallel
+	if depth == 0 {
+		computeStatuses(node, appConfig.MaxWorkers)
+	}
+
 	return node, nil
 }
 
+// computeStatuses fills in Status and LastBackup for every file node of the
+// tree using a bounded pool of workers (0 = one per CPU). Each node is
+// written by exactly one worker, so no further locking is needed and the
+// already-sorted tree layout is left untouched.
+func computeStatuses(root *FileStatusInfo, workers int) {
+	var files []*FileStatusInfo
+	var collect func(*FileStatusInfo)
+	collect = func(n *FileStatusInfo) {
+		if !n.IsDir {
+			files = append(files, n)
+		}
+		for _, child := range n.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	jobs := make(chan *FileStatusInfo)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range jobs {
+				status, lastBackup, err := compareFileWithLastBackup(node.Path)
+				if err != nil {
+					logger.Printf("Warning: failed to check status for %s: %v", node.Path, err)
+					node.Status = FileStatusUnchanged
+					continue
+				}
+				node.Status = status
+				node.LastBackup = lastBackup
+			}
+		}()
+	}
+
+	for _, node := range files {
+		jobs <- node
+	}
+	close(jobs)
+	wg.Wait()
+}
+
 // printStatusTree prints tree with status information
 func printStatusTree(node *FileStatusInfo, prefix string, isLast bool) {
 	if node == nil {
@@ -1579,6 +3589,9 @@ func printStatusTree(node *FileStatusInfo, prefix string, isLast bool) {
 		if node.Status != FileStatusUnchanged {
 			displayName = statusColor + displayName + ColorReset
 			statusStr = fmt.Sprintf(" %s[%s]%s", statusColor, node.Status.String(), ColorReset)
+			if !node.LastBackup.IsZero() {
+				statusStr += fmt.Sprintf(" %slast backup %s%s", ColorGray, formatAge(node.LastBackup), ColorReset)
+			}
 		} else {
 			displayName = ColorGreen + displayName + ColorReset
 		}
@@ -1622,6 +3635,28 @@ func countStatusFiles(node *FileStatusInfo) map[FileStatus]int {
 
 // handleCheckCommand handles the check/status command
 func handleCheckCommand(args []string) error {
+	showChanges := false
+	showArgs := []string{}
+	statusIgnoreWhitespace = appConfig.CheckIgnoreWhitespace
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--show":
+			showChanges = true
+			args = append(args[:i:i], args[i+1:]...)
+			i--
+		case "--ignore-whitespace":
+			statusIgnoreWhitespace = true
+			args = append(args[:i:i], args[i+1:]...)
+			i--
+		case "--context":
+			if i+1 < len(args) {
+				showArgs = append(showArgs, "--context", args[i+1])
+				args = append(args[:i:i], args[i+2:]...)
+				i--
+			}
+		}
+	}
+
 	// If filename provided, check single file (existing behavior)
 	if len(args) > 0 && args[0] != "" && args[0] != "-c" && args[0] != "--check" {
 		filename := args[0]
@@ -1630,7 +3665,7 @@ func handleCheckCommand(args []string) error {
 			return err
 		}
 
-		status, err := compareFileWithBackup(filePath)
+		status, lastBackup, err := compareFileWithLastBackup(filePath)
 		if err != nil {
 			return err
 		}
@@ -1640,16 +3675,25 @@ func handleCheckCommand(args []string) error {
 		fmt.Printf("Status: %s%s%s\n", statusColor, status.String(), ColorReset)
 
 		if status == FileStatusModified {
-			backups, _ := listBackups(filePath)
-			if len(backups) > 0 {
-				fmt.Printf("Last backup: %s\n", backups[0].ModTime.Format("2006-01-02 15:04:05"))
+			if !lastBackup.IsZero() {
+				fmt.Printf("Last backup: %s %s(%s)%s\n", formatTime(lastBackup),
+					ColorGray, formatAge(lastBackup), ColorReset)
 			}
 		} else if status == FileStatusNew {
 			fmt.Printf("No backups found (new file)\n")
 		}
 
+		// --show jumps straight into the changed regions
+		if showChanges && status == FileStatusModified {
+			fmt.Println()
+			return handleShowCommand(append([]string{filePath, "--changes"}, showArgs...))
+		}
+
 		return nil
 	}
+	if showChanges {
+		return fmt.Errorf("--show needs a file: pt check <file> --show")
+	}
 
 	// No filename = check all files (like git status)
 	fmt.Printf("\n%s📊 PT Status%s\n\n", ColorBold+ColorCyan, ColorReset)
@@ -1695,7 +3739,7 @@ func handleCheckCommand(args []string) error {
 	exceptions[appConfig.BackupDirName] = true
 
 	// Build status tree
-	tree, err := buildStatusTree(projectRoot, gitignore, exceptions, 0, appConfig.MaxSearchDepth)
+	tree, err := buildStatusTree(projectRoot, gitignore, exceptions, 0, appConfig.MaxSearchDepth, make(map[string]bool))
 	if err != nil {
 		return fmt.Errorf("failed to build status tree: %w", err)
 	}
@@ -1745,47 +3789,67 @@ func handleCheckCommand(args []string) error {
 // COMMIT COMMAND - Backup all changed files
 // ============================================================================
 
-// collectChangedFiles collects all files that need to be backed up
-func collectChangedFiles(node *FileStatusInfo, changedFiles *[]string) {
+// collectChangedFiles collects all files that need to be backed up.
+// With includeUnchanged (commit --all) unchanged files are collected too.
+// The nodes are returned as-is so callers can reuse the status computed
+// by buildStatusTree instead of comparing against the backups again.
+func collectChangedFiles(node *FileStatusInfo, changedFiles *[]*FileStatusInfo, includeUnchanged bool) {
 	if !node.IsDir {
-		if node.Status == FileStatusModified || node.Status == FileStatusNew {
-			*changedFiles = append(*changedFiles, node.Path)
+		if node.Status == FileStatusModified || node.Status == FileStatusNew ||
+			(includeUnchanged && node.Status == FileStatusUnchanged) {
+			*changedFiles = append(*changedFiles, node)
 		}
 	}
 	
 	for _, child := range node.Children {
-		collectChangedFiles(child, changedFiles)
+		collectChangedFiles(child, changedFiles, includeUnchanged)
 	}
 }
 
-// handleCommitCommand handles the commit command (backup all changed files)
-func handleCommitCommand(args []string) error {
-	// Parse commit message
-	commitMessage := ""
-	for i := range args {
-		if args[i] == "-m" || args[i] == "--message" {
-			if i+1 < len(args) {
-				commitMessage = args[i+1]
-				break
-			}
-		}
+// countUnchangedFiles counts the files under node that match their last
+// backup
+func countUnchangedFiles(node *FileStatusInfo) int {
+	count := 0
+	if !node.IsDir && node.Status == FileStatusUnchanged {
+		count++
 	}
+	for _, child := range node.Children {
+		count += countUnchangedFiles(child)
+	}
+	return count
+}
 
-	if commitMessage == "" {
-		return fmt.Errorf("commit message required. Use: pt commit -m \"your message\"")
+// formatProgress returns an "[i/N]" counter followed by an ETA derived from the
+// average time spent per item so far
+func formatProgress(done, total int, start time.Time) string {
+	counter := fmt.Sprintf("[%d/%d]", done, total)
+	if done == 0 || done >= total {
+		return counter
 	}
 
-	// Add "commit: " prefix to message
-	commitMessage = "commit: " + commitMessage
+	avg := time.Since(start) / time.Duration(done)
+	remaining := avg * time.Duration(total-done)
+	if remaining < time.Second {
+		return counter
+	}
 
-	fmt.Printf("\n%s📦 Committing changes...%s\n\n", ColorBold+ColorCyan, ColorReset)
+	return fmt.Sprintf("%s ETA %s", counter, remaining.Round(time.Second))
+}
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+// printProgressLine redraws a single status line in place (TTY only)
+func printProgressLine(done, total int, start time.Time, current string) {
+	line := fmt.Sprintf("%s %s", formatProgress(done, total, start), current)
+	width := getTerminalWidth() - 1
+	if width > 0 && len([]rune(line)) > width {
+		runes := []rune(line)
+		line = string(runes[:width])
 	}
+	fmt.Printf("\r\033[K%s%s%s", ColorCyan, line, ColorReset)
+}
 
-	// Try to find project root (where .git or .pt is)
+// findProjectRoot returns the directory holding .pt (or the git root), falling
+// back to cwd when neither is found
+func findProjectRoot(cwd string) string {
 	projectRoot := cwd
 	ptRoot, err := findPTRoot(cwd)
 	if err == nil && ptRoot != "" {
@@ -1805,12 +3869,12 @@ func handleCommitCommand(args []string) error {
 		}
 	}
 
-	// Show which directory we're scanning
-	relRoot, _ := filepath.Rel(cwd, projectRoot)
-	if relRoot != "" && relRoot != "." {
-		fmt.Printf("%sCommitting from project root:%s %s\n\n", ColorGray, ColorReset, projectRoot)
-	}
+	return projectRoot
+}
 
+// buildProjectStatusTree builds the status tree for projectRoot, honouring
+// .gitignore/.ptignore and skipping the backup directory
+func buildProjectStatusTree(projectRoot string) (*FileStatusInfo, error) {
 	// Load gitignore
 	gitignore, err := loadGitIgnoreAndPtIgnore(projectRoot)
 	if err != nil {
@@ -1821,42 +3885,231 @@ func handleCommitCommand(args []string) error {
 	exceptions[appConfig.BackupDirName] = true
 
 	// Build status tree to find changed files
-	tree, err := buildStatusTree(projectRoot, gitignore, exceptions, 0, appConfig.MaxSearchDepth)
+	tree, err := buildStatusTree(projectRoot, gitignore, exceptions, 0, appConfig.MaxSearchDepth, make(map[string]bool))
 	if err != nil {
-		return fmt.Errorf("failed to build status tree: %w", err)
+		return nil, fmt.Errorf("failed to build status tree: %w", err)
 	}
 
 	if tree == nil {
-		return fmt.Errorf("no files found")
+		return nil, fmt.Errorf("no files found")
 	}
 
-	// Collect all changed files
-	var changedFiles []string
-	collectChangedFiles(tree, &changedFiles)
+	return tree, nil
+}
 
-	if len(changedFiles) == 0 {
-		fmt.Printf("%s✓ No changes to commit. All files are up to date.%s\n", ColorGreen, ColorReset)
-		return nil
-	}
+// commitScope limits pt commit to the paths and globs given on the command
+// line. Directories match every file below them, other arguments are
+// expanded with expandGlobs, and a glob without a path separator (e.g.
+// "*.go") also matches by file name anywhere in the project, like a git
+// pathspec.
+type commitScope struct {
+	dirs  []string
+	files map[string]bool
+	names []string
+}
 
-	fmt.Printf("Files to backup:\n")
-	for i, file := range changedFiles {
-		relPath, _ := filepath.Rel(projectRoot, file)
-		status, _ := compareFileWithBackup(file)
-		statusColor := status.Color()
-		fmt.Printf("  %d. %s%s%s %s[%s]%s\n",
-			i+1, ColorGreen, relPath, ColorReset,
-			statusColor, status.String(), ColorReset)
-	}
-	fmt.Println()
+func newCommitScope(patterns []string) (*commitScope, error) {
+	scope := &commitScope{files: make(map[string]bool)}
 
-	// Ask for confirmation
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("Commit %d file(s) with message \"%s\"? (y/N): ", len(changedFiles), strings.TrimPrefix(commitMessage, "commit: "))
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(strings.ToLower(input))
+	var globs []string
+	for _, pattern := range patterns {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			absDir, err := filepath.Abs(pattern)
+			if err != nil {
+				return nil, err
+			}
+			scope.dirs = append(scope.dirs, absDir)
+			continue
+		}
+		if !strings.ContainsAny(pattern, `/\`) && strings.ContainsAny(pattern, "*?[") {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+			}
+			scope.names = append(scope.names, pattern)
+		}
+		globs = append(globs, pattern)
+	}
+
+	files, err := expandGlobs(globs)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if absFile, err := filepath.Abs(file); err == nil {
+			scope.files[absFile] = true
+		}
+	}
+
+	return scope, nil
+}
+
+func (s *commitScope) matches(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if s.files[absPath] {
+		return true
+	}
+	for _, dir := range s.dirs {
+		if rel, err := filepath.Rel(dir, absPath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	for _, name := range s.names {
+		if ok, _ := filepath.Match(name, filepath.Base(absPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// handleCommitCommand handles the commit command (backup all changed files)
+func handleCommitCommand(ctx context.Context, args []string) error {
+	// Parse commit message and options
+	commitMessage := ""
+	includeUnchanged := false
+	assumeYes := false
+	amend := false
+	force := false
+	var scopeArgs []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-m" || args[i] == "--message" {
+			if i+1 < len(args) {
+				commitMessage = args[i+1]
+				i++
+			}
+		} else if args[i] == "--all" || args[i] == "-a" {
+			includeUnchanged = true
+		} else if args[i] == "--yes" || args[i] == "-y" {
+			assumeYes = true
+		} else if args[i] == "--amend" {
+			amend = true
+		} else if args[i] == "--force" {
+			force = true
+		} else {
+			scopeArgs = append(scopeArgs, args[i])
+		}
+	}
+
+	if amend {
+		// Without -m the last commit keeps its message
+		return handleCommitAmend(ctx, commitMessage, assumeYes)
+	}
+
+	if commitMessage == "" {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("commit message required. Use: pt commit -m \"your message\"")
+		}
+
+		msg, err := editMessage()
+		if err != nil {
+			return err
+		}
+		if msg == "" {
+			return fmt.Errorf("aborting commit due to empty commit message")
+		}
+		commitMessage = msg
+	}
+
+	// Add "commit: " prefix to message
+	commitMessage = "commit: " + commitMessage
+
+	fmt.Printf("\n%s📦 Committing changes...%s\n\n", ColorBold+ColorCyan, ColorReset)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectRoot := findProjectRoot(cwd)
+
+	// Show which directory we're scanning
+	relRoot, _ := filepath.Rel(cwd, projectRoot)
+	if relRoot != "" && relRoot != "." {
+		fmt.Printf("%sCommitting from project root:%s %s\n\n", ColorGray, ColorReset, projectRoot)
+	}
+
+	tree, err := buildProjectStatusTree(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	// Collect all changed files
+	var changedFiles []*FileStatusInfo
+	collectChangedFiles(tree, &changedFiles, includeUnchanged)
+
+	// Paths and globs on the command line narrow the commit to those files
+	if len(scopeArgs) > 0 {
+		scope, err := newCommitScope(scopeArgs)
+		if err != nil {
+			return err
+		}
+		scoped := changedFiles[:0]
+		for _, file := range changedFiles {
+			if scope.matches(file.Path) {
+				scoped = append(scoped, file)
+			}
+		}
+		changedFiles = scoped
+
+		if len(changedFiles) == 0 {
+			fmt.Printf("%s✓ No changes to commit in %s.%s\n", ColorGreen, strings.Join(scopeArgs, " "), ColorReset)
+			return nil
+		}
+	}
+
+	// Backups over max_clipboard_size can't be restored later, so leave
+	// those files out unless --force is given
+	var oversized []*FileStatusInfo
+	if !force {
+		kept := changedFiles[:0]
+		for _, file := range changedFiles {
+			if file.Size > int64(appConfig.MaxClipboardSize) {
+				oversized = append(oversized, file)
+			} else {
+				kept = append(kept, file)
+			}
+		}
+		changedFiles = kept
+	}
+	if len(oversized) > 0 {
+		fmt.Printf("%s⚠️  Skipping %d file(s) larger than max_clipboard_size (%s); their backups could not be restored:%s\n",
+			ColorYellow, len(oversized), formatSize(int64(appConfig.MaxClipboardSize)), ColorReset)
+		for _, file := range oversized {
+			relPath, _ := filepath.Rel(projectRoot, file.Path)
+			fmt.Printf("  • %s (%s)\n", relPath, formatSize(file.Size))
+		}
+		fmt.Printf("%sUse --force to back them up anyway.%s\n\n", ColorGray, ColorReset)
+	}
+
+	if len(changedFiles) == 0 {
+		fmt.Printf("%s✓ No changes to commit. All files are up to date.%s\n", ColorGreen, ColorReset)
+		return nil
+	}
+
+	forcedCount := 0
+	fmt.Printf("Files to backup:\n")
+	for i, file := range changedFiles {
+		relPath, _ := filepath.Rel(projectRoot, file.Path)
+		status := file.Status
+		if status == FileStatusUnchanged {
+			forcedCount++
+		}
+		statusColor := status.Color()
+		fmt.Printf("  %d. %s%s%s %s[%s]%s\n",
+			i+1, ColorGreen, relPath, ColorReset,
+			statusColor, status.String(), ColorReset)
+	}
+	fmt.Println()
 
-	if input != "y" && input != "yes" {
+	// Ask for confirmation
+	prompt := fmt.Sprintf("Commit %d file(s) with message \"%s\"? (y/N): ", len(changedFiles), strings.TrimPrefix(commitMessage, "commit: "))
+	confirmed, err := confirmAction(prompt, assumeYes, "y", "yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
 		fmt.Println("❌ Commit cancelled")
 		return nil
 	}
@@ -1865,28 +4118,309 @@ func handleCommitCommand(args []string) error {
 	successCount := 0
 	failCount := 0
 
-	for _, file := range changedFiles {
-		relPath, _ := filepath.Rel(projectRoot, file)
+	// On a terminal, show a single updating progress line; otherwise (piped or
+	// redirected output) keep one line per file
+	isTTY := term.IsTerminal(int(os.Stdout.Fd()))
+	start := time.Now()
+	total := len(changedFiles)
+
+	// One id for the whole commit, so amend can tell it from an older
+	// commit with the same message
+	activeCommitID = generateShortID() + generateShortID()
+	defer func() { activeCommitID = "" }()
+
+	interrupted := false
+	for i, file := range changedFiles {
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
+		relPath, _ := filepath.Rel(projectRoot, file.Path)
 
 		// Create backup
-		_, err := autoRenameIfExists(file, commitMessage, false)
+		_, err := autoRenameIfExists(file.Path, commitMessage, false)
 		if err != nil {
+			if isTTY {
+				fmt.Print("\r\033[K")
+			}
 			fmt.Printf("%s✗%s %s: %v\n", ColorRed, ColorReset, relPath, err)
 			failCount++
 		} else {
-			fmt.Printf("%s✓%s %s\n", ColorGreen, ColorReset, relPath)
+			if !isTTY {
+				fmt.Printf("%s✓%s [%d/%d] %s\n", ColorGreen, ColorReset, i+1, total, relPath)
+			}
 			successCount++
 		}
+
+		if isTTY {
+			printProgressLine(i+1, total, start, relPath)
+		}
+	}
+
+	if isTTY {
+		fmt.Print("\r\033[K")
 	}
 
 	fmt.Println()
 	fmt.Printf("%s📦 Commit Summary:%s\n", ColorBold, ColorReset)
 	fmt.Printf("  %s✓ %d files backed up%s\n", ColorGreen, successCount, ColorReset)
+	if includeUnchanged {
+		fmt.Printf("  %s• %d changed, %d unchanged (forced by --all)%s\n",
+			ColorGray, len(changedFiles)-forcedCount, forcedCount, ColorReset)
+	}
 	if failCount > 0 {
 		fmt.Printf("  %s✗ %d files failed%s\n", ColorRed, failCount, ColorReset)
 	}
 	fmt.Printf("  💬 Message: \"%s\"\n", strings.TrimPrefix(commitMessage, "commit: "))
 
+	if interrupted {
+		return fmt.Errorf("commit stopped after %d of %d file(s): %w", successCount+failCount, total, errInterrupted)
+	}
+
+	return nil
+}
+
+// handleCommitAmend folds the current changes into the last commit, like git
+// commit --amend. The last commit is found from the newest "commit: " backup:
+// every file whose newest backup carries the same comment belongs to it.
+// Changed files in it get their backup replaced, other changed files are
+// added to it, and with -m the message of the whole commit is rewritten.
+func handleCommitAmend(ctx context.Context, message string, assumeYes bool) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectRoot := findProjectRoot(cwd)
+	tree, err := buildProjectStatusTree(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	var files []*FileStatusInfo
+	collectChangedFiles(tree, &files, true)
+
+	latest := make(map[string]BackupInfo)
+	latestCommitID := make(map[string]string)
+	lastComment := ""
+	lastCommitID := ""
+	var lastTime time.Time
+	for _, file := range files {
+		backups, err := listBackups(file.Path)
+		if err != nil || len(backups) == 0 {
+			continue
+		}
+		latest[file.Path] = backups[0]
+		if metadata, err := readBackupMetadata(backups[0].Path); err == nil && metadata != nil {
+			latestCommitID[file.Path] = metadata.CommitID
+		}
+		if strings.HasPrefix(backups[0].Comment, "commit: ") && backups[0].ModTime.After(lastTime) {
+			lastComment = backups[0].Comment
+			lastCommitID = latestCommitID[file.Path]
+			lastTime = backups[0].ModTime
+		}
+	}
+
+	if lastComment == "" {
+		return fmt.Errorf("nothing to amend: no previous commit found")
+	}
+
+	// A file belongs to the last commit when its newest backup carries the
+	// commit's id. Backups made before commit ids were recorded fall back to
+	// the grouping pt commits uses: same message, taken within
+	// commitGroupWindow of the commit. The message alone would also pick up
+	// files whose newest backup is an older commit with the same message.
+	inLastCommit := func(path string) bool {
+		backup, ok := latest[path]
+		if !ok || backup.Comment != lastComment {
+			return false
+		}
+		if lastCommitID != "" {
+			return latestCommitID[path] == lastCommitID
+		}
+		return latestCommitID[path] == "" && lastTime.Sub(backup.ModTime) <= commitGroupWindow
+	}
+
+	newComment := lastComment
+	if message != "" {
+		newComment = "commit: " + message
+	}
+
+	var changed, reworded []*FileStatusInfo
+	for _, file := range files {
+		inCommit := inLastCommit(file.Path)
+		if file.Status == FileStatusModified || file.Status == FileStatusNew {
+			changed = append(changed, file)
+		} else if inCommit && newComment != lastComment {
+			reworded = append(reworded, file)
+		}
+	}
+
+	if len(changed) == 0 && len(reworded) == 0 {
+		fmt.Printf("%s✓ Nothing to amend: no changes and no new message (use -m).%s\n", ColorGreen, ColorReset)
+		return nil
+	}
+
+	fmt.Printf("\n%s📦 Amending commit \"%s\" (%s)%s\n\n",
+		ColorBold+ColorCyan, strings.TrimPrefix(lastComment, "commit: "),
+		formatTime(lastTime), ColorReset)
+	for _, file := range changed {
+		relPath, _ := filepath.Rel(projectRoot, file.Path)
+		action := "replace backup"
+		if !inLastCommit(file.Path) {
+			action = "add to commit"
+		}
+		fmt.Printf("  %s%s%s %s[%s]%s %s\n", ColorGreen, relPath, ColorReset,
+			file.Status.Color(), file.Status.String(), ColorReset, action)
+	}
+	for _, file := range reworded {
+		relPath, _ := filepath.Rel(projectRoot, file.Path)
+		fmt.Printf("  %s%s%s %smessage only%s\n", ColorGreen, relPath, ColorReset, ColorGray, ColorReset)
+	}
+	fmt.Println()
+
+	prompt := fmt.Sprintf("Amend with message \"%s\"? (y/N): ", strings.TrimPrefix(newComment, "commit: "))
+	confirmed, err := confirmAction(prompt, assumeYes, "y", "yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("❌ Amend cancelled")
+		return nil
+	}
+
+	// The amended backups stay part of the same commit
+	activeCommitID = lastCommitID
+	if activeCommitID == "" {
+		activeCommitID = generateShortID() + generateShortID()
+	}
+	defer func() { activeCommitID = "" }()
+
+	successCount := 0
+	failCount := 0
+	interrupted := false
+	for _, file := range changed {
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
+		relPath, _ := filepath.Rel(projectRoot, file.Path)
+
+		// Back up the new content first so a failure never loses the old one
+		if _, err := autoRenameIfExists(file.Path, newComment, false); err != nil {
+			fmt.Printf("%s✗%s %s: %v\n", ColorRed, ColorReset, relPath, err)
+			failCount++
+			continue
+		}
+		if old, ok := latest[file.Path]; ok && inLastCommit(file.Path) {
+			if err := backupStore.Delete(old.Path); err != nil {
+				logger.Printf("Warning: failed to drop amended backup %s: %v", old.Path, err)
+			}
+		}
+		successCount++
+	}
+
+	for _, file := range reworded {
+		if interrupted || ctx.Err() != nil {
+			interrupted = true
+			break
+		}
+		relPath, _ := filepath.Rel(projectRoot, file.Path)
+		backup := latest[file.Path]
+		metadata, err := readBackupMetadata(backup.Path)
+		if err == nil && metadata == nil {
+			absPath, _ := filepath.Abs(file.Path)
+			metadata = &BackupMetadata{Timestamp: backup.ModTime, Size: backup.Size, Original: absPath}
+		}
+		if err == nil {
+			metadata.Comment = newComment
+			metadata.CommitID = activeCommitID
+			err = writeBackupMetadata(backup.Path, metadata)
+		}
+		if err != nil {
+			fmt.Printf("%s✗%s %s: %v\n", ColorRed, ColorReset, relPath, err)
+			failCount++
+			continue
+		}
+		successCount++
+	}
+
+	fmt.Println()
+	fmt.Printf("%s📦 Amend Summary:%s\n", ColorBold, ColorReset)
+	fmt.Printf("  %s✓ %d file(s) amended%s\n", ColorGreen, successCount, ColorReset)
+	if failCount > 0 {
+		fmt.Printf("  %s✗ %d file(s) failed%s\n", ColorRed, failCount, ColorReset)
+	}
+	fmt.Printf("  💬 Message: \"%s\"\n", strings.TrimPrefix(newComment, "commit: "))
+
+	if interrupted {
+		return fmt.Errorf("amend stopped after %d file(s): %w", successCount+failCount, errInterrupted)
+	}
+	if failCount > 0 {
+		return fmt.Errorf("%d file(s) could not be amended", failCount)
+	}
+	return nil
+}
+
+// handleBackupAllCommand snapshots every new or modified file in the
+// project (pt backup --all). Like commit it skips files equal to their last
+// backup; unlike commit it never prompts, since creating backups does not
+// touch the working files.
+func handleBackupAllCommand(ctx context.Context, comment string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectRoot := findProjectRoot(cwd)
+	tree, err := buildProjectStatusTree(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	var files []*FileStatusInfo
+	collectChangedFiles(tree, &files, false)
+	unchanged := countUnchangedFiles(tree)
+
+	if len(files) == 0 {
+		fmt.Printf("%s✓ No files to back up. All files match their last backup.%s\n", ColorGreen, ColorReset)
+		return nil
+	}
+
+	fmt.Printf("\n%s📦 Backing up %d file(s) under %s%s\n\n", ColorBold+ColorCyan, len(files), projectRoot, ColorReset)
+
+	successCount := 0
+	failCount := 0
+	interrupted := false
+	for _, file := range files {
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
+		relPath, _ := filepath.Rel(projectRoot, file.Path)
+		if _, err := autoRenameIfExists(file.Path, comment, false); err != nil {
+			fmt.Printf("%s✗%s %s: %v\n", ColorRed, ColorReset, relPath, err)
+			failCount++
+			continue
+		}
+		successCount++
+	}
+
+	fmt.Println()
+	fmt.Printf("  %s✓ %d files backed up%s\n", ColorGreen, successCount, ColorReset)
+	if unchanged > 0 {
+		fmt.Printf("  %s%d unchanged files skipped%s\n", ColorGray, unchanged, ColorReset)
+	}
+	if failCount > 0 {
+		fmt.Printf("  %s✗ %d files failed%s\n", ColorRed, failCount, ColorReset)
+	}
+	if interrupted {
+		return fmt.Errorf("backup stopped after %d of %d file(s): %w", successCount+failCount, len(files), errInterrupted)
+	}
+	if failCount > 0 {
+		return fmt.Errorf("%d file(s) could not be backed up", failCount)
+	}
+
 	return nil
 }
 
@@ -1894,12 +4428,12 @@ func handleCommitCommand(args []string) error {
 // TREE COMMAND - Display directory tree
 // ============================================================================
 
-func buildTree(path string, gitignore *GitIgnore, exceptions map[string]bool, depth int, maxDepth int) (*TreeNode, error) {
+func buildTree(path string, gitignore *GitIgnore, exceptions map[string]bool, depth int, maxDepth int, visited map[string]bool) (*TreeNode, error) {
 	if depth > maxDepth {
 		return nil, nil
 	}
 
-	info, err := os.Stat(path)
+	info, descend, err := statForTraversal(path, visited)
 	if err != nil {
 		return nil, err
 	}
@@ -1915,13 +4449,14 @@ func buildTree(path string, gitignore *GitIgnore, exceptions map[string]bool, de
 	}
 
 	node := &TreeNode{
-		Name:  baseName,
-		Path:  path,
-		IsDir: info.IsDir(),
-		Size:  info.Size(),
+		Name:    baseName,
+		Path:    path,
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
 	}
 
-	if info.IsDir() {
+	if descend {
 		entries, err := os.ReadDir(path)
 		if err != nil {
 			return node, nil
@@ -1929,7 +4464,7 @@ func buildTree(path string, gitignore *GitIgnore, exceptions map[string]bool, de
 
 		for _, entry := range entries {
 			childPath := filepath.Join(path, entry.Name())
-			childNode, err := buildTree(childPath, gitignore, exceptions, depth+1, maxDepth)
+			childNode, err := buildTree(childPath, gitignore, exceptions, depth+1, maxDepth, visited)
 			if err != nil || childNode == nil {
 				continue
 			}
@@ -1947,8 +4482,55 @@ func buildTree(path string, gitignore *GitIgnore, exceptions map[string]bool, de
 	return node, nil
 }
 
-func printTree(node *TreeNode, prefix string, isLast bool, showSize bool) {
-	if node == nil {
+// sortTree reorders the children of every directory by "size" (largest
+// first) or "mtime" (newest first), keeping directories ahead of files. A
+// directory counts with the total size and the newest mtime of what it
+// contains. reverse flips the order within each group. It returns node's own
+// size and mtime in that sense.
+func sortTree(node *TreeNode, by string, reverse bool) (int64, time.Time) {
+	if !node.IsDir {
+		return node.Size, node.ModTime
+	}
+
+	var total int64
+	latest := node.ModTime
+	sizes := make(map[*TreeNode]int64, len(node.Children))
+	times := make(map[*TreeNode]time.Time, len(node.Children))
+	for _, child := range node.Children {
+		size, modTime := sortTree(child, by, reverse)
+		sizes[child], times[child] = size, modTime
+		total += size
+		if modTime.After(latest) {
+			latest = modTime
+		}
+	}
+
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		if reverse {
+			a, b = b, a
+		}
+		switch by {
+		case "size":
+			if sizes[a] != sizes[b] {
+				return sizes[a] > sizes[b]
+			}
+		case "mtime":
+			if !times[a].Equal(times[b]) {
+				return times[a].After(times[b])
+			}
+		}
+		return a.Name < b.Name
+	})
+
+	return total, latest
+}
+
+func printTree(node *TreeNode, prefix string, isLast bool, showSize bool) {
+	if node == nil {
 		return
 	}
 
@@ -1988,10 +4570,24 @@ func printTree(node *TreeNode, prefix string, isLast bool, showSize bool) {
 func handleTreeCommand(args []string) error {
 	exceptions := make(map[string]bool)
 	startPath := "."
+	sortBy := "name"
+	reverse := false
 
 	i := 0
 	for i < len(args) {
-		if args[i] == "-e" || args[i] == "--exception" {
+		if args[i] == "--sort" {
+			if i+1 >= len(args) {
+				return fmt.Errorf("--sort requires a value")
+			}
+			sortBy = strings.ToLower(args[i+1])
+			if sortBy != "name" && sortBy != "size" && sortBy != "mtime" {
+				return fmt.Errorf("invalid --sort value %q (use size, mtime or name)", args[i+1])
+			}
+			i += 2
+		} else if args[i] == "--reverse" {
+			reverse = true
+			i++
+		} else if args[i] == "-e" || args[i] == "--exception" {
 			if i+1 >= len(args) {
 				return fmt.Errorf("-e/--exception requires a value")
 			}
@@ -2025,7 +4621,7 @@ func handleTreeCommand(args []string) error {
 		}
 	}
 
-	tree, err := buildTree(absPath, gitignore, exceptions, 0, appConfig.MaxSearchDepth)
+	tree, err := buildTree(absPath, gitignore, exceptions, 0, appConfig.MaxSearchDepth, make(map[string]bool))
 	if err != nil {
 		return fmt.Errorf("failed to build tree: %w", err)
 	}
@@ -2034,6 +4630,11 @@ func handleTreeCommand(args []string) error {
 		return fmt.Errorf("no files to display")
 	}
 
+	// buildTree already sorts by name
+	if sortBy != "name" || reverse {
+		sortTree(tree, sortBy, reverse)
+	}
+
 	fmt.Printf("\n%s%s%s\n", ColorBold, tree.Name, ColorReset)
 	if tree.IsDir && len(tree.Children) > 0 {
 		for i, child := range tree.Children {
@@ -2091,6 +4692,10 @@ func handleRemoveCommand(args []string) error {
 
 	filename := args[0]
 	comment := ""
+	useTrash := appConfig.UseTrash
+	recursive := false
+	dryRun := false
+	assumeYes := false
 
 	for i := 1; i < len(args); i++ {
 		if args[i] == "-m" || args[i] == "--message" {
@@ -2099,25 +4704,44 @@ func handleRemoveCommand(args []string) error {
 			}
 			i++
 			comment = args[i]
-			break
+		} else if args[i] == "--trash" {
+			useTrash = true
+		} else if args[i] == "--no-trash" {
+			useTrash = false
+		} else if args[i] == "-r" || args[i] == "--recursive" {
+			recursive = true
+		} else if args[i] == "--dry-run" {
+			dryRun = true
+		} else if args[i] == "--yes" || args[i] == "-y" {
+			assumeYes = true
 		}
 	}
 
-	filePath, err := resolveFilePath(filename)
-	if err != nil {
-		return err
+	// Directories are taken as given; resolveFilePath only finds files
+	filePath := filename
+	if info, err := os.Stat(filename); err != nil || !info.IsDir() {
+		filePath, err = resolveFilePath(filename)
+		if err != nil {
+			return err
+		}
 	}
 
 	info, err := os.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("file not found: %s", filePath)
+			return fmt.Errorf("file %w: %s", errNotFound, filePath)
 		}
 		return fmt.Errorf("failed to check file: %w", err)
 	}
 
 	if info.IsDir() {
-		return fmt.Errorf("cannot remove directories, only files")
+		if !recursive {
+			return withHint(fmt.Errorf("cannot remove directories without -r"), fmt.Sprintf("use: pt -rm -r %s", filename))
+		}
+		return removeDirectoryWithBackups(filePath, comment, useTrash, dryRun, assumeYes)
+	}
+	if dryRun {
+		return fmt.Errorf("--dry-run is only supported with -r <dir>")
 	}
 
 	if info.Size() > 0 {
@@ -2135,13 +4759,24 @@ func handleRemoveCommand(args []string) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	err = os.Remove(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
-	}
+	if useTrash {
+		// The backup above stays the primary copy; the trash is a second one
+		if err := moveToTrash(filePath); err != nil {
+			return fmt.Errorf("failed to move file to trash: %w", err)
+		}
+		logger.Printf("File moved to trash: %s (%d bytes)", filePath, len(content))
+		auditLog("delete", filePath, int64(len(content)), "trash", "true")
+		fmt.Printf("🗑️  File moved to trash: %s\n", filePath)
+	} else {
+		err = os.Remove(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
 
-	logger.Printf("File deleted: %s (%d bytes)", filePath, len(content))
-	fmt.Printf("🗑️  File deleted: %s\n", filePath)
+		logger.Printf("File deleted: %s (%d bytes)", filePath, len(content))
+		auditLog("delete", filePath, int64(len(content)))
+		fmt.Printf("🗑️  File deleted: %s\n", filePath)
+	}
 
 	// emptyFile, err := os.Create(filePath)
 	// if err != nil {
@@ -2160,11 +4795,156 @@ func handleRemoveCommand(args []string) error {
 	return nil
 }
 
+// removeDirectoryWithBackups backs up every non-empty file under dir and
+// then removes the whole tree (pt -rm -r <dir>). Nothing is removed unless
+// every backup succeeded, so pt restore -r <dir> can bring it all back.
+func removeDirectoryWithBackups(dir, comment string, useTrash, dryRun, assumeYes bool) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	var totalSize int64
+	err = filepath.Walk(absDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// Removing the store would take the backups along with the files
+			if info.Name() == appConfig.BackupDirName {
+				return fmt.Errorf("%s contains a backup store (%s), refusing to remove it", absDir, path)
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+			totalSize += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Every backup goes to the store serving the directory's parent, so
+	// none of them is created inside the tree that is about to go. Without
+	// this a file below a .git (or with no store above it) would get a new
+	// .pt next to it and lose its backup with the tree.
+	ptDir, err := predictPTDir(absDir)
+	if err != nil {
+		return fmt.Errorf("failed to find %s root: %w", appConfig.BackupDirName, err)
+	}
+	if isWithinDir(absDir, ptDir) {
+		return fmt.Errorf("the backup store for %s (%s) is inside it, refusing to remove it", absDir, ptDir)
+	}
+
+	if dryRun {
+		fmt.Printf("\n%s🔍 Dry run: nothing will be removed%s\n", ColorYellow, ColorReset)
+	}
+	fmt.Printf("\n🗑️  %s: %d file(s), %s\n", absDir, len(files), formatSize(totalSize))
+	fmt.Printf("%s   Backups go to %s%s\n\n", ColorGray, ptDir, ColorReset)
+
+	if dryRun {
+		for _, path := range files {
+			relPath, _ := filepath.Rel(absDir, path)
+			if info, err := os.Stat(path); err == nil && info.Size() == 0 {
+				fmt.Printf("  %s• %s (empty, no backup)%s\n", ColorGray, relPath, ColorReset)
+			} else {
+				fmt.Printf("  %s➜ %s would be backed up and removed%s\n", ColorCyan, relPath, ColorReset)
+			}
+		}
+		return nil
+	}
+
+	ok, err := confirmAction(fmt.Sprintf("Back up and remove %d file(s) (%s) under %s? [y/N]: ",
+		len(files), formatSize(totalSize), absDir), assumeYes, "y", "yes")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("❌ Remove cancelled")
+		return nil
+	}
+
+	if ptDir, err = ensurePTDir(filepath.Dir(absDir)); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", appConfig.BackupDirName, err)
+	}
+
+	if comment == "" {
+		comment = "Deleted directory backup"
+	}
+	backedUp := 0
+	for i, path := range files {
+		if interruptCtx.Err() != nil {
+			return fmt.Errorf("%w: %d of %d file(s) backed up, nothing removed", errInterrupted, backedUp, len(files))
+		}
+		relPath, _ := filepath.Rel(absDir, path)
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 {
+			continue
+		}
+		if err := backupFileInto(ptDir, path, comment, info); err != nil {
+			return fmt.Errorf("failed to back up %s, nothing removed: %w", relPath, err)
+		}
+		backedUp++
+		fmt.Printf("%s✓%s [%d/%d] %s\n", ColorGreen, ColorReset, i+1, len(files), relPath)
+	}
+
+	// The store must still be outside the tree (and present) before the
+	// tree goes
+	if info, err := os.Stat(ptDir); err != nil || !info.IsDir() || isWithinDir(absDir, ptDir) {
+		return fmt.Errorf("backup store %s is missing or inside %s, nothing removed", ptDir, absDir)
+	}
+
+	if useTrash {
+		if err := moveToTrash(absDir); err != nil {
+			return fmt.Errorf("failed to move directory to trash: %w", err)
+		}
+		auditLog("delete", absDir, totalSize, "files", strconv.Itoa(len(files)), "trash", "true")
+		fmt.Printf("\n🗑️  Directory moved to trash: %s\n", absDir)
+	} else {
+		if err := os.RemoveAll(absDir); err != nil {
+			return fmt.Errorf("failed to delete directory: %w", err)
+		}
+		auditLog("delete", absDir, totalSize, "files", strconv.Itoa(len(files)))
+		fmt.Printf("\n🗑️  Directory deleted: %s\n", absDir)
+	}
+	logger.Printf("Directory removed: %s (%d files, %d backed up)", absDir, len(files), backedUp)
+
+	fmt.Printf("ℹ️  %d file(s) backed up to %s/\n", backedUp, appConfig.BackupDirName)
+	fmt.Printf("💡 Use 'pt restore -r %s' to restore it\n", dir)
+	return nil
+}
+
+// isWithinDir reports whether path is dir or lies below it
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // ============================================================================
 // FIX COMMAND - Detect and fix manually moved files
 // ============================================================================
 
+// handleFixCommand finds backup directories whose file is gone and offers to
+// reattach them to where the file went (pt fix). With --yes it auto-fixes
+// without asking, skipping (and listing) orphans with several equally good
+// matches unless --newest says to take the most recently modified one;
+// --clean --yes removes the orphaned backups instead.
 func handleFixCommand(args []string) error {
+	assumeYes, clean, newest := false, false, false
+	for _, arg := range args {
+		switch arg {
+		case "--yes", "-y":
+			assumeYes = true
+		case "--clean":
+			clean = true
+		case "--newest":
+			newest = true
+		}
+	}
+
 	fmt.Printf("\n🔍 Scanning for orphaned backups...\n\n")
 	
 	cwd, err := os.Getwd()
@@ -2210,15 +4990,9 @@ func handleFixCommand(args []string) error {
 		
 		// Check if the expected file exists
 		if _, err := os.Stat(expectedFullPath); os.IsNotExist(err) {
-			// File doesn't exist at expected location
-			// Try to find it elsewhere
-			baseName := filepath.Base(expectedPath)
-			matches, _ := findFilesRecursive(baseName, ptParent)
-			
 			orphaned = append(orphaned, OrphanedBackup{
 				BackupDir:    path,
 				ExpectedPath: expectedFullPath,
-				ActualFiles:  matches,
 			})
 		}
 		
@@ -2228,6 +5002,14 @@ func handleFixCommand(args []string) error {
 	if err != nil {
 		return err
 	}
+
+	// Look for where each file went, by name and by content
+	if len(orphaned) > 0 {
+		files, _ := listProjectFiles(ptParent)
+		for i := range orphaned {
+			orphaned[i].Candidates = rankFixCandidates(orphaned[i], files)
+		}
+	}
 	
 	if len(orphaned) == 0 {
 		fmt.Printf("%s✅ No orphaned backups found. All files are in their expected locations.%s\n", 
@@ -2242,11 +5024,11 @@ func handleFixCommand(args []string) error {
 			idx+1, ColorRed, ColorReset, filepath.Base(orphan.BackupDir))
 		fmt.Printf("    Expected: %s (NOT FOUND)\n", orphan.ExpectedPath)
 		
-		if len(orphan.ActualFiles) > 0 {
+		if len(orphan.Candidates) > 0 {
 			fmt.Printf("    %sPossible matches found:%s\n", ColorGreen, ColorReset)
-			for i, match := range orphan.ActualFiles {
-				relMatch, _ := filepath.Rel(ptParent, match)
-				fmt.Printf("      %d) %s\n", i+1, relMatch)
+			for i, match := range orphan.Candidates {
+				relMatch, _ := filepath.Rel(ptParent, match.Path)
+				fmt.Printf("      %d) %s %s%s%s\n", i+1, relMatch, ColorGray, match.describe(), ColorReset)
 			}
 		} else {
 			fmt.Printf("    %sNo matches found (file may be deleted)%s\n", ColorYellow, ColorReset)
@@ -2254,9 +5036,20 @@ func handleFixCommand(args []string) error {
 		fmt.Println()
 	}
 	
+	if clean {
+		return cleanOrphanedBackups(orphaned, assumeYes)
+	}
+	if assumeYes {
+		return autoFixOrphanedBackups(orphaned, ptRoot, ptParent, newest)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return withHint(fmt.Errorf("pt fix needs a choice but stdin is not a terminal"),
+			"auto-fix with: pt fix --yes, or remove the orphaned backups with: pt fix --clean --yes")
+	}
+
 	// Ask user what to do
 	fmt.Println("Options:")
-	fmt.Println("  1. Auto-fix: Update backup references for files with single match")
+	fmt.Println("  1. Auto-fix: Update backup references for files with one clear match")
 	fmt.Println("  2. Manual: Select correct file for each orphaned backup")
 	fmt.Println("  3. Clean: Remove orphaned backups (files deleted)")
 	fmt.Println("  0. Cancel")
@@ -2268,11 +5061,11 @@ func handleFixCommand(args []string) error {
 	
 	switch choice {
 	case "1":
-		return autoFixOrphanedBackups(orphaned, ptRoot, ptParent)
+		return autoFixOrphanedBackups(orphaned, ptRoot, ptParent, newest)
 	case "2":
 		return manualFixOrphanedBackups(orphaned, ptRoot, ptParent)
 	case "3":
-		return cleanOrphanedBackups(orphaned)
+		return cleanOrphanedBackups(orphaned, assumeYes)
 	case "0":
 		fmt.Println("❌ Cancelled")
 		return nil
@@ -2281,37 +5074,203 @@ func handleFixCommand(args []string) error {
 	}
 }
 
-func findFilesRecursive(filename string, rootDir string) ([]string, error) {
-	matches := make([]string, 0)
-	
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+// fixSimilarityThreshold is how much of the newest backup's lines a file
+// must share to count as the renamed and edited original
+const fixSimilarityThreshold = 0.8
+
+// projectFile is a file found by listProjectFiles
+type projectFile struct {
+	Path string
+	Size int64
+}
+
+// listProjectFiles lists the files under root, skipping the backup store
+// and .git
+func listProjectFiles(root string) ([]projectFile, error) {
+	var files []projectFile
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
-		
-		// Skip .pt directory
-		if info.IsDir() && info.Name() == appConfig.BackupDirName {
-			return filepath.SkipDir
+		if info.IsDir() {
+			if info.Name() == appConfig.BackupDirName || info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
 		}
-		
-		if !info.IsDir() && info.Name() == filename {
-			matches = append(matches, path)
+		if info.Mode().IsRegular() {
+			files = append(files, projectFile{Path: path, Size: info.Size()})
 		}
-		
 		return nil
 	})
-	
-	return matches, err
+	return files, err
+}
+
+// newestBackupIn returns the most recent backup file in a backup directory
+func newestBackupIn(backupDir string) string {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return ""
+	}
+	newest := ""
+	var newestTime time.Time
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".meta.json") || name == backupDirConfigFile {
+			continue
+		}
+		info, err := entry.Info()
+		if err == nil && (newest == "" || info.ModTime().After(newestTime)) {
+			newest = filepath.Join(backupDir, name)
+			newestTime = info.ModTime()
+		}
+	}
+	return newest
+}
+
+// contentHash identifies file content for exact-match comparisons
+func contentHash(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// rankFixCandidates finds the files an orphaned backup may belong to: files
+// with the missing file's name, files with exactly the content of its newest
+// backup, and files of the same type that share most of its lines (renamed
+// and edited). Identical content ranks first, then similarity, then name.
+func rankFixCandidates(orphan OrphanedBackup, files []projectFile) []fixCandidate {
+	baseName := filepath.Base(orphan.ExpectedPath)
+	ext := filepath.Ext(orphan.ExpectedPath)
+
+	var backup []byte
+	if newest := newestBackupIn(orphan.BackupDir); newest != "" {
+		backup, _ = os.ReadFile(newest)
+	}
+	backupHash := contentHash(backup)
+	backupSize := int64(len(backup))
+
+	var candidates []fixCandidate
+	for _, file := range files {
+		nameMatch := filepath.Base(file.Path) == baseName
+		// Only read files that could plausibly be the same file
+		sameType := filepath.Ext(file.Path) == ext && backupSize > 0 &&
+			file.Size >= backupSize/2 && file.Size <= backupSize*2
+		if !nameMatch && !sameType {
+			continue
+		}
+		if file.Size > int64(appConfig.MaxClipboardSize) {
+			if nameMatch {
+				candidates = append(candidates, fixCandidate{Path: file.Path, NameMatch: true})
+			}
+			continue
+		}
+
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		candidate := fixCandidate{Path: file.Path, NameMatch: nameMatch}
+		if backupSize > 0 {
+			candidate.Identical = file.Size == backupSize && contentHash(content) == backupHash
+			candidate.Similarity = lineSimilarity(string(backup), string(content))
+		}
+		if nameMatch || candidate.Identical || candidate.Similarity >= fixSimilarityThreshold {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Identical != b.Identical {
+			return a.Identical
+		}
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		if a.NameMatch != b.NameMatch {
+			return a.NameMatch
+		}
+		return a.Path < b.Path
+	})
+	return candidates
+}
+
+// describe summarizes why a file is a candidate
+func (c fixCandidate) describe() string {
+	var reasons []string
+	if c.Identical {
+		reasons = append(reasons, "identical content")
+	} else if c.Similarity > 0 {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% similar", c.Similarity*100))
+	}
+	if c.NameMatch {
+		reasons = append(reasons, "same name")
+	}
+	return "(" + strings.Join(reasons, ", ") + ")"
+}
+
+// autoFixTarget picks the file an orphaned backup can be moved to without
+// asking: the only file with identical content, else the only candidate,
+// else a clearly most similar one. Returns "" when it is ambiguous.
+func autoFixTarget(candidates []fixCandidate) string {
+	switch {
+	case len(candidates) == 0:
+		return ""
+	case candidates[0].Identical:
+		if len(candidates) > 1 && candidates[1].Identical {
+			return ""
+		}
+		return candidates[0].Path
+	case len(candidates) == 1:
+		return candidates[0].Path
+	case candidates[0].Similarity >= fixSimilarityThreshold &&
+		candidates[0].Similarity-candidates[1].Similarity >= 0.1:
+		return candidates[0].Path
+	}
+	return ""
+}
+
+// newestFixCandidate returns the most recently modified candidate, which
+// pt fix --newest takes when autoFixTarget finds no clear match
+func newestFixCandidate(candidates []fixCandidate) string {
+	newest := ""
+	var newestTime time.Time
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate.Path)
+		if err == nil && (newest == "" || info.ModTime().After(newestTime)) {
+			newest, newestTime = candidate.Path, info.ModTime()
+		}
+	}
+	return newest
 }
 
-func autoFixOrphanedBackups(orphaned []OrphanedBackup, ptRoot, ptParent string) error {
+// autoFixOrphanedBackups moves each orphaned backup directory to the file
+// autoFixTarget picks for it. Ambiguous ones are skipped and listed, or
+// given to the newest candidate when pickNewest is set (pt fix --newest).
+func autoFixOrphanedBackups(orphaned []OrphanedBackup, ptRoot, ptParent string, pickNewest bool) error {
 	fixed := 0
 	skipped := 0
+	ambiguous := 0
 	
 	for _, orphan := range orphaned {
-		if len(orphan.ActualFiles) == 1 {
-			// Only one match, auto-fix
-			newPath := orphan.ActualFiles[0]
+		newPath := autoFixTarget(orphan.Candidates)
+		if newPath == "" && len(orphan.Candidates) > 1 {
+			name := filepath.Base(orphan.ExpectedPath)
+			if !pickNewest {
+				fmt.Printf("%s⚠️  Skipped %s: %d possible matches, none clearly best%s\n", ColorYellow,
+					name, len(orphan.Candidates), ColorReset)
+				skipped++
+				ambiguous++
+				continue
+			}
+			newPath = newestFixCandidate(orphan.Candidates)
+			if newPath != "" {
+				relPath, _ := filepath.Rel(ptParent, newPath)
+				fmt.Printf("%s⚠️  %s has %d possible matches, taking the newest: %s%s\n", ColorYellow,
+					name, len(orphan.Candidates), relPath, ColorReset)
+			}
+		}
+		if newPath != "" {
 			newBackupDir, err := getBackupDir(ptRoot, newPath)
 			if err != nil {
 				skipped++
@@ -2349,6 +5308,9 @@ func autoFixOrphanedBackups(orphaned []OrphanedBackup, ptRoot, ptParent string)
 	}
 	
 	fmt.Printf("\n📊 Result: %d fixed, %d skipped\n", fixed, skipped)
+	if ambiguous > 0 {
+		fmt.Printf("%s   💡 pt fix --yes --newest reattaches ambiguous ones to their newest match%s\n", ColorGray, ColorReset)
+	}
 	return nil
 }
 
@@ -2358,12 +5320,13 @@ func manualFixOrphanedBackups(orphaned []OrphanedBackup, ptRoot, ptParent string
 	return nil
 }
 
-func cleanOrphanedBackups(orphaned []OrphanedBackup) error {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\n⚠️  This will DELETE %d backup directories. Continue? (yes/no): ", len(orphaned))
-	input, _ := reader.ReadString('\n')
-	
-	if strings.TrimSpace(strings.ToLower(input)) != "yes" {
+func cleanOrphanedBackups(orphaned []OrphanedBackup, assumeYes bool) error {
+	prompt := fmt.Sprintf("\n⚠️  This will DELETE %d backup directories. Continue? (yes/no): ", len(orphaned))
+	confirmed, err := confirmAction(prompt, assumeYes, "yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
 		fmt.Println("❌ Cancelled")
 		return nil
 	}
@@ -2388,7 +5351,7 @@ func cleanOrphanedBackups(orphaned []OrphanedBackup) error {
 // MOVE COMMAND - Move file(s) and adjust all backups
 // ============================================================================
 
-func handleMoveCommand(args []string) error {
+func handleMoveCommand(ctx context.Context, args []string) error {
 	if len(args) < 2 {
 		return fmt.Errorf("move requires at least source and destination: pt move <source...> <destination>")
 	}
@@ -2396,6 +5359,9 @@ func handleMoveCommand(args []string) error {
 	comment := ""
 	patterns := []string{}
 	recursive := false
+	dryRun := false
+	merge := false
+	preserve := false
 	
 	// Parse arguments - last non-flag arg is destination
 	i := 0
@@ -2414,6 +5380,21 @@ func handleMoveCommand(args []string) error {
 			i++
 			continue
 		}
+		if args[i] == "--dry-run" {
+			dryRun = true
+			i++
+			continue
+		}
+		if args[i] == "--merge" {
+			merge = true
+			i++
+			continue
+		}
+		if args[i] == "--preserve-structure" {
+			preserve = true
+			i++
+			continue
+		}
 		patterns = append(patterns, args[i])
 		i++
 	}
@@ -2430,7 +5411,7 @@ func handleMoveCommand(args []string) error {
 	if len(sourcePatterns) == 1 && !strings.Contains(sourcePatterns[0], "*") && !strings.HasPrefix(sourcePatterns[0], "regex:") && !strings.HasPrefix(sourcePatterns[0], "r:") {
 		if info, err := os.Stat(sourcePatterns[0]); err == nil && info.IsDir() {
 			if recursive {
-				return moveDirectoryWithBackups(sourcePatterns[0], destPath, comment)
+				return moveDirectoryWithBackups(ctx, sourcePatterns[0], destPath, comment, dryRun)
 			} else {
 				return fmt.Errorf("use -r flag to move directories: pt move -r %s %s", sourcePatterns[0], destPath)
 			}
@@ -2488,27 +5469,98 @@ func handleMoveCommand(args []string) error {
 			if len(sourceFiles) > 1 {
 				return fmt.Errorf("destination must be a directory when moving multiple files")
 			}
-			// Single file to existing file - not allowed
-			return fmt.Errorf("destination already exists: %s", destResolved)
+			// Single file to existing file - only allowed when merging
+			if !merge {
+				return fmt.Errorf("destination already exists: %s (use --merge to combine histories)", destResolved)
+			}
+		} else {
+			destIsDir = true
 		}
-		destIsDir = true
 	} else {
 		// Destination doesn't exist
 		if len(sourceFiles) > 1 {
 			// Multiple files - destination must be a directory, create it
-			if err := os.MkdirAll(destResolved, 0755); err != nil {
-				return fmt.Errorf("failed to create destination directory: %w", err)
+			if dryRun {
+				fmt.Printf("📁 Would create destination directory: %s\n", destResolved)
+			} else {
+				if err := os.MkdirAll(destResolved, 0755); err != nil {
+					return fmt.Errorf("failed to create destination directory: %w", err)
+				}
+				fmt.Printf("📁 Created destination directory: %s\n", destResolved)
 			}
 			destIsDir = true
-			fmt.Printf("📁 Created destination directory: %s\n", destResolved)
 		}
 		// Single file - destination will be the new filename
 	}
 
+	// Where each source lands inside a destination directory: flattened
+	// to its basename, or under its path below the pattern's literal prefix
+	var bases []string
+	if preserve {
+		for _, pattern := range sourcePatterns {
+			bases = append(bases, globBase(pattern))
+		}
+	}
+	destFor := func(sourceResolved string) string {
+		if !destIsDir {
+			return destResolved
+		}
+		if preserve {
+			return filepath.Join(destResolved, relToGlobBase(sourceResolved, bases))
+		}
+		return filepath.Join(destResolved, filepath.Base(sourceResolved))
+	}
+
+	// Refuse up front when several sources would land on the same path,
+	// rather than moving the first and failing the rest one by one
+	if destIsDir && len(sourceFiles) > 1 {
+		targets := make(map[string][]string)
+		var order []string
+		for _, sourcePath := range sourceFiles {
+			absSource, err := filepath.Abs(sourcePath)
+			if err != nil {
+				continue
+			}
+			target := destFor(absSource)
+			if _, ok := targets[target]; !ok {
+				order = append(order, target)
+			}
+			targets[target] = append(targets[target], sourcePath)
+		}
+
+		collisions := 0
+		for _, target := range order {
+			if len(targets[target]) < 2 {
+				continue
+			}
+			if collisions == 0 {
+				fmt.Printf("\n%s❌ Several sources would be moved to the same destination:%s\n", ColorRed, ColorReset)
+			}
+			collisions++
+			fmt.Printf("  %s%s%s\n", ColorYellow, target, ColorReset)
+			for _, source := range targets[target] {
+				fmt.Printf("    ← %s\n", source)
+			}
+		}
+		if collisions > 0 {
+			hint := "rename the clashing files first"
+			if !preserve {
+				hint = "use --preserve-structure to keep each file's directory under the destination"
+			}
+			return withHint(fmt.Errorf("%d destination collision(s), nothing was moved", collisions), hint)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("\n%s🔍 Dry run: nothing will be moved%s\n", ColorYellow, ColorReset)
+	}
 	fmt.Printf("\n🚚 Moving %d file(s) with backup adjustment...\n", len(sourceFiles))
 	fmt.Printf("  Destination: %s\n", destResolved)
 	if destIsDir {
 		fmt.Printf("  Type: Directory\n")
+		if preserve {
+			fmt.Printf("  Layout: preserving source directories\n")
+		}
 	}
 	fmt.Println()
 
@@ -2518,7 +5570,12 @@ func handleMoveCommand(args []string) error {
 	movedBackups := 0
 
 	// Process each source file
+	interrupted := false
 	for idx, sourcePath := range sourceFiles {
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
 		fileNum := idx + 1
 		fmt.Printf("[%d/%d] Processing: %s\n", fileNum, len(sourceFiles), sourcePath)
 
@@ -2545,18 +5602,22 @@ func handleMoveCommand(args []string) error {
 		}
 
 		// Determine final destination path
-		var finalDestPath string
-		if destIsDir {
-			finalDestPath = filepath.Join(destResolved, filepath.Base(sourceResolved))
-		} else {
-			finalDestPath = destResolved
-		}
+		finalDestPath := destFor(sourceResolved)
 
 		// Check if destination already exists
-		if _, err := os.Stat(finalDestPath); err == nil {
-			fmt.Printf("  %s❌ Destination exists: %s%s\n", ColorRed, finalDestPath, ColorReset)
-			failCount++
-			continue
+		destExists := false
+		if destInfo, err := os.Stat(finalDestPath); err == nil {
+			if !merge || destInfo.IsDir() {
+				fmt.Printf("  %s❌ Destination exists: %s%s\n", ColorRed, finalDestPath, ColorReset)
+				failCount++
+				continue
+			}
+			if finalDestPath == sourceResolved {
+				fmt.Printf("  %s❌ Source and destination are the same file%s\n", ColorRed, ColorReset)
+				failCount++
+				continue
+			}
+			destExists = true
 		}
 
 		// Validate destination path
@@ -2588,6 +5649,27 @@ func handleMoveCommand(args []string) error {
 			}
 		}
 
+		if dryRun {
+			if destExists {
+				fmt.Printf("  📦 Would back up existing destination before overwriting\n")
+			}
+			if hasBackups {
+				if destPTDir, err := predictPTDir(finalDestPath); err == nil {
+					if destBackupDir, err := getBackupDir(destPTDir, finalDestPath); err == nil {
+						if destExists {
+							fmt.Printf("  📦 Would merge backups into: %s\n", destBackupDir)
+						} else {
+							fmt.Printf("  📦 Would move backups to: %s\n", destBackupDir)
+						}
+						movedBackups += countBackupsIn(sourceBackupDir)
+					}
+				}
+			}
+			fmt.Printf("  %s➜ Would move to: %s%s\n", ColorCyan, finalDestPath, ColorReset)
+			successCount++
+			continue
+		}
+
 		// Ensure destination parent directory exists
 		destDir := filepath.Dir(finalDestPath)
 		if err := os.MkdirAll(destDir, 0755); err != nil {
@@ -2612,6 +5694,20 @@ func handleMoveCommand(args []string) error {
 			continue
 		}
 
+		// Merging onto an existing file: snapshot it, then (once the file has
+		// moved) fold the source history into the destination's instead of
+		// replacing the directory
+		mergeHistory := false
+		if destExists {
+			if _, err := autoRenameIfExists(finalDestPath, "merge: before merging "+filepath.Base(sourceResolved), false); err != nil {
+				fmt.Printf("  %s❌ Cannot back up destination: %v%s\n", ColorRed, err, ColorReset)
+				failCount++
+				continue
+			}
+			mergeHistory = hasBackups
+			hasBackups = false // the history is merged below, not moved
+		}
+
 		// Move backups first (if they exist)
 		if hasBackups {
 			// Ensure destination backup parent directory exists
@@ -2619,7 +5715,7 @@ func handleMoveCommand(args []string) error {
 				fmt.Printf("  %s⚠️  Cannot create backup parent: %v%s\n", ColorYellow, err, ColorReset)
 			} else {
 				// Move the entire backup directory
-				err = os.Rename(sourceBackupDir, destBackupDir)
+				err = backupStore.Move(sourceBackupDir, destBackupDir)
 				if err != nil {
 					fmt.Printf("  %s⚠️  Failed to move backups: %v%s\n", ColorYellow, err, ColorReset)
 				} else {
@@ -2661,17 +5757,32 @@ func handleMoveCommand(args []string) error {
 		}
 
 		// Move the actual file
-		err = os.Rename(sourceResolved, finalDestPath)
+		err = moveFile(sourceResolved, finalDestPath)
 		if err != nil {
 			// If move fails, try to restore backups
 			if hasBackups {
-				os.Rename(destBackupDir, sourceBackupDir)
+				backupStore.Move(destBackupDir, sourceBackupDir)
 			}
 			fmt.Printf("  %s❌ Failed to move file: %v%s\n", ColorRed, err, ColorReset)
 			failCount++
 			continue
 		}
 
+		// Only now that the file is at the destination does its history follow
+		if mergeHistory {
+			merged, err := mergeBackupHistory(sourceBackupDir, destBackupDir, sourceResolved, finalDestPath)
+			if err != nil {
+				fmt.Printf("  %s⚠️  Failed to merge backups: %v%s\n", ColorYellow, err, ColorReset)
+			} else {
+				fmt.Printf("  ✅ Merged %d backup(s) into destination history\n", merged)
+			}
+			movedBackups += merged
+		}
+
+		if info, err := os.Stat(finalDestPath); err == nil {
+			auditLog("move", sourceResolved, info.Size(), "to", finalDestPath)
+		}
+
 		// Create backup of the move operation if comment provided
 		if comment != "" {
 			_, err = autoRenameIfExists(finalDestPath, "move: "+comment, false)
@@ -2692,7 +5803,9 @@ func handleMoveCommand(args []string) error {
 			displayPath = finalDestPath
 		}
 		
-		if srcName == destName {
+		if destExists {
+			fmt.Printf("  %s✅ Merged into: %s%s\n", ColorGreen, displayPath, ColorReset)
+		} else if srcName == destName {
 			// Same filename, different directory
 			fmt.Printf("  %s✅ Moved to: %s%s\n", ColorGreen, displayPath, ColorReset)
 		} else {
@@ -2705,27 +5818,144 @@ func handleMoveCommand(args []string) error {
 	// Summary
 	fmt.Println()
 	fmt.Printf("%s📊 Move Summary:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %s✅ %d file(s) moved successfully%s\n", ColorGreen, successCount, ColorReset)
+	if dryRun {
+		fmt.Printf("  %s🔍 %d file(s) would be moved (dry run)%s\n", ColorCyan, successCount, ColorReset)
+	} else {
+		fmt.Printf("  %s✅ %d file(s) moved successfully%s\n", ColorGreen, successCount, ColorReset)
+	}
 	if failCount > 0 {
 		fmt.Printf("  %s❌ %d file(s) failed%s\n", ColorRed, failCount, ColorReset)
 	}
-	if movedBackups > 0 {
+	if movedBackups > 0 && dryRun {
+		fmt.Printf("  📦 %d backup(s) would be adjusted\n", movedBackups)
+	} else if movedBackups > 0 {
 		fmt.Printf("  📦 %d backup(s) adjusted\n", movedBackups)
 	}
 	if comment != "" {
 		fmt.Printf("  💬 Comment: \"%s\"\n", comment)
 	}
 
-	if failCount > 0 {
+	if interrupted {
+		return fmt.Errorf("move stopped after %d of %d file(s): %w", successCount+failCount, len(sourceFiles), errInterrupted)
+	}
+	if failCount > 0 && dryRun {
+		return fmt.Errorf("%d file(s) would fail to move", failCount)
+	} else if failCount > 0 {
 		return fmt.Errorf("%d file(s) failed to move", failCount)
 	}
 
 	return nil
 }
 
+// globBase returns the absolute directory a move pattern is rooted at: the
+// directory part before the first wildcard, or the current directory for
+// regex patterns
+func globBase(pattern string) string {
+	base := "."
+	if !strings.HasPrefix(pattern, "regex:") && !strings.HasPrefix(pattern, "r:") {
+		if i := strings.IndexAny(pattern, "*?["); i >= 0 {
+			pattern = pattern[:i]
+		}
+		base = filepath.Dir(pattern)
+		if strings.HasSuffix(pattern, "/") || strings.HasSuffix(pattern, string(filepath.Separator)) {
+			base = filepath.Clean(pattern)
+		}
+	}
+	abs, err := filepath.Abs(base)
+	if err != nil {
+		return base
+	}
+	return abs
+}
+
+// relToGlobBase returns path relative to the deepest of bases containing
+// it, falling back to the current directory and then to the basename
+func relToGlobBase(path string, bases []string) string {
+	best := ""
+	for _, base := range bases {
+		rel, err := filepath.Rel(base, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(base) > len(best) {
+			best = base
+		}
+	}
+	if best == "" {
+		best, _ = os.Getwd()
+	}
+	if rel, err := filepath.Rel(best, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return filepath.Base(path)
+}
+
+// mergeBackupHistory moves every backup of srcFile into destBackupDir as a
+// backup of destFile. Backups are renamed to the destination's name prefix
+// so listBackups finds them, keep their modification time (which orders the
+// history), and get a suffix if the name is already taken.
+func mergeBackupHistory(srcBackupDir, destBackupDir, srcFile, destFile string) (int, error) {
+	if err := os.MkdirAll(destBackupDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(srcBackupDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	prefixOf := func(path string) string {
+		base := filepath.Base(path)
+		ext := filepath.Ext(base)
+		return fmt.Sprintf("%s_%s.", strings.TrimSuffix(base, ext), strings.TrimPrefix(ext, "."))
+	}
+	srcPrefix, destPrefix := prefixOf(srcFile), prefixOf(destFile)
+
+	merged := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".meta.json") || name == backupDirConfigFile {
+			continue
+		}
+
+		newName := destPrefix + strings.TrimPrefix(name, srcPrefix)
+		target := filepath.Join(destBackupDir, newName)
+		for n := 1; ; n++ {
+			if _, err := os.Stat(target); os.IsNotExist(err) {
+				break
+			}
+			target = filepath.Join(destBackupDir, fmt.Sprintf("%s_m%d", newName, n))
+		}
+
+		srcPath := filepath.Join(srcBackupDir, name)
+		if err := moveFile(srcPath, target); err != nil {
+			return merged, fmt.Errorf("failed to move backup %s: %w", name, err)
+		}
+
+		metadata, err := readBackupMetadata(srcPath)
+		if err != nil || metadata == nil {
+			logger.Printf("Warning: no usable metadata for merged backup %s: %v", name, err)
+		} else {
+			metadata.Original = destFile
+			if data, err := json.MarshalIndent(metadata, "", "  "); err == nil {
+				if err := os.WriteFile(target+".meta.json", data, 0644); err == nil {
+					os.Remove(srcPath + ".meta.json")
+				}
+			}
+		}
+		merged++
+	}
+
+	// Drop the now empty source history (fails harmlessly if anything is left)
+	os.Remove(srcBackupDir)
 
-// moveDirectoryWithBackups moves entire directory and adjusts all backups
-func moveDirectoryWithBackups(sourceDir, destDir string, comment string) error {
+	logger.Printf("Merged %d backup(s) of %s into %s", merged, srcFile, destBackupDir)
+	return merged, nil
+}
+
+// moveDirectoryWithBackups moves entire directory and adjusts all backups.
+// With dryRun it only reports what would be moved.
+func moveDirectoryWithBackups(ctx context.Context, sourceDir, destDir string, comment string, dryRun bool) error {
 	// Resolve source directory
 	sourceResolved, err := filepath.Abs(sourceDir)
 	if err != nil {
@@ -2752,6 +5982,9 @@ func moveDirectoryWithBackups(sourceDir, destDir string, comment string) error {
 		return fmt.Errorf("destination already exists: %s", destResolved)
 	}
 	
+	if dryRun {
+		fmt.Printf("\n%s🔍 Dry run: nothing will be moved%s\n", ColorYellow, ColorReset)
+	}
 	fmt.Printf("\n🚚 Moving directory with backup adjustment...\n")
 	fmt.Printf("  Source: %s\n", sourceResolved)
 	fmt.Printf("  Destination: %s\n", destResolved)
@@ -2786,8 +6019,10 @@ func moveDirectoryWithBackups(sourceDir, destDir string, comment string) error {
 	}
 	
 	// Create destination directory structure first
-	if err := os.MkdirAll(destResolved, 0755); err != nil {
-		return fmt.Errorf("failed to create destination: %w", err)
+	if !dryRun {
+		if err := os.MkdirAll(destResolved, 0755); err != nil {
+			return fmt.Errorf("failed to create destination: %w", err)
+		}
 	}
 	
 	// Track results
@@ -2796,13 +6031,32 @@ func moveDirectoryWithBackups(sourceDir, destDir string, comment string) error {
 	movedBackups := 0
 	
 	// Process each file
+	interrupted := false
 	for idx, sourcePath := range filesToMove {
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
 		fileNum := idx + 1
 		relPath, _ := filepath.Rel(sourceResolved, sourcePath)
 		fmt.Printf("[%d/%d] %s\n", fileNum, len(filesToMove), relPath)
 		
 		// Calculate destination path (preserve directory structure)
 		destPath := filepath.Join(destResolved, relPath)
+
+		if dryRun {
+			if sourcePTRoot != "" {
+				if sourceBackupDir, err := getBackupDir(sourcePTRoot, sourcePath); err == nil {
+					if n := countBackupsIn(sourceBackupDir); n > 0 {
+						fmt.Printf("  📦 %d backup(s) would follow the file\n", n)
+						movedBackups += n
+					}
+				}
+			}
+			fmt.Printf("  %s➜ Would move to: %s%s\n", ColorCyan, destPath, ColorReset)
+			successCount++
+			continue
+		}
 		
 		// Ensure parent directory exists
 		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
@@ -2845,7 +6099,7 @@ func moveDirectoryWithBackups(sourceDir, destDir string, comment string) error {
 		// Move backups if they exist
 		if hasBackups {
 			if err := os.MkdirAll(filepath.Dir(destBackupDir), 0755); err == nil {
-				if err := os.Rename(sourceBackupDir, destBackupDir); err == nil {
+				if err := backupStore.Move(sourceBackupDir, destBackupDir); err == nil {
 					// Update metadata
 					entries, _ := os.ReadDir(destBackupDir)
 					for _, entry := range entries {
@@ -2867,35 +6121,147 @@ func moveDirectoryWithBackups(sourceDir, destDir string, comment string) error {
 		}
 		
 		// Move the file
-		if err := os.Rename(sourcePath, destPath); err != nil {
+		if err := moveFile(sourcePath, destPath); err != nil {
 			fmt.Printf("  %s❌ Move failed: %v%s\n", ColorRed, err, ColorReset)
 			failCount++
 			continue
 		}
 		
+		if info, err := os.Stat(destPath); err == nil {
+			auditLog("move", sourcePath, info.Size(), "to", destPath)
+		}
 		fmt.Printf("  %s✅ Moved%s\n", ColorGreen, ColorReset)
 		successCount++
 	}
 	
-	// Remove empty source directory
-	os.RemoveAll(sourceResolved)
+	// Remove empty source directory (an interrupted move still has files there)
+	if !dryRun && !interrupted {
+		os.RemoveAll(sourceResolved)
+	}
 	
 	fmt.Println()
 	fmt.Printf("%s📊 Directory Move Summary:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %s✅ %d file(s) moved%s\n", ColorGreen, successCount, ColorReset)
+	if dryRun {
+		fmt.Printf("  %s🔍 %d file(s) would be moved (dry run)%s\n", ColorCyan, successCount, ColorReset)
+	} else {
+		fmt.Printf("  %s✅ %d file(s) moved%s\n", ColorGreen, successCount, ColorReset)
+	}
 	if failCount > 0 {
 		fmt.Printf("  %s❌ %d file(s) failed%s\n", ColorRed, failCount, ColorReset)
 	}
-	if movedBackups > 0 {
+	if movedBackups > 0 && dryRun {
+		fmt.Printf("  📦 %d backup(s) would be adjusted\n", movedBackups)
+	} else if movedBackups > 0 {
 		fmt.Printf("  📦 %d backup(s) adjusted\n", movedBackups)
 	}
 	if comment != "" {
 		fmt.Printf("  💬 Comment: \"%s\"\n", comment)
 	}
 	
+	if interrupted {
+		return fmt.Errorf("move stopped after %d of %d file(s), the rest are still in %s: %w",
+			successCount+failCount, len(filesToMove), sourceResolved, errInterrupted)
+	}
+
 	return nil
 }
 
+// moveFile renames src to dst. Across filesystems, where rename fails, it
+// copies instead (keeping mode and modification times) and removes src.
+// Directories are copied recursively.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !isCrossDeviceError(err) {
+		return err
+	}
+
+	// Copy next to dst and rename over it, so an existing dst is replaced
+	// the way rename replaces it, and a failed copy only cleans up the
+	// temp copy, never something that was already at dst
+	logger.Printf("Rename across devices, copying instead: %s -> %s", src, dst)
+	tmp := filepath.Join(filepath.Dir(dst), fmt.Sprintf(".%s.pt-move-%s", filepath.Base(dst), generateShortID()))
+	if err := copyPreserving(src, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return fmt.Errorf("failed to copy across devices: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.RemoveAll(tmp)
+		return fmt.Errorf("failed to copy across devices: %w", err)
+	}
+	return os.RemoveAll(src)
+}
+
+// copyPreserving copies a file or directory tree, keeping permissions and
+// modification times
+func copyPreserving(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPreserving(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	} else {
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+
+	// The umask may have narrowed the mode at creation
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// countBackupsIn returns the number of backups (excluding .meta.json sidecars)
+// in a per-file backup directory, or 0 if it doesn't exist
+func countBackupsIn(backupDir string) int {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasSuffix(entry.Name(), ".meta.json") && entry.Name() != backupDirConfigFile {
+			count++
+		}
+	}
+	return count
+}
+
 // ============================================================================
 // BACKUP & RESTORE OPERATIONS
 // ============================================================================
@@ -2944,67 +6310,40 @@ func listBackups(filePath string) ([]BackupInfo, error) {
 
 	logger.Printf("Expected backup directory: %s", backupDir)
 
-	// Check if expected backup directory exists
-	backupDirExists := false
-	if stat, err := os.Stat(backupDir); err == nil && stat.IsDir() {
-		backupDirExists = true
-		logger.Printf("Backup directory exists: %s", backupDir)
-	} else {
-		logger.Printf("Backup directory does not exist: %s (error: %v)", backupDir, err)
-	}
+	// Pattern for backup files: filename_ext.timestamp...
+	pattern := fmt.Sprintf("%s_%s.", fileNameWithoutExt, fileExtWithoutDot)
 
-	// If expected directory doesn't exist, try fallback to base filename only
-	if !backupDirExists {
-		alternateBackupDir := filepath.Join(ptRoot, fileBaseName)
+	stored, err := backupStore.List(backupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Printf("Backup directory does not exist: %s", backupDir)
 
+		// The file may have been moved: try the base filename only
+		alternateBackupDir := filepath.Join(ptRoot, fileBaseName)
 		logger.Printf("Trying alternate backup directory (base filename only): %s", alternateBackupDir)
 
-		if stat, err := os.Stat(alternateBackupDir); err == nil && stat.IsDir() {
+		stored, err = backupStore.List(alternateBackupDir)
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Printf("No backup directory found for file")
+			return []BackupInfo{}, nil
+		}
+		if err == nil {
 			logger.Printf("Found backups using base filename: %s", alternateBackupDir)
-			fmt.Printf("%sℹ️  Note: Using backups from '%s/' (file may have been moved)%s\n",
+			fmt.Fprintf(infoOut, "%sℹ️  Note: Using backups from '%s/' (file may have been moved)%s\n",
 				ColorYellow, fileBaseName, ColorReset)
 			backupDir = alternateBackupDir
-			backupDirExists = true
-		} else {
-			logger.Printf("Alternate backup directory also not found: %s (error: %v)", alternateBackupDir, err)
 		}
 	}
-
-	// If still no backup directory found, return empty
-	if !backupDirExists {
-		logger.Printf("No backup directory found for file")
-		return []BackupInfo{}, nil
-	}
-
-	// Pattern for backup files: filename_ext.timestamp...
-	pattern := fmt.Sprintf("%s_%s.", fileNameWithoutExt, fileExtWithoutDot)
-
-	logger.Printf("Looking for backup files with pattern: %s", pattern)
-
-	entries, err := os.ReadDir(backupDir)
 	if err != nil {
 		logger.Printf("Failed to read backup directory: %v", err)
 		return nil, fmt.Errorf("failed to read backup directory: %w", err)
 	}
 
-	logger.Printf("Found %d entries in backup directory", len(entries))
-
-	backups := make([]BackupInfo, 0)
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			logger.Printf("Skipping directory: %s", entry.Name())
-			continue
-		}
-
-		name := entry.Name()
+	logger.Printf("Found %d backup(s) in %s, filtering with pattern: %s", len(stored), backupDir, pattern)
 
-		if strings.HasSuffix(name, ".meta.json") {
-			logger.Printf("Skipping metadata file: %s", name)
-			continue
-		}
+	backups := make([]BackupInfo, 0, len(stored))
 
-		logger.Printf("Checking file: %s against pattern: %s", name, pattern)
+	for _, backup := range stored {
+		name := backup.Name
 
 		if !strings.HasPrefix(name, pattern) {
 			logger.Printf("Skipping (doesn't match pattern '%s'): %s", pattern, name)
@@ -3013,8 +6352,6 @@ func listBackups(filePath string) ([]BackupInfo, error) {
 
 		timestamp := strings.TrimPrefix(name, pattern)
 
-		logger.Printf("Extracted timestamp: %s (length: %d)", timestamp, len(timestamp))
-
 		if len(timestamp) < 20 {
 			logger.Printf("Skipping (timestamp too short): %s", name)
 			continue
@@ -3037,26 +6374,8 @@ func listBackups(filePath string) ([]BackupInfo, error) {
 			continue
 		}
 
-		info, err := entry.Info()
-		if err != nil {
-			logger.Printf("Warning: failed to get info for %s: %v", name, err)
-			continue
-		}
-
-		backupPath := filepath.Join(backupDir, name)
-		comment, err := loadBackupMetadata(backupPath)
-		if err != nil && !os.IsNotExist(err) {
-			logger.Printf("Warning: failed to load metadata for %s: %v", name, err)
-		}
-
-		logger.Printf("Found valid backup: %s (comment: %s)", name, comment)
-		backups = append(backups, BackupInfo{
-			Path:    backupPath,
-			Name:    name,
-			ModTime: info.ModTime(),
-			Size:    info.Size(),
-			Comment: comment,
-		})
+		logger.Printf("Found valid backup: %s (comment: %s)", name, backup.Comment)
+		backups = append(backups, backup)
 	}
 
 	if len(backups) == 0 {
@@ -3068,21 +6387,157 @@ func listBackups(filePath string) ([]BackupInfo, error) {
 		return backups[i].ModTime.After(backups[j].ModTime)
 	})
 
-	if len(backups) > appConfig.MaxBackupCount {
-		backups = backups[:appConfig.MaxBackupCount]
+	// A per-file --keep override takes precedence over max_backup_count
+	maxCount := maxBackupsFor(backupDir)
+	if len(backups) > maxCount {
+		// Pinned backups past the cap are kept
+		kept := make([]BackupInfo, maxCount, len(backups))
+		copy(kept, backups)
+		for _, backup := range backups[maxCount:] {
+			if backup.Pinned {
+				kept = append(kept, backup)
+			}
+		}
+		backups = kept
 	}
 
 	logger.Printf("Returning %d backup(s)", len(backups))
 	return backups, nil
 }
 
-func printBackupTable(filePath string, backups []BackupInfo) {
+// backupTableColumns lays out the backup list for a terminal termWidth
+// columns wide. Modified and Size keep their natural width and the rest is
+// split between File Name and Comment; on narrow terminals Comment and then
+// Size are dropped so rows never wrap.
+func backupTableColumns(termWidth int, withPreview bool) []tableColumn {
 	const (
-		col1Width = 40  // More width for filename
-		col2Width = 19
-		col3Width = 12
-		col4Width = 30  // Smaller for comments
+		sizeWidth       = 12
+		minNameWidth    = 18
+		minCommentWidth = 12
+		minPreviewWidth = 16
 	)
+	dateWidth := timeColumnWidth()
+
+	name := tableColumn{header: "File Name"}
+	date := tableColumn{header: "Modified", width: dateWidth}
+	size := tableColumn{header: "Size", width: sizeWidth, right: true}
+	comment := tableColumn{header: "Comment"}
+
+	// --preview adds a content column when there is room for it
+	if withPreview {
+		preview := tableColumn{header: "Preview"}
+		free := termWidth - tableOverhead(5) - dateWidth - sizeWidth
+		if free >= minNameWidth+minCommentWidth+minPreviewWidth {
+			preview.width = min(free*2/5, backupSnippetLen)
+			name.width = (free - preview.width) * 3 / 5
+			comment.width = free - preview.width - name.width
+			return []tableColumn{name, date, size, comment, preview}
+		}
+
+		// Narrower: the preview takes the comment's place
+		free = termWidth - tableOverhead(4) - dateWidth - sizeWidth
+		if free >= minNameWidth+minPreviewWidth {
+			preview.width = min(free/2, backupSnippetLen)
+			name.width = free - preview.width
+			return []tableColumn{name, date, size, preview}
+		}
+	}
+
+	free := termWidth - tableOverhead(4) - dateWidth - sizeWidth
+	if free >= minNameWidth+minCommentWidth {
+		name.width = free * 3 / 5
+		comment.width = free - name.width
+		return []tableColumn{name, date, size, comment}
+	}
+
+	free = termWidth - tableOverhead(3) - dateWidth - sizeWidth
+	if free >= minNameWidth {
+		name.width = free
+		return []tableColumn{name, date, size}
+	}
+
+	name.width = max(termWidth-tableOverhead(2)-dateWidth, 10)
+	return []tableColumn{name, date}
+}
+
+// backupShortID is the random suffix generateUniqueBackupName puts at the
+// end of a backup name, which identifies it like a short commit hash
+func backupShortID(name string) string {
+	if i := strings.LastIndex(name, "_"); i >= 0 && i+1 < len(name) {
+		return name[i+1:]
+	}
+	return name
+}
+
+// printBackupOneline lists backups one per line (pt -l <file> --oneline):
+// number, short id, age, size and the comment cut to fit the terminal
+func printBackupOneline(backups []BackupInfo) {
+	width := getTerminalWidth()
+	numWidth := len(strconv.Itoa(len(backups)))
+
+	for i, backup := range backups {
+		id := backupShortID(backup.Name)
+		age := formatAge(backup.ModTime)
+		size := formatSize(backup.Size)
+		prefix := fmt.Sprintf("%*d %s %-9s %8s ", numWidth, i+1, id, age, size)
+
+		comment := backup.Comment
+		if backup.Pinned {
+			comment = "📌 " + comment
+		}
+		if room := width - len(prefix) - 1; room > 3 && len([]rune(comment)) > room {
+			comment = string([]rune(comment)[:room-3]) + "..."
+		}
+
+		fmt.Printf("%s%*d%s %s%s%s %s%-9s%s %8s %s\n",
+			ColorGray, numWidth, i+1, ColorReset,
+			ColorYellow, id, ColorReset,
+			ColorCyan, age, ColorReset,
+			size, comment)
+	}
+}
+
+// backupSnippetLen is how much of a backup's content pt -l --preview shows,
+// and backupSnippetRead how much of it is looked at to find that
+const (
+	backupSnippetLen  = 40
+	backupSnippetRead = 4096
+)
+
+// backupSnippet returns the start of a backup's content with whitespace
+// collapsed, for pt -l --preview. Only the first few KB are looked at, so
+// a binary check or whitespace collapse never walks a whole large backup.
+func backupSnippet(backupPath string) string {
+	data, err := backupStore.Read(backupPath)
+	if err != nil {
+		return "(unreadable)"
+	}
+	if len(data) > backupSnippetRead {
+		data = data[:backupSnippetRead]
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "(binary)"
+	}
+
+	// The read limit may cut a character in half
+	snippet := strings.Join(strings.Fields(strings.ToValidUTF8(string(data), "")), " ")
+	if snippet == "" {
+		return "(empty)"
+	}
+	if runes := []rune(snippet); len(runes) > backupSnippetLen {
+		snippet = string(runes[:backupSnippetLen-3]) + "..."
+	}
+	return snippet
+}
+
+func printBackupTable(filePath string, backups []BackupInfo) {
+	printBackupTableWithPreview(filePath, backups, false)
+}
+
+// printBackupTableWithPreview prints the backup table, with a column showing
+// the start of each backup's content when withPreview is set
+func printBackupTableWithPreview(filePath string, backups []BackupInfo, withPreview bool) {
+	cols := backupTableColumns(getTerminalWidth(), withPreview)
 
 	// Find .pt root to show in message
 	dir := filepath.Dir(filePath)
@@ -3100,72 +6555,111 @@ func printBackupTable(filePath string, backups []BackupInfo) {
 	fmt.Printf("%sTotal: %d backup(s) (stored in %s/)%s\n\n",
 		ColorGray, len(backups), ptLocation, ColorReset)
 
-	fmt.Printf("%s┌%s┬%s┬%s┬%s┐%s\n",
-		ColorGray,
-		strings.Repeat("─", col1Width+2),
-		strings.Repeat("─", col2Width+2),
-		strings.Repeat("─", col3Width+2),
-		strings.Repeat("─", col4Width+2),
-		ColorReset)
+	fmt.Println(tableRule("┌", "┬", "┐", cols))
+	fmt.Println(tableHeader(cols))
+	fmt.Println(tableRule("├", "┼", "┤", cols))
 
-	fmt.Printf("%s│%s %s%s%-*s%s %s│%s %s%s%-*s%s %s│%s %s%s%*s%s %s│%s %s%s%-*s%s %s│%s\n",
-		ColorGray, ColorReset,
-		ColorBold, ColorYellow, col1Width, "File Name", ColorReset,
-		ColorGray, ColorReset,
-		ColorBold, ColorYellow, col2Width, "Modified", ColorReset,
-		ColorGray, ColorReset,
-		ColorBold, ColorYellow, col3Width, "Size", ColorReset,
-		ColorGray, ColorReset,
-		ColorBold, ColorYellow, col4Width, "Comment", ColorReset,
-		ColorGray, ColorReset)
+	for i, backup := range backups {
+		fmt.Println(tableRow(cols, backupTableCells(cols, i+1, backup)))
+	}
 
-	fmt.Printf("%s├%s┼%s┼%s┼%s┤%s\n",
-		ColorGray,
-		strings.Repeat("─", col1Width+2),
-		strings.Repeat("─", col2Width+2),
-		strings.Repeat("─", col3Width+2),
-		strings.Repeat("─", col4Width+2),
-		ColorReset)
+	fmt.Println(tableRule("└", "┴", "┘", cols))
+	fmt.Println()
+}
 
-	for i, backup := range backups {
-		name := backup.Name
-		numWidth := len(fmt.Sprintf("%3d. ", i+1))
-		maxNameLen := col1Width - numWidth
-		if len(name) > maxNameLen {
-			name = name[:maxNameLen-3] + "..."
+// backupTableCells fills in row number n of the backup table, cut to fit cols
+func backupTableCells(cols []tableColumn, n int, backup BackupInfo) []string {
+	number := fmt.Sprintf("%3d. ", n)
+	name := backup.Name
+	if backup.Pinned {
+		name = "📌 " + name
+	}
+	name = number + truncateCell(name, cols[0].width-len(number))
+
+	comment := backup.Comment
+	if comment == "" {
+		comment = "-"
+	}
+
+	cells := []string{
+		name,
+		formatTime(backup.ModTime),
+		formatSize(backup.Size),
+		comment,
+	}
+	if cols[len(cols)-1].header == "Preview" {
+		cells = append(cells[:len(cols)-1], backupSnippet(backup.Path))
+	}
+	for j, col := range cols {
+		cells[j] = truncateCell(cells[j], col.width)
+	}
+	return cells
+}
+
+// previewRestore shows what restoring backup would change in filePath and
+// asks for confirmation. It reports false if the restore should not go ahead:
+// the user declined, or filePath already matches the backup, which is said
+// as such rather than as a cancelled restore.
+func previewRestore(backup BackupInfo, filePath string, toolFlag string, assumeYes bool) (bool, error) {
+	if current, err := os.ReadFile(filePath); err == nil {
+		content, err := readBackupForRestore(backup.Path)
+		if err != nil {
+			return false, err
 		}
+		if bytes.Equal(current, content) {
+			fmt.Printf("%s✅ %s is already identical to %s, nothing to restore%s\n", ColorGreen, filePath, backup.Name, ColorReset)
+			return false, nil
+		}
+	}
 
-		modTime := backup.ModTime.Format("2006-01-02 15:04:05")
-		sizeStr := formatSize(backup.Size)
+	fmt.Printf("\n%s📊 Changes restoring %s would make:%s\n\n", ColorCyan, backup.Name, ColorReset)
 
-		comment := backup.Comment
-		if comment == "" {
-			comment = "-"
-		} else {
-			if len(comment) > col4Width {
-				comment = comment[:col4Width-3] + "..."
-			}
+	toolName := resolveDiffTool(toolFlag)
+	var err error
+	if toolName != "pdiff" && toolName != "pdiff2" {
+		if err = runDiff(toolName, filePath, backup.Path, false); err != nil {
+			fmt.Printf("%s⚠️  %v, using the built-in diff%s\n", ColorYellow, err, ColorReset)
+		}
+	}
+	if toolName == "pdiff" || toolName == "pdiff2" || err != nil {
+		pdiff := &PDiff2{Context: contextOrDefault(3), NoHighlight: diffNoHighlight}
+		diff, err := pdiff.DiffFiles(filePath, backup.Path)
+		if err != nil {
+			return false, fmt.Errorf("diff failed: %w", err)
 		}
+		pdiff.PrintDiff(diff)
+	}
 
-		fmt.Printf("%s│%s %3d. %-*s %s│%s %-*s %s│%s %*s %s│%s %-*s %s│%s\n",
-			ColorGray, ColorReset,
-			i+1, maxNameLen, name,
-			ColorGray, ColorReset,
-			col2Width, modTime,
-			ColorGray, ColorReset,
-			col3Width, sizeStr,
-			ColorGray, ColorReset,
-			col4Width, comment,
-			ColorGray, ColorReset)
+	fmt.Println()
+	confirmed, err := confirmAction("Restore this backup? (y/N): ", assumeYes, "y", "yes")
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		fmt.Println("❌ Restore cancelled")
+	}
+	return confirmed, nil
+}
+
+// readBackupForRestore reads a backup's content, refusing one too large to
+// restore before any of it is read
+func readBackupForRestore(backupPath string) ([]byte, error) {
+	backup, err := backupStore.Stat(backupPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("backup file not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to read backup file: %w", err)
+	}
+	if backup.Size > int64(appConfig.MaxClipboardSize) {
+		return nil, fmt.Errorf("backup file too large to restore (max %dMB)", appConfig.MaxClipboardSize/(1024*1024))
 	}
 
-	fmt.Printf("%s└%s┴%s┴%s┴%s┘%s\n\n",
-		ColorGray,
-		strings.Repeat("─", col1Width+2),
-		strings.Repeat("─", col2Width+2),
-		strings.Repeat("─", col3Width+2),
-		strings.Repeat("─", col4Width+2),
-		ColorReset)
+	content, err := backupStore.Read(backupPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup file: %w", err)
+	}
+	return content, nil
 }
 
 // Add the missing comment parameter
@@ -3180,18 +6674,9 @@ func restoreBackup(backupPath, originalPath, comment string) error {
 		fileExists = true
 	}
 
-	info, err := os.Stat(backupPath)
-	if err != nil {
-		return fmt.Errorf("backup file not found: %w", err)
-	}
-
-	if info.Size() > int64(appConfig.MaxClipboardSize) {
-		return fmt.Errorf("backup file too large to restore (max %dMB)", appConfig.MaxClipboardSize/(1024*1024))
-	}
-
-	content, err := os.ReadFile(backupPath)
+	content, err := readBackupForRestore(backupPath)
 	if err != nil {
-		return fmt.Errorf("failed to read backup file: %w", err)
+		return err
 	}
 
 	// if _, err := os.Stat(originalPath); err == nil {
@@ -3228,6 +6713,7 @@ func restoreBackup(backupPath, originalPath, comment string) error {
 	}
 
 	logger.Printf("Restored: %s from %s", originalPath, backupPath)
+	auditLog("restore", originalPath, int64(len(content)), "backup", filepath.Base(backupPath))
 	fmt.Printf("✅ Successfully restored: %s\n", originalPath)
 	fmt.Printf("📦 From backup: %s\n", filepath.Base(backupPath))
 	fmt.Printf("📄 %sContent size:%s %d characters\n", ColorBrightBlue, ColorReset, len(content))
@@ -3239,9 +6725,2165 @@ func restoreBackup(backupPath, originalPath, comment string) error {
 	return nil
 }
 
-// ============================================================================
-// UTILITY FUNCTIONS
-// ============================================================================
+// confirmRestoreOverChanges warns when the file has edits that are not in
+// any backup yet and asks before a restore replaces them. The edits are
+// backed up by the restore either way, but that is easy to miss.
+func confirmRestoreOverChanges(filePath string, assumeYes bool) (bool, error) {
+	status, err := compareFileWithBackup(filePath)
+	if err != nil || status != FileStatusModified {
+		return true, nil
+	}
+
+	fmt.Printf("\n%s⚠️  %s has uncommitted changes (it differs from its last backup).%s\n", ColorYellow, filepath.Base(filePath), ColorReset)
+	fmt.Printf("   The current content will be saved as a new backup before restoring;\n")
+	fmt.Printf("   get it back with: pt -r %s --last\n\n", filepath.Base(filePath))
+
+	confirmed, err := confirmAction("Restore anyway? (y/N): ", assumeYes, "y", "yes")
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		fmt.Println("❌ Restore cancelled")
+	}
+	return confirmed, nil
+}
+
+// restoreBackupInto writes a backup's content to targetPath instead of the
+// file it was taken from (pt -r <file> --into <path>), leaving the original
+// untouched. An existing target is backed up first, like restoreBackup does.
+func restoreBackupInto(backupPath, originalPath, targetPath, comment string) error {
+	if err := validatePath(targetPath); err != nil {
+		return err
+	}
+	if filepath.Clean(targetPath) == filepath.Clean(originalPath) {
+		return restoreBackup(backupPath, originalPath, comment)
+	}
+
+	content, err := readBackupForRestore(backupPath)
+	if err != nil {
+		return err
+	}
+
+	if st, err := os.Stat(targetPath); err == nil {
+		if st.IsDir() {
+			return fmt.Errorf("%s is a directory", targetPath)
+		}
+		backupComment := comment
+		if backupComment == "" {
+			backupComment = "Backup before restore"
+		}
+		if _, err := autoRenameIfExists(targetPath, backupComment, false); err != nil {
+			return fmt.Errorf("failed to backup current file: %w", err)
+		}
+		fmt.Printf("📦 Existing %s backed up before restore\n", filepath.Base(targetPath))
+	} else if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := os.WriteFile(targetPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+
+	logger.Printf("Restored: %s from %s (backup of %s)", targetPath, backupPath, originalPath)
+	auditLog("restore", targetPath, int64(len(content)), "backup", filepath.Base(backupPath), "from", originalPath)
+	fmt.Printf("✅ Successfully restored into: %s\n", targetPath)
+	fmt.Printf("📦 From backup: %s (of %s)\n", filepath.Base(backupPath), filepath.Base(originalPath))
+	fmt.Printf("📄 %sContent size:%s %d characters\n", ColorBrightBlue, ColorReset, len(content))
+
+	if comment != "" {
+		fmt.Printf("💬 Restore comment: \"%s\"\n", comment)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// MERGE COMMAND - Three-way merge of the current file with a backup
+// ============================================================================
+
+// lineSimilarity scores how many lines a and b share, from 0 (none) to 1
+// (same lines, in any order)
+func lineSimilarity(a, b string) float64 {
+	linesA := strings.Split(a, "\n")
+	linesB := strings.Split(b, "\n")
+
+	counts := make(map[string]int, len(linesA))
+	for _, line := range linesA {
+		counts[line]++
+	}
+	common := 0
+	for _, line := range linesB {
+		if counts[line] > 0 {
+			counts[line]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(linesA)+len(linesB))
+}
+
+// pickMergeBase guesses the common ancestor of the current file and the
+// backup at index other: among the older backups, the one the current
+// content is most similar to (typically the version it was restored from)
+func pickMergeBase(current string, backups []BackupInfo, other int) (int, error) {
+	best, bestScore := -1, -1.0
+	for i := other + 1; i < len(backups); i++ {
+		content, err := backupStore.Read(backups[i].Path)
+		if err != nil {
+			continue
+		}
+		if score := lineSimilarity(current, string(content)); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return 0, fmt.Errorf("no backup older than #%d to use as the merge base (pick one with --base N)", other+1)
+	}
+	return best, nil
+}
+
+// handleMergeCommand reconciles the current file with one of its backups
+// through a three-way merge tool (pt merge <file>). The merge base is the
+// older backup closest to the current content unless --base N is given.
+// Without a merge tool, both sides' changes against the base are shown.
+func handleMergeCommand(filename, toolFlag, baseArg string, useLast bool) error {
+	filePath, err := resolveFilePath(filename)
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	backups, err := listBackups(filePath)
+	if err != nil {
+		return err
+	}
+	if len(backups) < 2 {
+		return withHint(fmt.Errorf("%w: a merge needs at least two backups of %s", errNoBackups, filePath),
+			"with a single backup, compare it with: pt -d "+filename+" --last")
+	}
+
+	other := 0
+	if !useLast {
+		printBackupTable(filePath, backups)
+		choice, err := readUserChoice(len(backups))
+		if err != nil {
+			return err
+		}
+		if choice == 0 {
+			fmt.Println("❌ Merge cancelled")
+			return nil
+		}
+		other = choice - 1
+	}
+
+	var base int
+	if baseArg != "" {
+		n, err := strconv.Atoi(baseArg)
+		if err != nil || n < 1 || n > len(backups) {
+			return fmt.Errorf("invalid --base value '%s': must be a backup number from 1 to %d", baseArg, len(backups))
+		}
+		if n-1 == other {
+			return fmt.Errorf("--base must differ from the backup being merged (#%d)", other+1)
+		}
+		base = n - 1
+	} else if base, err = pickMergeBase(string(current), backups, other); err != nil {
+		return err
+	}
+
+	describe := func(n int) string {
+		text := fmt.Sprintf("#%d %s", n+1, formatTime(backups[n].ModTime))
+		if backups[n].Comment != "" {
+			text += fmt.Sprintf(" %q", backups[n].Comment)
+		}
+		return text
+	}
+	fmt.Printf("\n%s🔀 Merging into %s%s\n", ColorBold+ColorCyan, filePath, ColorReset)
+	fmt.Printf("  %sBase:%s   %s\n", ColorCyan, ColorReset, describe(base))
+	fmt.Printf("  %sLocal:%s  current file\n", ColorCyan, ColorReset)
+	fmt.Printf("  %sOther:%s  %s\n\n", ColorCyan, ColorReset, describe(other))
+
+	toolName := resolveMergeTool(toolFlag)
+	if toolName == "" {
+		fmt.Printf("%s⚠️  No three-way merge tool installed (kdiff3, meld, ...), showing both sides' changes%s\n", ColorYellow, ColorReset)
+		return printMergeSides(backups[base], filePath, backups[other])
+	}
+
+	// The tool reads local from a copy, so it can write the result over the file
+	local, err := os.CreateTemp("", "pt-merge-local-*"+filepath.Ext(filePath))
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(local.Name())
+	if _, err := local.Write(current); err != nil {
+		local.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	local.Close()
+
+	if _, err := autoRenameIfExists(filePath, "Backup before merge", false); err != nil {
+		return fmt.Errorf("failed to backup current file: %w", err)
+	}
+
+	err = runMerge(toolName, backups[base].Path, local.Name(), backups[other].Path, filePath)
+	if errors.Is(err, errToolMissing) {
+		fmt.Printf("%s⚠️  %v, showing both sides' changes%s\n", ColorYellow, err, ColorReset)
+		return printMergeSides(backups[base], filePath, backups[other])
+	}
+	if err != nil {
+		return err
+	}
+
+	if !checkIfDifferent(filePath, current) {
+		fmt.Printf("ℹ️  %s is unchanged\n", filePath)
+		return nil
+	}
+	logger.Printf("Merged: %s with %s (base %s)", filePath, backups[other].Path, backups[base].Path)
+	auditLog("merge", filePath, 0, "other", backups[other].Name, "base", backups[base].Name)
+	fmt.Printf("✅ Merged into: %s\n", filePath)
+	fmt.Printf("💡 The pre-merge version is the newest backup: pt -r %s --last\n", filename)
+	return nil
+}
+
+// printMergeSides shows what each side changed relative to the merge base,
+// for when no three-way merge tool is available
+func printMergeSides(base BackupInfo, filePath string, other BackupInfo) error {
+	sides := []struct {
+		label string
+		path  string
+	}{
+		{"Local changes (base → current file)", filePath},
+		{fmt.Sprintf("Other changes (base → %s)", other.Name), other.Path},
+	}
+	for _, side := range sides {
+		fmt.Printf("\n%s━━━ %s%s\n\n", ColorBold+ColorCyan, side.label, ColorReset)
+		pdiff := &PDiff2{Context: contextOrDefault(3), NoHighlight: diffNoHighlight, Filename: filePath}
+		diff, err := pdiff.DiffFiles(base.Path, side.path)
+		if err != nil {
+			return fmt.Errorf("diff failed: %w", err)
+		}
+		if strings.TrimSpace(diff) == "" {
+			fmt.Printf("%sNo changes.%s\n", ColorGray, ColorReset)
+			continue
+		}
+		pdiff.PrintDiff(diff)
+	}
+	return nil
+}
+
+// ============================================================================
+// LOCKING - Serialize mutating pt invocations on a shared backup store
+// ============================================================================
+
+const (
+	lockFileName     = ".lock"
+	lockStaleTimeout = 10 * time.Minute // Age after which a lock with no readable pid is abandoned
+	lockWaitTimeout  = 3 * time.Second  // How long to wait for a busy lock
+)
+
+// activeLock is the lockfile held by this process ("" when none). The
+// interrupt handler may release it while the command is still running.
+var (
+	activeLockMu sync.Mutex
+	activeLock   string
+)
+
+// lockTarget returns the path whose backup store a command works on: its
+// first file argument, or the working directory when it takes none
+func lockTarget(info *CommandInfo) string {
+	if len(info.Files) > 0 {
+		return info.Files[0]
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// ptLockDir returns the backup store that holds target's backups, with
+// symlinks resolved so every path into the same store shares one lock, or ""
+// when no backup directory exists yet
+func ptLockDir(target string) string {
+	ptRoot, err := findPTRoot(target)
+	if err != nil || ptRoot == "" {
+		return ""
+	}
+	if filepath.Base(ptRoot) != appConfig.BackupDirName {
+		ptRoot = filepath.Join(ptRoot, appConfig.BackupDirName)
+	}
+	if info, err := os.Stat(ptRoot); err != nil || !info.IsDir() {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(ptRoot); err == nil {
+		ptRoot = resolved
+	}
+	return ptRoot
+}
+
+// readLockPID returns the pid recorded in a lockfile, or 0 if it can't be read
+func readLockPID(lockPath string) int {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0
+	}
+	pid, _ := strconv.Atoi(fields[0])
+	return pid
+}
+
+// acquirePTLock creates <.pt>/.lock exclusively in the store that holds
+// target's backups, so that concurrent pt runs do not interleave backup and
+// metadata writes. A busy lock is retried briefly. A lock whose owning pid
+// is no longer running was left behind by a crashed run and is removed; a
+// lock without a readable pid is only removed once it is older than
+// lockStaleTimeout. Nothing is locked when no backup directory exists yet.
+func acquirePTLock(target string) error {
+	ptRoot := ptLockDir(target)
+	if ptRoot == "" {
+		return nil
+	}
+
+	lockPath := filepath.Join(ptRoot, lockFileName)
+	deadline := time.Now().Add(lockWaitTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n%s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+			f.Close()
+			activeLockMu.Lock()
+			activeLock = lockPath
+			activeLockMu.Unlock()
+			logger.Printf("Acquired lock: %s", lockPath)
+			return nil
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		holderPID := readLockPID(lockPath)
+		// A lock with our own pid can't be ours (we hold at most one), so it
+		// was left by an earlier run that happened to get the same pid
+		stale := holderPID > 0 && (holderPID == os.Getpid() || !processAlive(holderPID))
+		if holderPID == 0 {
+			// The holder may still be writing its pid; only give up on it once it's old
+			if info, statErr := os.Stat(lockPath); statErr == nil {
+				stale = time.Since(info.ModTime()) > lockStaleTimeout
+			}
+		}
+		// Check the pid again right before removing, in case another run has
+		// already replaced the stale lock with its own
+		if stale && readLockPID(lockPath) == holderPID {
+			logger.Printf("Removing stale lock (pid %d): %s", holderPID, lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return withHint(fmt.Errorf("another pt operation is in progress (pid %d)", holderPID),
+				fmt.Sprintf("if it isn't, remove %s", lockPath))
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// releasePTLock removes the lockfile held by this process, if any
+func releasePTLock() {
+	activeLockMu.Lock()
+	defer activeLockMu.Unlock()
+	if activeLock == "" {
+		return
+	}
+	if err := os.Remove(activeLock); err != nil && !os.IsNotExist(err) {
+		logger.Printf("Warning: failed to release lock %s: %v", activeLock, err)
+	}
+	activeLock = ""
+}
+
+// interruptGracePeriod is how long a command gets to stop on its own after
+// Ctrl+C before pt cleans up and exits anyway (e.g. when blocked on a prompt)
+const interruptGracePeriod = 2 * time.Second
+
+// interruptCtx is cancelled on the first SIGINT/SIGTERM. Long-running loops
+// (commit, backup --all, move) check it between files.
+var interruptCtx = context.Background()
+
+// Files being written right now that must not survive an interrupt:
+// temp files, write probes and backups whose metadata isn't saved yet
+var (
+	tempFilesMu sync.Mutex
+	tempFiles   = make(map[string]bool)
+)
+
+func trackTempFile(path string) {
+	tempFilesMu.Lock()
+	tempFiles[path] = true
+	tempFilesMu.Unlock()
+}
+
+func untrackTempFile(path string) {
+	tempFilesMu.Lock()
+	delete(tempFiles, path)
+	tempFilesMu.Unlock()
+}
+
+// isTrackedTempFile reports whether path is a temp file pt will delete soon
+func isTrackedTempFile(path string) bool {
+	tempFilesMu.Lock()
+	defer tempFilesMu.Unlock()
+	return tempFiles[path]
+}
+
+// removeTrackedTempFiles deletes every file still registered with trackTempFile
+func removeTrackedTempFiles() {
+	tempFilesMu.Lock()
+	defer tempFilesMu.Unlock()
+
+	for path := range tempFiles {
+		if err := os.Remove(path); err == nil {
+			logger.Printf("Removed partial file after interrupt: %s", path)
+		}
+		delete(tempFiles, path)
+	}
+}
+
+// installInterruptHandler sets up interruptCtx. The first signal cancels it so
+// the current command can finish the file it is on and return; after
+// interruptGracePeriod, or on a second signal, partial files are removed, the
+// lock is released and pt exits with exitInterrupted.
+func installInterruptHandler() {
+	ctx, cancel := context.WithCancel(context.Background())
+	interruptCtx = ctx
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-sigCh
+		logger.Printf("Received %v, cancelling current operation", sig)
+		cancel()
+		fmt.Fprintf(os.Stderr, "\n%s⚠️  Interrupted, stopping...%s\n", ColorYellow, ColorReset)
+
+		select {
+		case <-sigCh:
+		case <-time.After(interruptGracePeriod):
+		}
+
+		removeTrackedTempFiles()
+		releasePTLock()
+		os.Exit(exitInterrupted)
+	}()
+}
+
+// mutatingCommands are the commands that write to the backup store and must
+// hold the lock ("" is the default clipboard write)
+var mutatingCommands = map[string]bool{
+	"": true, "+": true,
+	"commit": true, "backup": true, "-b": true,
+	"move": true, "mv": true, "-mv": true,
+	"fix": true, "-f": true,
+	"-rm": true, "--remove": true,
+	"-r": true, "--restore": true, "restore": true,
+	"undo": true, "redo": true,
+	"pin": true, "unpin": true,
+	"split": true, "merge": true,
+	"gc": true,
+}
+
+// ============================================================================
+// UNDO / REDO - Step through a file's backups without piling up new ones
+// ============================================================================
+
+// UndoState records which backup a file was last restored to by undo/redo
+type UndoState struct {
+	Cursor string `json:"cursor"` // Name of the backup currently restored
+}
+
+const undoStateFile = ".undo.json"
+
+func loadUndoStates(ptDir string) map[string]UndoState {
+	states := make(map[string]UndoState)
+
+	data, err := os.ReadFile(filepath.Join(ptDir, undoStateFile))
+	if err != nil {
+		return states
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		logger.Printf("Warning: failed to parse %s: %v", undoStateFile, err)
+		return make(map[string]UndoState)
+	}
+
+	return states
+}
+
+func saveUndoStates(ptDir string, states map[string]UndoState) error {
+	statePath := filepath.Join(ptDir, undoStateFile)
+
+	if len(states) == 0 {
+		err := os.Remove(statePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(statePath, data, 0644)
+}
+
+// resetUndoCursor forgets the undo position of a file. Called whenever a new
+// backup is made, since that backup becomes the new head of the history.
+func resetUndoCursor(ptDir, filePath string) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return
+	}
+
+	states := loadUndoStates(ptDir)
+	if _, ok := states[absPath]; !ok {
+		return
+	}
+
+	delete(states, absPath)
+	if err := saveUndoStates(ptDir, states); err != nil {
+		logger.Printf("Warning: failed to reset undo cursor: %v", err)
+	}
+}
+
+// handleUndoCommand moves a file one backup back (undo) or forward (redo).
+// The first undo restores the second most recent backup, since the most
+// recent one normally holds the current content. Uncommitted changes are
+// backed up before the first step so nothing is lost.
+func handleUndoCommand(args []string, redo bool) error {
+	action := "undo"
+	if redo {
+		action = "redo"
+	}
+
+	if len(args) < 1 {
+		return fmt.Errorf("filename required for %s command", action)
+	}
+
+	filePath, err := resolveFilePath(args[0])
+	if err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+
+	backups, err := listBackups(absPath)
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		return fmt.Errorf("%w for: %s", errNoBackups, absPath)
+	}
+
+	ptDir, err := ensurePTDir(absPath)
+	if err != nil {
+		return err
+	}
+
+	// Locate the current position; -1 means the working file is ahead of
+	// every backup (no undo in progress)
+	states := loadUndoStates(ptDir)
+	position := -1
+	if state, ok := states[absPath]; ok {
+		for i, b := range backups {
+			if b.Name == state.Cursor {
+				position = i
+				break
+			}
+		}
+	}
+
+	// If the file was edited since the last step (or no step was taken yet),
+	// keep its content as the new head of the history
+	reference := backups[0]
+	if position >= 0 {
+		reference = backups[position]
+	}
+	current, err := os.ReadFile(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	referenceContent, err := os.ReadFile(reference.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup file: %w", err)
+	}
+	if !bytes.Equal(current, referenceContent) {
+		if _, err := autoRenameIfExists(absPath, "Backup before "+action, false); err != nil {
+			return fmt.Errorf("failed to backup current file: %w", err)
+		}
+		if backups, err = listBackups(absPath); err != nil {
+			return err
+		}
+		states = loadUndoStates(ptDir)
+		position = 0
+	} else if position < 0 {
+		position = 0
+	}
+
+	target := position + 1
+	if redo {
+		target = position - 1
+	}
+
+	if target < 0 {
+		return fmt.Errorf("nothing to redo for: %s", filepath.Base(absPath))
+	}
+	if target >= len(backups) {
+		return fmt.Errorf("nothing to undo: already at the oldest backup of %s", filepath.Base(absPath))
+	}
+
+	content, err := backupStore.Read(backups[target].Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup file: %w", err)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(absPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(absPath, content, mode); err != nil {
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+
+	states[absPath] = UndoState{Cursor: backups[target].Name}
+	if err := saveUndoStates(ptDir, states); err != nil {
+		logger.Printf("Warning: failed to save undo state: %v", err)
+	}
+
+	logger.Printf("%s: %s -> %s", action, absPath, backups[target].Path)
+
+	label := "↩️  Undo"
+	if redo {
+		label = "↪️  Redo"
+	}
+	fmt.Printf("%s%s%s: %s\n", ColorBold, label, ColorReset, filepath.Base(absPath))
+	fmt.Printf("📦 Now at backup: %s%s%s (%d of %d, newest first)\n",
+		ColorBrightYellow, backups[target].Name, ColorReset, target+1, len(backups))
+	if backups[target].Comment != "" {
+		fmt.Printf("💬 Comment: \"%s\"\n", backups[target].Comment)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// AT COMMAND - Point-in-time view of a file from its backups
+// ============================================================================
+
+// parseTimeArg parses an absolute date/time (local time, or UTC with
+// use_utc) or a relative age
+// such as "30m", "2h", "7d" or "2w" (meaning that long ago)
+func parseTimeArg(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"20060102_150405",
+	}
+	if appConfig.TimeFormat != "" {
+		layouts = append([]string{timeLayout()}, layouts...)
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, displayLocation()); err == nil {
+			return t, nil
+		}
+	}
+
+	if len(value) >= 2 {
+		unit := value[len(value)-1]
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			switch unit {
+			case 'd':
+				return time.Now().AddDate(0, 0, -n), nil
+			case 'w':
+				return time.Now().AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s' (use e.g. \"2025-11-18 14:30\", 2025-11-18 or 3d)", value)
+}
+
+// backupTime returns when a backup was taken, preferring the metadata
+// timestamp over the file modification time
+func backupTime(backup BackupInfo) time.Time {
+	if metadata, err := readBackupMetadata(backup.Path); err == nil && metadata != nil && !metadata.Timestamp.IsZero() {
+		return metadata.Timestamp
+	}
+	return backup.ModTime
+}
+
+// findBackupsByOriginal scans every backup directory for backups whose
+// metadata records absPath as the original file. This finds the history of
+// a file that has since been moved or renamed outside pt.
+func findBackupsByOriginal(ptRoot, absPath string) []BackupInfo {
+	backups := make([]BackupInfo, 0)
+
+	filepath.Walk(ptRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".meta.json") {
+			return nil
+		}
+
+		backupPath := strings.TrimSuffix(path, ".meta.json")
+		metadata, err := readBackupMetadata(backupPath)
+		if err != nil || metadata == nil {
+			return nil
+		}
+
+		original := metadata.Original
+		if !filepath.IsAbs(original) {
+			original, _ = filepath.Abs(original)
+		}
+		if original != absPath {
+			return nil
+		}
+
+		backupInfo, err := os.Stat(backupPath)
+		if err != nil {
+			return nil
+		}
+
+		backups = append(backups, BackupInfo{
+			Path:    backupPath,
+			Name:    filepath.Base(backupPath),
+			ModTime: backupInfo.ModTime(),
+			Size:    backupInfo.Size(),
+			Comment: metadata.Comment,
+		})
+		return nil
+	})
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime.After(backups[j].ModTime)
+	})
+
+	return backups
+}
+
+// handleAtCommand prints (or writes with --out) the content a file had at a
+// given point in time, i.e. its newest backup taken at or before that time
+func handleAtCommand(args []string) error {
+	outPath := ""
+	positional := []string{}
+
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--out" || args[i] == "-o" {
+			if i+1 >= len(args) {
+				return fmt.Errorf("--out requires a value")
+			}
+			i++
+			outPath = args[i]
+		} else {
+			positional = append(positional, args[i])
+		}
+	}
+
+	if len(positional) < 2 {
+		return fmt.Errorf("usage: pt at <file> <timestamp> [--out <path>]")
+	}
+
+	at, err := parseTimeArg(strings.Join(positional[1:], " "))
+	if err != nil {
+		return err
+	}
+
+	filePath, err := resolveFilePath(positional[0])
+	if err != nil {
+		// The file may have been deleted or moved; look up its backups by path
+		filePath, err = filepath.Abs(positional[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	backups, err := listBackups(filePath)
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		if ptRoot, err := findPTRoot(filepath.Dir(filePath)); err == nil && ptRoot != "" {
+			if filepath.Base(ptRoot) != appConfig.BackupDirName {
+				ptRoot = filepath.Join(ptRoot, appConfig.BackupDirName)
+			}
+			backups = findBackupsByOriginal(ptRoot, filePath)
+		}
+	}
+
+	if len(backups) == 0 {
+		return fmt.Errorf("%w for: %s", errNoBackups, filePath)
+	}
+
+	var selected *BackupInfo
+	var selectedTime time.Time
+	for i := range backups {
+		t := backupTime(backups[i])
+		if t.After(at) {
+			continue
+		}
+		if selected == nil || t.After(selectedTime) {
+			selected = &backups[i]
+			selectedTime = t
+		}
+	}
+
+	if selected == nil {
+		oldest := backupTime(backups[len(backups)-1])
+		return fmt.Errorf("no backup of %s exists at or before %s (oldest is %s)",
+			filepath.Base(filePath), formatTime(at), formatTime(oldest))
+	}
+
+	content, err := backupStore.Read(selected.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup file: %w", err)
+	}
+
+	if outPath == "" {
+		// Keep stdout clean for piping; describe the match on stderr
+		fmt.Fprintf(os.Stderr, "%s🕒 %s as of %s (backup %s, %s)%s\n", ColorGray,
+			filepath.Base(filePath), formatTime(at),
+			selected.Name, formatTime(selectedTime), ColorReset)
+		os.Stdout.Write(content)
+		return nil
+	}
+
+	if err := validatePath(outPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	fmt.Printf("✅ Wrote %s as of %s to: %s\n", filepath.Base(filePath), formatTime(at), outPath)
+	fmt.Printf("📦 From backup: %s (%s)\n", selected.Name, formatTime(selectedTime))
+	if selected.Comment != "" {
+		fmt.Printf("💬 Comment: \"%s\"\n", selected.Comment)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// RECURSIVE RESTORE - Roll a directory back to its latest backups
+// ============================================================================
+
+// latestBackupsUnder returns the newest backup of every file under absDir,
+// keyed by absolute path. Files still on disk are looked up with listBackups;
+// deleted files are found through the metadata scan used by orphan detection.
+func latestBackupsUnder(absDir string) (map[string]BackupInfo, error) {
+	latest := make(map[string]BackupInfo)
+
+	consider := func(path string, backup BackupInfo) {
+		if current, ok := latest[path]; !ok || backupTime(backup).After(backupTime(current)) {
+			latest[path] = backup
+		}
+	}
+
+	err := filepath.Walk(absDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == appConfig.BackupDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if backups, err := listBackups(path); err == nil && len(backups) > 0 {
+			consider(path, backups[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ptRoot, err := findPTRoot(absDir)
+	if err != nil || ptRoot == "" {
+		return latest, nil
+	}
+	if filepath.Base(ptRoot) != appConfig.BackupDirName {
+		ptRoot = filepath.Join(ptRoot, appConfig.BackupDirName)
+	}
+
+	prefix := absDir + string(os.PathSeparator)
+	filepath.Walk(ptRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".meta.json") {
+			return nil
+		}
+
+		backupPath := strings.TrimSuffix(path, ".meta.json")
+		metadata, err := readBackupMetadata(backupPath)
+		if err != nil || metadata == nil || metadata.Original == "" {
+			return nil
+		}
+
+		original := metadata.Original
+		if !filepath.IsAbs(original) {
+			original, _ = filepath.Abs(original)
+		}
+		if !strings.HasPrefix(original, prefix) {
+			return nil
+		}
+
+		backupInfo, err := os.Stat(backupPath)
+		if err != nil {
+			return nil
+		}
+
+		consider(original, BackupInfo{
+			Path:    backupPath,
+			Name:    filepath.Base(backupPath),
+			ModTime: backupInfo.ModTime(),
+			Size:    backupInfo.Size(),
+			Comment: metadata.Comment,
+		})
+		return nil
+	})
+
+	return latest, nil
+}
+
+// handleRestoreDirCommand restores every file under dir (including deleted
+// ones) from its latest backup. Files already matching their backup are
+// skipped. With assumeYes (--last/--yes) no confirmation is asked.
+func handleRestoreDirCommand(dir, comment string, assumeYes bool) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(absDir); err == nil && !info.IsDir() {
+		return fmt.Errorf("not a directory: %s (use pt restore <file> for single files)", dir)
+	}
+
+	latest, err := latestBackupsUnder(absDir)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	type restoreItem struct {
+		path    string
+		backup  BackupInfo
+		deleted bool
+	}
+
+	var items []restoreItem
+	for path, backup := range latest {
+		current, err := os.ReadFile(path)
+		if err != nil {
+			items = append(items, restoreItem{path, backup, true})
+			continue
+		}
+		saved, err := backupStore.Read(backup.Path)
+		if err != nil || bytes.Equal(current, saved) {
+			continue
+		}
+		items = append(items, restoreItem{path, backup, false})
+	}
+
+	if len(items) == 0 {
+		fmt.Printf("%s✓ Nothing to restore. All files under %s match their latest backup.%s\n", ColorGreen, dir, ColorReset)
+		return nil
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].path < items[j].path
+	})
+
+	fmt.Printf("\n%s♻️  Files to restore under %s:%s\n", ColorBold+ColorCyan, absDir, ColorReset)
+	for i, item := range items {
+		relPath, _ := filepath.Rel(absDir, item.path)
+		state := ColorYellow + "[modified]" + ColorReset
+		if item.deleted {
+			state = ColorRed + "[deleted]" + ColorReset
+		}
+		fmt.Printf("  %d. %s%s%s %s %s(%s)%s\n", i+1, ColorGreen, relPath, ColorReset, state,
+			ColorGray, formatTime(backupTime(item.backup)), ColorReset)
+	}
+	fmt.Println()
+
+	prompt := fmt.Sprintf("Restore %d file(s) from their latest backup? (y/N): ", len(items))
+	confirmed, err := confirmAction(prompt, assumeYes, "y", "yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("❌ Restore cancelled")
+		return nil
+	}
+
+	if comment == "" {
+		comment = "Restored directory from last backup"
+	}
+
+	successCount := 0
+	failCount := 0
+	for _, item := range items {
+		if err := restoreBackup(item.backup.Path, item.path, comment); err != nil {
+			fmt.Printf("%s✗%s %s: %v\n", ColorRed, ColorReset, item.path, err)
+			failCount++
+			continue
+		}
+		successCount++
+	}
+
+	fmt.Println()
+	fmt.Printf("%s♻️  Restore Summary:%s\n", ColorBold, ColorReset)
+	fmt.Printf("  %s✓ %d files restored%s\n", ColorGreen, successCount, ColorReset)
+	if failCount > 0 {
+		fmt.Printf("  %s✗ %d files failed%s\n", ColorRed, failCount, ColorReset)
+		return fmt.Errorf("%d file(s) could not be restored", failCount)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// PIN COMMAND - Keep important backups past the max_backup_count cap
+// ============================================================================
+
+// handlePinCommand pins or unpins backup number n of filePath, numbered as in
+// pt -l (1 = newest)
+func handlePinCommand(filePath string, n int, pinned bool) error {
+	backups, err := listBackups(filePath)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return fmt.Errorf("%w for: %s", errNoBackups, filePath)
+	}
+	if n < 1 || n > len(backups) {
+		return fmt.Errorf("backup number must be between 1 and %d", len(backups))
+	}
+
+	backup := backups[n-1]
+	metadata, err := readBackupMetadata(backup.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if metadata == nil {
+		// Backups made before sidecars existed: start one from what we know
+		absPath, _ := filepath.Abs(filePath)
+		metadata = &BackupMetadata{
+			Timestamp: backup.ModTime,
+			Size:      backup.Size,
+			Original:  absPath,
+		}
+	}
+
+	if metadata.Pinned == pinned {
+		state := "pinned"
+		if !pinned {
+			state = "not pinned"
+		}
+		fmt.Printf("ℹ️  Backup %d (%s) is already %s\n", n, backup.Name, state)
+		return nil
+	}
+
+	metadata.Pinned = pinned
+	if err := writeBackupMetadata(backup.Path, metadata); err != nil {
+		return err
+	}
+
+	if pinned {
+		fmt.Printf("📌 Pinned backup %d: %s%s%s\n", n, ColorBrightYellow, backup.Name, ColorReset)
+	} else {
+		fmt.Printf("✅ Unpinned backup %d: %s%s%s\n", n, ColorBrightYellow, backup.Name, ColorReset)
+	}
+	return nil
+}
+
+// ============================================================================
+// SIZE COMMAND - Disk usage of the backup store per original file
+// ============================================================================
+
+// storeUsage is the disk usage of one per-file backup directory
+type storeUsage struct {
+	Name  string // original file relative to the project, or the subdir name
+	Count int    // number of backups
+	Bytes int64  // backups plus their metadata sidecars
+}
+
+// collectStoreUsage walks a .pt directory and sums every per-file backup
+// subdir. Files directly in ptDir (lock, undo state) are not counted.
+func collectStoreUsage(ptDir string) ([]storeUsage, error) {
+	projectRoot := filepath.Dir(ptDir)
+	usage := make(map[string]*storeUsage)
+
+	err := filepath.Walk(ptDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		subdir := filepath.Dir(path)
+		if subdir == ptDir {
+			return nil
+		}
+
+		entry, ok := usage[subdir]
+		if !ok {
+			rel, _ := filepath.Rel(ptDir, subdir)
+			entry = &storeUsage{Name: rel}
+			usage[subdir] = entry
+		}
+
+		entry.Bytes += info.Size()
+		if strings.HasSuffix(path, ".meta.json") {
+			// Prefer the real file name over the flattened subdir name
+			if metadata, err := readBackupMetadata(strings.TrimSuffix(path, ".meta.json")); err == nil && metadata != nil && metadata.Original != "" {
+				if rel, err := filepath.Rel(projectRoot, metadata.Original); err == nil && !strings.HasPrefix(rel, "..") {
+					entry.Name = rel
+				}
+			}
+		} else if filepath.Base(path) != backupDirConfigFile {
+			entry.Count++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storeUsage, 0, len(usage))
+	for _, entry := range usage {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bytes != result[j].Bytes {
+			return result[i].Bytes > result[j].Bytes
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// findPTStore returns the .pt directory that serves dir, or errNoBackups
+// when there is none
+func findPTStore(dir string) (string, error) {
+	ptRoot, err := findPTRoot(dir)
+	if err != nil || ptRoot == "" {
+		return "", fmt.Errorf("%w: no %s directory here or in any parent", errNoBackups, appConfig.BackupDirName)
+	}
+	if filepath.Base(ptRoot) != appConfig.BackupDirName {
+		ptRoot = filepath.Join(ptRoot, appConfig.BackupDirName)
+	}
+	if info, err := os.Stat(ptRoot); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%w: no %s directory here or in any parent", errNoBackups, appConfig.BackupDirName)
+	}
+	return ptRoot, nil
+}
+
+// handleSizeCommand prints the biggest consumers of the backup store
+// (pt size [--top N])
+func handleSizeCommand(top int) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	ptRoot, err := findPTStore(cwd)
+	if err != nil {
+		return err
+	}
+
+	usage, err := collectStoreUsage(ptRoot)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", ptRoot, err)
+	}
+
+	var totalBytes int64
+	totalCount := 0
+	for _, entry := range usage {
+		totalBytes += entry.Bytes
+		totalCount += entry.Count
+	}
+
+	fmt.Printf("\n%s💽 Backup store usage:%s %s\n\n", ColorBold+ColorCyan, ColorReset, ptRoot)
+
+	if len(usage) == 0 {
+		fmt.Printf("%s✓ The backup store is empty.%s\n", ColorGreen, ColorReset)
+		return nil
+	}
+
+	shown := usage
+	if top > 0 && top < len(shown) {
+		shown = shown[:top]
+	}
+
+	nameWidth := len("File")
+	for _, entry := range shown {
+		nameWidth = max(nameWidth, len(entry.Name))
+	}
+	nameWidth = min(nameWidth, 60)
+
+	fmt.Printf("  %s%-*s  %8s  %10s%s\n", ColorBold+ColorYellow, nameWidth, "File", "Backups", "Size", ColorReset)
+	for _, entry := range shown {
+		name := entry.Name
+		if len(name) > nameWidth {
+			name = "..." + name[len(name)-nameWidth+3:]
+		}
+		fmt.Printf("  %-*s  %8d  %10s\n", nameWidth, name, entry.Count, formatSize(entry.Bytes))
+	}
+
+	if len(shown) < len(usage) {
+		fmt.Printf("  %s... %d more file(s)%s\n", ColorGray, len(usage)-len(shown), ColorReset)
+	}
+
+	fmt.Println()
+	fmt.Printf("  %sTotal:%s %s in %d backup(s) of %d file(s)\n",
+		ColorBold, ColorReset, formatSize(totalBytes), totalCount, len(usage))
+
+	return nil
+}
+
+// handleGCCommand rebuilds the store's metadata index from the sidecars
+// (pt gc). Sidecars whose backup no longer exists are deleted; the rest are
+// kept so older pt versions can still read the store.
+func handleGCCommand() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	ptRoot, err := findPTStore(cwd)
+	if err != nil {
+		return err
+	}
+
+	previous := 0
+	if old := readBackupIndex(ptRoot); old != nil {
+		previous = len(old.Entries)
+	}
+
+	idx := &backupIndex{Version: 1, Entries: make(map[string]backupIndexEntry)}
+	orphans, unreadable := 0, 0
+	err = filepath.Walk(ptRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".meta.json") {
+			return nil
+		}
+
+		backupPath := strings.TrimSuffix(path, ".meta.json")
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			if err := os.Remove(path); err == nil {
+				orphans++
+			}
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			unreadable++
+			return nil
+		}
+		var metadata BackupMetadata
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			logger.Printf("gc: skipping unreadable %s: %v", path, err)
+			unreadable++
+			return nil
+		}
+
+		rel, _ := filepath.Rel(ptRoot, backupPath)
+		idx.Entries[filepath.ToSlash(rel)] = backupIndexEntry{
+			Metadata:    metadata,
+			SidecarSize: info.Size(),
+			SidecarTime: info.ModTime(),
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", ptRoot, err)
+	}
+
+	// The rebuilt index covers everything the journal recorded
+	backupIndexMu.Lock()
+	err = writeBackupIndex(ptRoot, idx)
+	if err == nil {
+		backupIndexes[ptRoot] = idx
+		if err := os.Remove(filepath.Join(ptRoot, backupIndexJournal)); err != nil && !os.IsNotExist(err) {
+			logger.Printf("Warning: failed to remove index journal: %v", err)
+		}
+		// A folder by one of these names holds the backups of a file
+		for _, name := range legacyBackupIndexFiles {
+			path := filepath.Join(ptRoot, name)
+			if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() {
+				os.Remove(path)
+			}
+		}
+	}
+	backupIndexMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	auditLog("gc", ptRoot, int64(len(idx.Entries)))
+
+	fmt.Printf("%s✅ Indexed %d backup(s) into %s%s\n", ColorGreen, len(idx.Entries),
+		filepath.Join(ptRoot, backupIndexFile), ColorReset)
+	if previous > 0 {
+		fmt.Printf("  Previous index had %d entries\n", previous)
+	}
+	if orphans > 0 {
+		fmt.Printf("  🧹 Removed %d metadata file(s) of deleted backups\n", orphans)
+	}
+	if unreadable > 0 {
+		fmt.Printf("  %s⚠️  %d metadata file(s) could not be read and were left out%s\n", ColorYellow, unreadable, ColorReset)
+	}
+	return nil
+}
+
+// ============================================================================
+// COMMITS COMMAND - Rebuild pt commit history from backup comments
+// ============================================================================
+
+// commitGroupWindow is how far apart two backups with the same commit
+// message may be and still belong to the same pt commit. Only backups made
+// before pt commit stamped a commit_id are grouped this way.
+const commitGroupWindow = 2 * time.Minute
+
+// commitFile is one file's backup within a reconstructed commit
+type commitFile struct {
+	Original string // absolute path of the backed up file
+	Backup   BackupInfo
+}
+
+// storeCommit is a pt commit rebuilt from the store: the backups that share
+// a commit_id, or for older backups without one, a "commit: " message and
+// were taken within commitGroupWindow of each other
+type storeCommit struct {
+	ID      string
+	Key     string // the commit_id, if the backups have one
+	Message string
+	Time    time.Time // of the first backup in the commit
+	Files   []commitFile
+}
+
+// collectCommits walks a .pt directory and groups its "commit: " backups
+// into commits, newest first
+func collectCommits(ptDir string) ([]*storeCommit, error) {
+	var files []commitFile
+	var times []time.Time
+	var ids []string
+
+	err := filepath.Walk(ptDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".meta.json") {
+			return nil
+		}
+		backupPath := strings.TrimSuffix(path, ".meta.json")
+		metadata, err := readBackupMetadata(backupPath)
+		if err != nil || metadata == nil || !strings.HasPrefix(metadata.Comment, "commit: ") {
+			return nil
+		}
+		backup, err := backupStore.Stat(backupPath)
+		if err != nil {
+			return nil
+		}
+
+		when := metadata.Timestamp
+		if when.IsZero() {
+			when = backup.ModTime
+		}
+		files = append(files, commitFile{Original: metadata.Original, Backup: backup})
+		times = append(times, when)
+		ids = append(ids, metadata.CommitID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return times[order[a]].Before(times[order[b]]) })
+
+	// Oldest first: a backup joins the commit with its commit_id. Without
+	// one it joins the open commit with its message if the previous backup
+	// of that commit is recent enough.
+	var commits []*storeCommit
+	byID := make(map[string]*storeCommit)
+	open := make(map[string]*storeCommit)
+	last := make(map[*storeCommit]time.Time)
+	for _, i := range order {
+		message := strings.TrimPrefix(files[i].Backup.Comment, "commit: ")
+		if id := ids[i]; id != "" {
+			commit := byID[id]
+			if commit == nil {
+				commit = &storeCommit{Key: id, Time: times[i]}
+				commits = append(commits, commit)
+				byID[id] = commit
+			}
+			commit.Message = message // an amend may have reworded it
+			commit.Files = append(commit.Files, files[i])
+			continue
+		}
+
+		commit := open[message]
+		if commit == nil || times[i].Sub(last[commit]) > commitGroupWindow {
+			commit = &storeCommit{Message: message, Time: times[i]}
+			commits = append(commits, commit)
+			open[message] = commit
+		}
+		commit.Files = append(commit.Files, files[i])
+		last[commit] = times[i]
+	}
+
+	for _, commit := range commits {
+		key := commit.Key
+		if key == "" {
+			key = commit.Message + "\x00" + commit.Time.UTC().Format(time.RFC3339Nano)
+		}
+		sum := sha1.Sum([]byte(key))
+		commit.ID = hex.EncodeToString(sum[:])[:7]
+		sort.Slice(commit.Files, func(a, b int) bool { return commit.Files[a].Original < commit.Files[b].Original })
+	}
+	sort.SliceStable(commits, func(a, b int) bool { return commits[a].Time.After(commits[b].Time) })
+	return commits, nil
+}
+
+// handleCommitsCommand lists the commits made with pt commit (pt commits)
+// or diffs one of them (pt commits show <id>)
+func handleCommitsCommand(args []string, usePager bool) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	ptDir, err := findPTStore(cwd)
+	if err != nil {
+		return err
+	}
+	projectRoot := filepath.Dir(ptDir)
+
+	commits, err := collectCommits(ptDir)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", ptDir, err)
+	}
+
+	if len(args) > 0 {
+		if args[0] != "show" || len(args) < 2 {
+			return fmt.Errorf("usage: pt commits [show <id>]")
+		}
+		var matched []*storeCommit
+		for _, commit := range commits {
+			if strings.HasPrefix(commit.ID, args[1]) {
+				matched = append(matched, commit)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			return withHint(fmt.Errorf("commit %s %w", args[1], errNotFound), "run 'pt commits' to list commit ids")
+		case 1:
+			return showStoreCommit(matched[0], projectRoot, usePager)
+		default:
+			return fmt.Errorf("commit id %s is ambiguous (%d matches), use more characters", args[1], len(matched))
+		}
+	}
+
+	if len(commits) == 0 {
+		fmt.Printf("%s✓ No commits yet. Use: pt commit -m \"message\"%s\n", ColorGreen, ColorReset)
+		return nil
+	}
+
+	fmt.Printf("\n%s📜 Commits in%s %s\n", ColorBold+ColorCyan, ColorReset, ptDir)
+	for _, commit := range commits {
+		fmt.Printf("\n%s%s%s  %s  %s%q%s  %s(%d file(s))%s\n",
+			ColorBrightYellow, commit.ID, ColorReset,
+			formatTime(commit.Time),
+			ColorMagenta, commit.Message, ColorReset,
+			ColorGray, len(commit.Files), ColorReset)
+		for _, file := range commit.Files {
+			fmt.Printf("    %s\n", relToProject(projectRoot, file.Original))
+		}
+	}
+	fmt.Printf("\n%sUse 'pt commits show <id>' to see a commit's changes%s\n", ColorGray, ColorReset)
+	return nil
+}
+
+// showStoreCommit diffs every file of a commit against the backup before it
+func showStoreCommit(commit *storeCommit, projectRoot string, usePager bool) error {
+	var output bytes.Buffer
+	fmt.Fprintf(&output, "\n%scommit %s%s  %s  %s%q%s\n",
+		ColorBold+ColorBrightYellow, commit.ID, ColorReset,
+		formatTime(commit.Time), ColorMagenta, commit.Message, ColorReset)
+
+	for _, file := range commit.Files {
+		if interruptCtx.Err() != nil {
+			return errInterrupted
+		}
+
+		// The previous version is the backup right after this one in
+		// newest-first order; a file committed for the first time has none
+		var previous any = []byte{}
+		status := "new"
+		if backups, err := listBackups(file.Original); err == nil {
+			for i, backup := range backups {
+				if backup.Path == file.Backup.Path && i+1 < len(backups) {
+					previous = backups[i+1].Path
+					status = "modified"
+					break
+				}
+			}
+		}
+
+		fmt.Fprintf(&output, "\n%s━━━ %s%s %s(%s)%s\n\n", ColorBold+ColorCyan,
+			relToProject(projectRoot, file.Original), ColorReset, ColorGray, status, ColorReset)
+		pdiff := &PDiff2{Context: contextOrDefault(3), Out: &output, NoHighlight: diffNoHighlight, Filename: file.Original}
+		diff, err := pdiff.DiffFiles(previous, file.Backup.Path)
+		if err != nil {
+			return fmt.Errorf("diff failed: %w", err)
+		}
+		if strings.TrimSpace(diff) == "" {
+			fmt.Fprintf(&output, "%sNo changes.%s\n", ColorGray, ColorReset)
+			continue
+		}
+		pdiff.PrintDiff(diff)
+	}
+
+	if usePager {
+		return displayWithPager(output.String())
+	}
+	fmt.Print(output.String())
+	return nil
+}
+
+// relToProject shows path relative to projectRoot when it lies inside it
+func relToProject(projectRoot, path string) string {
+	if rel, err := filepath.Rel(projectRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// ============================================================================
+// EXPORT COMMAND - Replay backup history into a git repository
+// ============================================================================
+
+// exportEntry is one backup to replay as a git commit
+type exportEntry struct {
+	RelPath string // path of the file inside the exported repository
+	Backup  BackupInfo
+	Time    time.Time // metadata timestamp, or the backup's mtime
+}
+
+// collectFileExport returns the backups of one file, oldest first
+func collectFileExport(filename string) ([]exportEntry, error) {
+	filePath, err := resolveFilePath(filename)
+	if err != nil {
+		// Deleted files still have backups: fall back to the literal path
+		filePath = filename
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := listBackups(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, withHint(fmt.Errorf("%w for %s", errNoBackups, filename), "back it up first with pt <file> or pt commit")
+	}
+
+	relPath := filepath.Base(absPath)
+	if ptDir, err := findPTStore(filepath.Dir(absPath)); err == nil {
+		relPath = relToProject(filepath.Dir(ptDir), absPath)
+	}
+
+	entries := make([]exportEntry, 0, len(backups))
+	for _, backup := range backups {
+		entries = append(entries, exportEntry{RelPath: relPath, Backup: backup, Time: backupTime(backup)})
+	}
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].Time.Before(entries[b].Time) })
+	return entries, nil
+}
+
+// collectStoreExport returns every backup in ptDir, oldest first. The
+// flattened backup directory names cannot be turned back into paths, so
+// backups whose original_file can't be placed in the project (see
+// exportRelPath) are skipped and counted.
+func collectStoreExport(ptDir string) ([]exportEntry, int, error) {
+	dirs, err := os.ReadDir(ptDir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entries []exportEntry
+	skipped := 0
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		backups, err := backupStore.List(filepath.Join(ptDir, dir.Name()))
+		if err != nil {
+			logger.Printf("Warning: failed to list %s: %v", dir.Name(), err)
+			continue
+		}
+		for _, backup := range backups {
+			metadata, err := readBackupMetadata(backup.Path)
+			if err != nil || metadata == nil || metadata.Original == "" {
+				skipped++
+				continue
+			}
+			relPath, ok := exportRelPath(ptDir, filepath.Join(ptDir, dir.Name()), metadata.Original)
+			if !ok {
+				skipped++
+				continue
+			}
+			when := metadata.Timestamp
+			if when.IsZero() {
+				when = backup.ModTime
+			}
+			entries = append(entries, exportEntry{
+				RelPath: relPath,
+				Backup:  backup,
+				Time:    when,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		if !entries[a].Time.Equal(entries[b].Time) {
+			return entries[a].Time.Before(entries[b].Time)
+		}
+		return entries[a].RelPath < entries[b].RelPath
+	})
+	return entries, skipped, nil
+}
+
+// exportRelPath returns the project-relative path of a backup in backupDir
+// whose metadata names original. After the project folder is moved or
+// renamed original no longer lies under it, so the trailing parts of
+// original are tried instead; a path only counts if the store would keep
+// its backups in backupDir. It reports false when no path fits.
+func exportRelPath(ptDir, backupDir, original string) (string, bool) {
+	projectRoot := filepath.Dir(ptDir)
+	fits := func(rel string) bool {
+		if !filepath.IsLocal(rel) {
+			return false
+		}
+		dir, err := backupStore.Dir(ptDir, filepath.Join(projectRoot, rel))
+		return err == nil && dir == backupDir
+	}
+
+	if rel, err := filepath.Rel(projectRoot, original); err == nil && fits(rel) {
+		return rel, true
+	}
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(original)), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		rel := filepath.Join(parts[i:]...)
+		if fits(rel) {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+// runGit runs git in dir, returning its output in the error when it fails
+func runGit(dir string, env []string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// handleExportGitCommand replays backups into a new git repository at
+// outDir, one commit per backup with the backup's comment as the message
+// and its timestamp as the author and committer date (pt export --git)
+func handleExportGitCommand(outDir string, files []string, all bool) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return withHint(fmt.Errorf("git not found in PATH"), "install git to use pt export --git")
+	}
+	if all == (len(files) > 0) {
+		return fmt.Errorf("usage: pt export --git <outdir> <file>  or  pt export --git <outdir> --all")
+	}
+
+	var entries []exportEntry
+	skipped := 0
+	if all {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		ptDir, err := findPTStore(cwd)
+		if err != nil {
+			return err
+		}
+		entries, skipped, err = collectStoreExport(ptDir)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", ptDir, err)
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("%w with metadata in %s", errNoBackups, ptDir)
+		}
+	} else {
+		var err error
+		entries, err = collectFileExport(files[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	// Every file must land inside outDir
+	for _, entry := range entries {
+		if !filepath.IsLocal(entry.RelPath) {
+			return fmt.Errorf("refusing to export %s outside %s", entry.RelPath, outDir)
+		}
+	}
+
+	// Never commit on top of an existing repository or someone's files
+	if dirEntries, err := os.ReadDir(outDir); err == nil && len(dirEntries) > 0 {
+		return withHint(fmt.Errorf("%s already exists and is not empty", outDir), "export into a new or empty directory")
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outDir, err)
+	}
+	if err := runGit(outDir, nil, "init", "-q"); err != nil {
+		return err
+	}
+
+	// Commits need an identity; use one only when git has none configured
+	var identity []string
+	if err := runGit(outDir, nil, "config", "user.email"); err != nil {
+		identity = []string{
+			"GIT_AUTHOR_NAME=pt", "GIT_AUTHOR_EMAIL=pt@localhost",
+			"GIT_COMMITTER_NAME=pt", "GIT_COMMITTER_EMAIL=pt@localhost",
+		}
+	}
+
+	fmt.Printf("%s📤 Exporting %d backup(s) to%s %s\n", ColorCyan, len(entries), ColorReset, outDir)
+	isTTY := term.IsTerminal(int(os.Stdout.Fd()))
+	start := time.Now()
+	for i, entry := range entries {
+		if interruptCtx.Err() != nil {
+			return errInterrupted
+		}
+
+		content, err := backupStore.Read(entry.Backup.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read backup %s: %w", entry.Backup.Name, err)
+		}
+		target := filepath.Join(outDir, entry.RelPath)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", entry.RelPath, err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.RelPath, err)
+		}
+
+		message := entry.Backup.Comment
+		if message == "" {
+			message = fmt.Sprintf("Backup of %s", entry.RelPath)
+		}
+		date := entry.Time.Format(time.RFC3339)
+		env := append([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, identity...)
+
+		if err := runGit(outDir, env, "add", "--", entry.RelPath); err != nil {
+			return err
+		}
+		// A backup identical to the previous one still gets its commit
+		if err := runGit(outDir, env, "commit", "-q", "--allow-empty", "-m", message); err != nil {
+			return err
+		}
+
+		if isTTY {
+			printProgressLine(i+1, len(entries), start, entry.RelPath)
+		}
+	}
+	if isTTY {
+		fmt.Print("\r\033[K")
+	}
+
+	fmt.Printf("%s✅ Created %d commit(s) in%s %s\n", ColorGreen, len(entries), ColorReset, outDir)
+	if skipped > 0 {
+		fmt.Printf("%s⚠️  Skipped %d backup(s) whose original path is missing or no longer in this project (export them per file)%s\n",
+			ColorYellow, skipped, ColorReset)
+	}
+	fmt.Printf("%sBrowse with: git -C %s log --stat%s\n", ColorGray, outDir, ColorReset)
+	return nil
+}
+
+// ============================================================================
+// SPLIT COMMAND - Write a multi-file paste to its files
+// ============================================================================
+
+// defaultSplitMarker matches section headers like "=== path/to/file ==="
+const defaultSplitMarker = `^=== (.+) ===$`
+
+// splitSection is one file's worth of a split paste
+type splitSection struct {
+	Path    string
+	Content string
+	Err     error // set when the section can't be written (e.g. patch mismatch)
+}
+
+// isUnifiedDiff reports whether text looks like a patch rather than
+// marker-separated files
+func isUnifiedDiff(text string) bool {
+	hasHeader, hasHunk := false, false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "+++ ") {
+			hasHeader = true
+		} else if strings.HasPrefix(line, "@@ ") {
+			hasHunk = true
+		}
+	}
+	return hasHeader && hasHunk
+}
+
+// splitByMarker cuts text into sections at each line matching marker, whose
+// first capture group is the file path
+func splitByMarker(text string, marker *regexp.Regexp) []splitSection {
+	var sections []splitSection
+	var current *splitSection
+	var body []string
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+			body = body[:len(body)-1]
+		}
+		current.Content = strings.Join(body, "\n") + "\n"
+		sections = append(sections, *current)
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if m := marker.FindStringSubmatch(line); m != nil {
+			flush()
+			current = &splitSection{Path: strings.TrimSpace(m[1])}
+			body = nil
+			continue
+		}
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	flush()
+	return sections
+}
+
+// splitByPatch turns a unified diff into sections holding the patched
+// content of each file
+func splitByPatch(text string) []splitSection {
+	var sections []splitSection
+	pdiff := &PDiff2{}
+	for _, f := range pdiff.ParseDiff(strings.ReplaceAll(text, "\r\n", "\n")) {
+		if f.New == "/dev/null" {
+			sections = append(sections, splitSection{
+				Path: patchPath(f.Old),
+				Err:  fmt.Errorf("patch deletes the file, delete it with pt -rm instead"),
+			})
+			continue
+		}
+
+		section := splitSection{Path: patchPath(f.New)}
+		current := ""
+		if f.Old != "/dev/null" {
+			data, err := os.ReadFile(section.Path)
+			if err != nil {
+				section.Err = fmt.Errorf("cannot read file to patch: %w", err)
+				sections = append(sections, section)
+				continue
+			}
+			current = string(data)
+		}
+		section.Content, section.Err = applyHunks(current, f.Hunks)
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// patchPath strips the a/ or b/ prefix git puts on diff paths
+func patchPath(name string) string {
+	if i := strings.IndexByte(name, '\t'); i >= 0 {
+		name = name[:i] // diff -u appends a timestamp
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		return name[2:]
+	}
+	return name
+}
+
+// applyHunks applies unified diff hunks to content. Each hunk is tried at
+// its stated line first, then at the nearest place its context matches
+// exactly; a hunk whose context is nowhere in the file is an error. CRLF
+// files keep their line endings, and "\ No newline at end of file" markers
+// decide whether the result ends in a newline.
+func applyHunks(content string, hunks []Hunk) (string, error) {
+	eol := "\n"
+	if strings.Contains(content, "\r\n") {
+		eol = "\r\n"
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	finalNewline := content == "" || strings.HasSuffix(content, "\n")
+
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	offset := 0
+	for n, h := range hunks {
+		// Hunk.Lines may run past the hunk (e.g. "diff --git" lines of the
+		// next file), so stop once both sides are complete
+		var oldLines, newLines []string
+		prev := byte(0) // kind of the previous line: '-', '+' or ' '
+		for _, line := range h.Lines {
+			if strings.HasPrefix(line, "\\") {
+				// "\ No newline at end of file" applies to the line before
+				switch prev {
+				case '-':
+					finalNewline = true
+				case '+', ' ':
+					finalNewline = false
+				}
+				continue
+			}
+			if len(oldLines) >= h.SourceLen && len(newLines) >= h.TargetLen {
+				break
+			}
+			line = strings.TrimSuffix(line, "\r")
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldLines = append(oldLines, line[1:])
+				prev = '-'
+			case strings.HasPrefix(line, "+"):
+				newLines = append(newLines, line[1:])
+				prev = '+'
+			default:
+				// Context; editors often strip the space from empty lines
+				line = strings.TrimPrefix(line, " ")
+				oldLines = append(oldLines, line)
+				newLines = append(newLines, line)
+				prev = ' '
+			}
+		}
+
+		// A hunk that only adds lines starts after line SourceStart
+		base := h.SourceStart - 1
+		if len(oldLines) == 0 {
+			base = h.SourceStart
+		}
+		at := findHunk(lines, oldLines, base+offset)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (@@ -%d,%d) does not match the current file", n+1, h.SourceStart, h.SourceLen)
+		}
+
+		patched := append([]string{}, lines[:at]...)
+		patched = append(patched, newLines...)
+		lines = append(patched, lines[at+len(oldLines):]...)
+		offset = at - base + len(newLines) - len(oldLines)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	result := strings.Join(lines, eol)
+	if finalNewline {
+		result += eol
+	}
+	return result, nil
+}
+
+// findHunk returns the index nearest want where old appears in lines, or -1
+func findHunk(lines, old []string, want int) int {
+	matches := func(at int) bool {
+		if at < 0 || at+len(old) > len(lines) {
+			return false
+		}
+		for i, line := range old {
+			if lines[at+i] != line {
+				return false
+			}
+		}
+		return true
+	}
+
+	want = max(0, min(want, len(lines)))
+	for delta := 0; delta <= len(lines); delta++ {
+		if matches(want - delta) {
+			return want - delta
+		}
+		if delta > 0 && matches(want+delta) {
+			return want + delta
+		}
+	}
+	return -1
+}
+
+// handleSplitCommand writes each file section of the clipboard (or stdin with
+// "-") through writeFile, backing up files that already exist
+func handleSplitCommand(ctx context.Context, fromStdin bool, markerExpr string, comment string, dryRun bool) error {
+	var text string
+	if fromStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		text = string(data)
+	} else {
+		clip, err := getClipboardText()
+		if err != nil {
+			return err
+		}
+		text = clip
+	}
+	if strings.TrimSpace(text) == "" {
+		return errClipboardEmpty
+	}
+
+	var sections []splitSection
+	if markerExpr == "" && isUnifiedDiff(text) {
+		sections = splitByPatch(text)
+	} else {
+		if markerExpr == "" {
+			markerExpr = appConfig.SplitMarker
+		}
+		if markerExpr == "" {
+			markerExpr = defaultSplitMarker
+		}
+		marker, err := regexp.Compile(markerExpr)
+		if err != nil {
+			return fmt.Errorf("invalid split marker %q: %w", markerExpr, err)
+		}
+		if marker.NumSubexp() < 1 {
+			return fmt.Errorf("split marker %q needs a capture group for the file path", markerExpr)
+		}
+		sections = splitByMarker(text, marker)
+	}
+
+	if len(sections) == 0 {
+		return fmt.Errorf("no file sections found (expected lines like \"=== path/to/file ===\" or a unified diff)")
+	}
+
+	cwd, _ := os.Getwd()
+	if comment == "" {
+		comment = "pt split"
+	}
+
+	var written, failed []string
+	for _, section := range sections {
+		if ctx.Err() != nil {
+			fmt.Printf("%s⚠️  Interrupted, remaining sections were not written%s\n", ColorYellow, ColorReset)
+			break
+		}
+
+		target := filepath.Join(cwd, filepath.FromSlash(section.Path))
+		if rel, err := filepath.Rel(cwd, target); err != nil || filepath.IsAbs(section.Path) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			section.Err = fmt.Errorf("path is outside the current directory")
+		}
+		if section.Err != nil {
+			fmt.Printf("%s❌ %s: %v%s\n", ColorRed, section.Path, section.Err, ColorReset)
+			failed = append(failed, section.Path)
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("%s[dry-run]%s would write %s (%s)\n", ColorYellow, ColorReset, section.Path, formatSize(int64(len(section.Content))))
+			written = append(written, section.Path)
+			continue
+		}
+
+		fmt.Printf("\n%s✂️  %s%s\n", ColorCyan, section.Path, ColorReset)
+		if err := writeFile(target, section.Content, false, true, comment, false); err != nil {
+			fmt.Printf("%s❌ %s: %v%s\n", ColorRed, section.Path, err, ColorReset)
+			failed = append(failed, section.Path)
+			continue
+		}
+		written = append(written, section.Path)
+	}
+
+	verb := "Wrote"
+	if dryRun {
+		verb = "Would write"
+	}
+	fmt.Printf("\n%s✅ %s %d file(s)%s", ColorGreen, verb, len(written), ColorReset)
+	if len(failed) > 0 {
+		fmt.Printf(", %s%d failed%s", ColorRed, len(failed), ColorReset)
+	}
+	fmt.Println()
+	for _, path := range written {
+		fmt.Printf("   %s\n", path)
+	}
+
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d section(s) could not be written", len(failed), len(sections))
+	}
+	return nil
+}
+
+// ============================================================================
+// UTILITY FUNCTIONS
+// ============================================================================
+
+// defaultTimeLayout is how times are shown unless time_format says otherwise
+const defaultTimeLayout = "2006-01-02 15:04:05"
+
+// displayLocation is the zone times are shown and parsed in (use_utc)
+func displayLocation() *time.Location {
+	if appConfig.UseUTC {
+		return time.UTC
+	}
+	return time.Local
+}
+
+// timeLayout returns the configured time_format; "iso8601" and "rfc3339"
+// select RFC 3339, which carries the zone offset
+func timeLayout() string {
+	switch strings.ToLower(appConfig.TimeFormat) {
+	case "":
+		return defaultTimeLayout
+	case "iso8601", "iso", "rfc3339":
+		return time.RFC3339
+	}
+	return appConfig.TimeFormat
+}
+
+// formatTime renders a backup or file time for display, honoring
+// time_format and use_utc
+func formatTime(t time.Time) string {
+	return t.In(displayLocation()).Format(timeLayout())
+}
+
+// timeColumnWidth is the table column width formatTime needs
+func timeColumnWidth() int {
+	return max(utf8.RuneCountInString(formatTime(time.Now())), len("Modified"))
+}
 
 func formatSize(size int64) string {
 	const unit = 1024
@@ -3305,10 +8947,250 @@ func getDefaultConfig() *Config {
 		MaxFilenameLen:   DefaultMaxFilenameLen,
 		BackupDirName:    DefaultBackupDirName,
 		MaxSearchDepth:   DefaultMaxSearchDepth,
+		MaxWorkers:       DefaultMaxWorkers,
+		ClipboardRetries: DefaultClipboardRetries,
+		NotificationBatchMs: DefaultNotificationBatchMs,
+		MonitorThrottleMs: DefaultMonitorThrottleMs,
+	}
+}
+
+// configEnvVar names the environment variable that points pt at a specific
+// config file, bypassing the search paths below
+const configEnvVar = "PT_CONFIG"
+
+// configSource describes where appConfig was loaded from. loadConfig runs in
+// init() before --debug is parsed, so main logs it once the logger is ready.
+var configSource string
+
+// localConfigPath is the per-directory config merged over the global one, if any
+var localConfigPath string
+
+// configOrigins records which layer set each top-level config key; keys that
+// are missing still have their built-in default (pt config show --effective)
+var configOrigins = make(map[string]string)
+
+// recordConfigOrigins marks every top-level key present in a YAML config
+// file as coming from origin
+func recordConfigOrigins(data []byte, origin string) {
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) != nil || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return
+	}
+	mapping := root.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		configOrigins[mapping.Content[i].Value] = origin
+	}
+}
+
+// configEnvPrefix starts the environment variables that override single
+// config keys: max_backup_count is $PT_MAX_BACKUP_COUNT
+const configEnvPrefix = "PT_"
+
+// applyEnvOverrides sets config keys from $PT_<KEY> variables, over both
+// config files. Each value is decoded as the YAML scalar it would be in a
+// file, so parsing and the range checks that follow are the same. A value
+// that does not fit the key's type is logged and ignored.
+func applyEnvOverrides(config *Config) {
+	configType := reflect.TypeOf(*config)
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" || field.Type.Kind() == reflect.Struct {
+			continue
+		}
+
+		envName := configEnvPrefix + strings.ToUpper(key)
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+
+		node := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: key},
+			{Kind: yaml.ScalarNode, Value: value},
+		}}
+		merged := *config
+		if err := node.Decode(&merged); err != nil {
+			logger.Printf("Warning: ignoring $%s: %v", envName, err)
+			continue
+		}
+		*config = merged
+		configOrigins[key] = "env $" + envName
+		logger.Printf("Config %s set from $%s", key, envName)
+	}
+}
+
+// configFallback logs that a loaded value was rejected and records that the
+// key is back at its default
+func configFallback(key, reason string) {
+	logger.Printf("Warning: %s %s, using default", reason, key)
+	configOrigins[key] = fmt.Sprintf("default (%s value in %s ignored)", reason, configOrigins[key])
+}
+
+// configRule is a setting check shared by loadConfig, which logs a
+// rejected value and resets it to its default, and validateConfigFile,
+// which reports it
+type configRule struct {
+	key    string
+	reason string                 // why configFallback says the value was dropped
+	check  func(c *Config) string // what is wrong with the value, "" if nothing
+	reset  func(c *Config)
+}
+
+// intRule accepts a number setting within min - max; def replaces anything else
+func intRule(key string, field func(c *Config) *int, min, max, def int) configRule {
+	return configRule{
+		key:    key,
+		reason: "invalid",
+		check: func(c *Config) string {
+			if v := *field(c); v < min || v > max {
+				return fmt.Sprintf("%s %d out of range (%d - %d)", key, v, min, max)
+			}
+			return ""
+		},
+		reset: func(c *Config) { *field(c) = def },
+	}
+}
+
+// configRules are the value checks of every setting that has one
+var configRules = []configRule{
+	intRule("max_clipboard_size", func(c *Config) *int { return &c.MaxClipboardSize }, 1, 1024*1024*1024, DefaultMaxClipboardSize),
+	intRule("max_backup_count", func(c *Config) *int { return &c.MaxBackupCount }, 1, 10000, DefaultMaxBackupCount),
+	intRule("max_filename_length", func(c *Config) *int { return &c.MaxFilenameLen }, 1, 1000, DefaultMaxFilenameLen),
+	{
+		key:    "backup_dir_name",
+		reason: "empty",
+		check: func(c *Config) string {
+			if c.BackupDirName == "" {
+				return "backup_dir_name must not be empty"
+			}
+			return ""
+		},
+		reset: func(c *Config) { c.BackupDirName = DefaultBackupDirName },
+	},
+	intRule("max_search_depth", func(c *Config) *int { return &c.MaxSearchDepth }, 1, 100, DefaultMaxSearchDepth),
+	intRule("max_workers", func(c *Config) *int { return &c.MaxWorkers }, 0, 256, DefaultMaxWorkers),
+	intRule("tab_width", func(c *Config) *int { return &c.TabWidth }, 0, 16, 0),
+	intRule("clipboard_retries", func(c *Config) *int { return &c.ClipboardRetries }, 0, 10, DefaultClipboardRetries),
+	intRule("notification_batch_ms", func(c *Config) *int { return &c.NotificationBatchMs }, 0, 60000, DefaultNotificationBatchMs),
+	intRule("monitor_throttle_ms", func(c *Config) *int { return &c.MonitorThrottleMs }, 0, 600000, DefaultMonitorThrottleMs),
+	{
+		key:    "merge_tool",
+		reason: "unsupported",
+		check: func(c *Config) string {
+			if c.MergeTool != "" && len(diffTools[c.MergeTool].MergeArgs) == 0 {
+				return fmt.Sprintf("merge_tool %q is not a three-way merge tool (kdiff3, meld, bcompare, diffmerge, tkdiff, filemerge)", c.MergeTool)
+			}
+			return ""
+		},
+		reset: func(c *Config) { c.MergeTool = "" },
+	},
+	{
+		key:    "update_url",
+		reason: "invalid",
+		check: func(c *Config) string {
+			if c.UpdateURL != "" && !isHTTPURL(c.UpdateURL) {
+				return fmt.Sprintf("update_url %q is not an http(s) URL", c.UpdateURL)
+			}
+			return ""
+		},
+		reset: func(c *Config) { c.UpdateURL = "" },
+	},
+	{
+		key:    "notification_backend",
+		reason: "unsupported",
+		check: func(c *Config) string {
+			if backend := strings.ToLower(c.NotificationBackend); backend != "" && !contains(notificationBackends, backend) {
+				return fmt.Sprintf("notification_backend %q must be one of %s", c.NotificationBackend, strings.Join(notificationBackends, ", "))
+			}
+			return ""
+		},
+		reset: func(c *Config) { c.NotificationBackend = "" },
+	},
+	{
+		key:    "normalize_line_endings",
+		reason: "invalid",
+		check: func(c *Config) string {
+			if !validLineEndingMode(strings.ToLower(c.NormalizeLineEndings)) {
+				return fmt.Sprintf("normalize_line_endings %q must be off, lf or crlf", c.NormalizeLineEndings)
+			}
+			return ""
+		},
+		reset: func(c *Config) { c.NormalizeLineEndings = "" },
+	},
+}
+
+// localConfigNames are the per-directory config files looked up by
+// findLocalConfigFile, nearest directory first
+var localConfigNames = []string{".pt.yml", "pt.yml", ".pt.yaml", "pt.yaml"}
+
+// findLocalConfigFile walks up from the cwd to the project root (the first
+// directory holding the backup dir or .git) and returns the nearest local
+// config file. Outside a project only the cwd itself is checked.
+func findLocalConfigFile(backupDirName string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	var dirs []string
+	dir := cwd
+	for {
+		dirs = append(dirs, dir)
+
+		if info, err := os.Stat(filepath.Join(dir, backupDirName)); err == nil && info.IsDir() {
+			break
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			dirs = dirs[:1]
+			break
+		}
+		dir = parent
+	}
+
+	for _, d := range dirs {
+		for _, name := range localConfigNames {
+			candidate := filepath.Join(d, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+	}
+
+	return ""
+}
+
+// configPathFromEnv returns $PT_CONFIG if it names a readable regular file
+func configPathFromEnv() string {
+	envPath := os.Getenv(configEnvVar)
+	if envPath == "" {
+		return ""
+	}
+
+	info, err := os.Stat(envPath)
+	if err != nil || info.IsDir() {
+		return ""
+	}
+
+	f, err := os.Open(envPath)
+	if err != nil {
+		return ""
 	}
+	f.Close()
+
+	return envPath
 }
 
 func findConfigFile() string {
+    if envPath := configPathFromEnv(); envPath != "" {
+        return envPath
+    }
+
     configNames := []string{"pt.yml", "pt.yaml", ".pt.yml", ".pt.yaml"}
     
     var searchPaths []string
@@ -3473,50 +9355,76 @@ func findConfigFile() string {
 
 func loadConfig() *Config {
 	config := getDefaultConfig()
+	configOrigins = make(map[string]string)
 
 	configPath := findConfigFile()
-	if configPath == "" {
-		logger.Println("No config file found, using defaults")
-		return config
-	}
 
-	logger.Printf("Loading config from: %s", configPath)
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		logger.Printf("Warning: failed to read config file: %v, using defaults", err)
-		return config
+	switch {
+	case configPath != "" && configPath == os.Getenv(configEnvVar):
+		configSource = fmt.Sprintf("%s (from $%s)", configPath, configEnvVar)
+	case configPath != "":
+		configSource = fmt.Sprintf("%s (from search paths)", configPath)
+	default:
+		configSource = "built-in defaults (no config file found)"
 	}
-
-	err = yaml.Unmarshal(data, config)
-	if err != nil {
-		logger.Printf("Warning: failed to parse config file: %v, using defaults", err)
-		return config
+	if envPath := os.Getenv(configEnvVar); envPath != "" && envPath != configPath {
+		configSource += fmt.Sprintf("; $%s=%s ignored: not a readable file", configEnvVar, envPath)
 	}
 
-	if config.MaxClipboardSize <= 0 || config.MaxClipboardSize > 1024*1024*1024 {
-		logger.Printf("Warning: invalid max_clipboard_size, using default")
-		config.MaxClipboardSize = DefaultMaxClipboardSize
-	}
+	if configPath == "" {
+		logger.Println("No config file found, using defaults")
+	} else {
+		logger.Printf("Loading config from: %s", configPath)
 
-	if config.MaxBackupCount <= 0 || config.MaxBackupCount > 10000 {
-		logger.Printf("Warning: invalid max_backup_count, using default")
-		config.MaxBackupCount = DefaultMaxBackupCount
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			logger.Printf("Warning: failed to read config file: %v, using defaults", err)
+		} else if err := yaml.Unmarshal(data, config); err != nil {
+			logger.Printf("Warning: failed to parse config file: %v, using defaults", err)
+			config = getDefaultConfig()
+		} else if configPath == os.Getenv(configEnvVar) {
+			recordConfigOrigins(data, fmt.Sprintf("$%s %s", configEnvVar, configPath))
+		} else {
+			recordConfigOrigins(data, "global "+configPath)
+		}
 	}
 
-	if config.MaxFilenameLen <= 0 || config.MaxFilenameLen > 1000 {
-		logger.Printf("Warning: invalid max_filename_length, using default")
-		config.MaxFilenameLen = DefaultMaxFilenameLen
+	// Layer the nearest per-directory config over the global one. Unmarshalling
+	// into the populated struct only overwrites keys present in the local file.
+	// $PT_BACKUP_DIR_NAME already decides which directory marks the project.
+	backupDirName := config.BackupDirName
+	if name := os.Getenv(configEnvPrefix + "BACKUP_DIR_NAME"); name != "" {
+		backupDirName = name
+	}
+	localConfigPath = findLocalConfigFile(backupDirName)
+	if localConfigPath != "" && localConfigPath != configPath {
+		data, err := os.ReadFile(localConfigPath)
+		if err == nil {
+			merged := *config
+			if err = yaml.Unmarshal(data, &merged); err == nil {
+				*config = merged
+				recordConfigOrigins(data, "local "+localConfigPath)
+				configSource = fmt.Sprintf("local %s > global %s", localConfigPath, configSource)
+				logger.Printf("Merged local config over global: %s", localConfigPath)
+			}
+		}
+		if err != nil {
+			logger.Printf("Warning: ignoring local config %s: %v", localConfigPath, err)
+			localConfigPath = ""
+		}
+	} else {
+		localConfigPath = ""
 	}
 
-	if config.BackupDirName == "" {
-		logger.Printf("Warning: empty backup_dir_name, using default")
-		config.BackupDirName = DefaultBackupDirName
-	}
+	applyEnvOverrides(config)
 
-	if config.MaxSearchDepth <= 0 || config.MaxSearchDepth > 100 {
-		logger.Printf("Warning: invalid max_search_depth, using default")
-		config.MaxSearchDepth = DefaultMaxSearchDepth
+	config.NotificationBackend = strings.ToLower(config.NotificationBackend)
+	config.NormalizeLineEndings = strings.ToLower(config.NormalizeLineEndings)
+	for _, rule := range configRules {
+		if rule.check(config) != "" {
+			configFallback(rule.key, rule.reason)
+			rule.reset(config)
+		}
 	}
 
 	logger.Printf("Config loaded successfully: clipboard=%dMB, backups=%d, depth=%d",
@@ -3551,9 +9459,121 @@ func generateSampleConfig(path string) error {
 	return nil
 }
 
+// yamlKeysOf returns the yaml keys a struct type accepts
+func yamlKeysOf(t reflect.Type) map[string]reflect.Type {
+	keys := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		keys[name] = field.Type
+	}
+	return keys
+}
+
+// checkUnknownKeys reports mapping keys in node that t has no field for,
+// descending into nested structs (e.g. menu_icons)
+func checkUnknownKeys(node *yaml.Node, t reflect.Type, prefix string) []string {
+	var problems []string
+	if node.Kind != yaml.MappingNode {
+		return problems
+	}
+
+	known := yamlKeysOf(t)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		fieldType, ok := known[key.Value]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("line %d: unknown key '%s%s'", key.Line, prefix, key.Value))
+			continue
+		}
+		if fieldType.Kind() == reflect.Struct {
+			problems = append(problems, checkUnknownKeys(value, fieldType, prefix+key.Value+".")...)
+		}
+	}
+	return problems
+}
+
+// trayIconExists resolves a tray_icon value the same way getTrayIconData
+// does: as given, then relative to the executable, then to the cwd
+func trayIconExists(icon string) bool {
+	candidates := []string{icon}
+	if exePath, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exePath), icon))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		candidates = append(candidates, filepath.Join(cwd, icon))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateConfigFile strictly checks a config file and returns every problem
+// found. Unlike loadConfig, nothing falls back to defaults silently.
+func validateConfigFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []string{fmt.Sprintf("invalid YAML: %v", err)}, nil
+	}
+	if len(root.Content) == 0 {
+		// Empty file: every value uses its default
+		return nil, nil
+	}
+
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return []string{fmt.Sprintf("line %d: top level must be a mapping of key: value", doc.Line)}, nil
+	}
+
+	problems := checkUnknownKeys(doc, reflect.TypeOf(Config{}), "")
+
+	config := getDefaultConfig()
+	if err := doc.Decode(config); err != nil {
+		// Type errors (e.g. a string where a number is expected)
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			problems = append(problems, typeErr.Errors...)
+		} else {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	for _, rule := range configRules {
+		if problem := rule.check(config); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if config.NormalizeStoredLineEndings && !lineEndingModeOn(strings.ToLower(config.NormalizeLineEndings)) {
+		problems = append(problems, "normalize_stored_line_endings has no effect without normalize_line_endings: lf or crlf")
+	}
+
+	if config.TrayIcon != "" && !trayIconExists(config.TrayIcon) {
+		problems = append(problems, fmt.Sprintf("tray_icon file not found: %s", config.TrayIcon))
+	}
+	if config.MenuIconsDir != "" {
+		if info, err := os.Stat(config.MenuIconsDir); err != nil || !info.IsDir() {
+			problems = append(problems, fmt.Sprintf("menu_icons_dir is not a directory: %s", config.MenuIconsDir))
+		}
+	}
+
+	return problems, nil
+}
+
 func handleConfigCommand(args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("config subcommand required: 'init', 'show', or 'path'")
+		return fmt.Errorf("config subcommand required: 'init', 'show', 'path', 'validate', or 'edit'")
 	}
 
 	subcommand := args[0]
@@ -3584,86 +9604,565 @@ func handleConfigCommand(args []string) error {
 			return fmt.Errorf("failed to generate config: %w", err)
 		}
 
-		fmt.Printf("✅ Sample config file created: %s%s%s\n", ColorGreen, configPath, ColorReset)
-		fmt.Println("📝 Edit this file to customize PT behavior")
+		fmt.Printf("✅ Sample config file created: %s%s%s\n", ColorGreen, configPath, ColorReset)
+		fmt.Println("📝 Edit this file to customize PT behavior")
+
+	case "show":
+		if len(args) > 1 && args[1] == "--effective" {
+			printEffectiveConfig()
+			return nil
+		}
+		if len(args) > 1 && args[1] == "--yaml" {
+			// Plain output so it can be redirected into a pt.yml. An unset
+			// update_check means enabled; spell that out.
+			effective := *appConfig
+			if effective.UpdateCheck == nil {
+				enabled := true
+				effective.UpdateCheck = &enabled
+			}
+			data, err := yaml.Marshal(&effective)
+			if err != nil {
+				return fmt.Errorf("failed to encode config: %w", err)
+			}
+			fmt.Printf("# pt configuration: effective values (pt config show --yaml)\n")
+			fmt.Print(string(data))
+			return nil
+		}
+
+		fmt.Printf("\n%sCurrent PT Configuration:%s\n\n", ColorBold, ColorReset)
+		fmt.Printf("%sMax Clipboard Size:%s %d bytes (%.1f MB)\n",
+			ColorCyan, ColorReset, appConfig.MaxClipboardSize, float64(appConfig.MaxClipboardSize)/(1024*1024))
+		fmt.Printf("%sMax Backup Count:%s %d\n", ColorCyan, ColorReset, appConfig.MaxBackupCount)
+		fmt.Printf("%sMax Filename Length:%s %d characters\n", ColorCyan, ColorReset, appConfig.MaxFilenameLen)
+		fmt.Printf("%sBackup Directory:%s %s/ (Git-like structure)\n", ColorCyan, ColorReset, appConfig.BackupDirName)
+		fmt.Printf("%sMax Search Depth:%s %d levels\n", ColorCyan, ColorReset, appConfig.MaxSearchDepth)
+		fmt.Printf("%sFollow Symlinks:%s %v\n", ColorCyan, ColorReset, appConfig.FollowSymlinks)
+		if appConfig.Theme != "" {
+			fmt.Printf("%sTheme:%s %s\n", ColorCyan, ColorReset, appConfig.Theme)
+		}
+		if appConfig.OnChangeCmd != "" {
+			fmt.Printf("%sOn Change Command:%s %s\n", ColorCyan, ColorReset, appConfig.OnChangeCmd)
+		}
+		if appConfig.MaxWorkers > 0 {
+			fmt.Printf("%sMax Workers:%s %d\n\n", ColorCyan, ColorReset, appConfig.MaxWorkers)
+		} else {
+			fmt.Printf("%sMax Workers:%s auto (%d CPUs)\n\n", ColorCyan, ColorReset, runtime.NumCPU())
+		}
+
+		configPath := findConfigFile()
+		if configPath != "" {
+			fmt.Printf("%sConfig loaded from:%s %s\n", ColorGray, ColorReset, configPath)
+		} else {
+			fmt.Printf("%sUsing default configuration (no config file found)%s\n", ColorGray, ColorReset)
+		}
+		if localConfigPath != "" {
+			fmt.Printf("%sLocal overrides from:%s %s\n", ColorGray, ColorReset, localConfigPath)
+		}
+
+	case "path":
+		configPath := findConfigFile()
+		if configPath != "" {
+			fmt.Printf("📄 Config file: %s%s%s\n", ColorGreen, configPath, ColorReset)
+			if configPath == os.Getenv(configEnvVar) {
+				fmt.Printf("%s   (set via $%s)%s\n", ColorGray, configEnvVar, ColorReset)
+			}
+		} else if localConfigPath == "" {
+			fmt.Printf("%sℹ️  No config file found%s\n", ColorGray, ColorReset)
+			fmt.Println("\nSearched in:")
+			fmt.Printf("  • $%s (if set to a readable file)\n", configEnvVar)
+			fmt.Println("  • ./pt.yml or ./pt.yaml")
+			fmt.Println("  • ~/.config/pt/pt.yml or ~/.config/pt/pt.yaml")
+			fmt.Println("  • ~/pt.yml or ~/pt.yaml")
+			fmt.Printf("\n%sCreate one with:%s pt config init\n", ColorCyan, ColorReset)
+		}
+		if localConfigPath != "" {
+			fmt.Printf("📄 Local overrides: %s%s%s\n", ColorGreen, localConfigPath, ColorReset)
+		}
+
+	case "validate":
+		var configPath string
+		if len(args) > 1 {
+			configPath = args[1]
+		} else {
+			configPath = findConfigFile()
+			if configPath == "" {
+				return fmt.Errorf("no config file found to validate: %w", errNotFound)
+			}
+		}
+
+		problems, err := validateConfigFile(configPath)
+		if err != nil {
+			return err
+		}
+
+		if len(problems) == 0 {
+			fmt.Printf("✅ Config is valid: %s%s%s\n", ColorGreen, configPath, ColorReset)
+			return nil
+		}
+
+		fmt.Printf("%s❌ %s has %d problem(s):%s\n", ColorRed, configPath, len(problems), ColorReset)
+		for _, problem := range problems {
+			fmt.Printf("  • %s\n", problem)
+		}
+		return fmt.Errorf("config validation failed")
+
+	case "edit":
+		var configPath string
+		if len(args) > 1 {
+			configPath = args[1]
+		} else {
+			configPath = findConfigFile()
+		}
+		if configPath == "" {
+			configPath = "pt.yml"
+		}
+
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			if err := generateSampleConfig(configPath); err != nil {
+				return fmt.Errorf("failed to generate config: %w", err)
+			}
+			fmt.Printf("✅ Sample config file created: %s%s%s\n", ColorGreen, configPath, ColorReset)
+		}
+
+		if err := editFile(configPath); err != nil {
+			return err
+		}
+
+		problems, err := validateConfigFile(configPath)
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			fmt.Printf("✅ Config is valid: %s%s%s\n", ColorGreen, configPath, ColorReset)
+			return nil
+		}
+
+		fmt.Printf("%s❌ %s has %d problem(s):%s\n", ColorRed, configPath, len(problems), ColorReset)
+		for _, problem := range problems {
+			fmt.Printf("  • %s\n", problem)
+		}
+		fmt.Printf("%sRun 'pt config edit' again to fix them; invalid values fall back to defaults.%s\n", ColorGray, ColorReset)
+		return fmt.Errorf("config validation failed")
+
+	default:
+		return fmt.Errorf("unknown config subcommand: %s (use 'init', 'show', 'path', 'validate', or 'edit')", subcommand)
+	}
+
+	return nil
+}
+
+// printEffectiveConfig lists every setting with its resolved value and the
+// layer it came from: built-in default, $PT_CONFIG, global file, local file
+// or command-line flag
+func printEffectiveConfig() {
+	type row struct{ key, value, origin string }
+	var rows []row
+
+	var collect func(v reflect.Value, prefix, parentOrigin string)
+	collect = func(v reflect.Value, prefix, parentOrigin string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			key := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if key == "" || key == "-" {
+				continue
+			}
+
+			origin := parentOrigin
+			if prefix == "" {
+				origin = configOrigins[key]
+				if origin == "" {
+					origin = "default"
+				}
+			}
+
+			field := v.Field(i)
+			if field.Kind() == reflect.Struct {
+				collect(field, prefix+key+".", origin)
+				continue
+			}
+
+			value := "(unset)"
+			switch {
+			case field.Kind() == reflect.Ptr && !field.IsNil():
+				value = fmt.Sprint(field.Elem().Interface())
+			case field.Kind() == reflect.String:
+				value = fmt.Sprintf("%q", field.String())
+			case field.Kind() != reflect.Ptr:
+				value = fmt.Sprint(field.Interface())
+			}
+			rows = append(rows, row{prefix + key, value, origin})
+		}
+	}
+	collect(reflect.ValueOf(*appConfig), "", "")
+
+	// Flags that override a setting for this run only
+	for i := range rows {
+		if rows[i].key == "follow_symlinks" && followSymlinks && !appConfig.FollowSymlinks {
+			rows[i].value, rows[i].origin = "true", "flag --follow-symlinks"
+		}
+	}
+
+	keyWidth, valueWidth := 0, 0
+	for _, r := range rows {
+		keyWidth = max(keyWidth, len(r.key))
+		valueWidth = max(valueWidth, min(len(r.value), 40))
+	}
+
+	fmt.Printf("\n%sEffective PT Configuration:%s\n\n", ColorBold, ColorReset)
+	for _, r := range rows {
+		color := ColorGreen
+		if r.origin == "default" {
+			color = ColorGray
+		} else if strings.HasPrefix(r.origin, "default (") {
+			color = ColorYellow
+		}
+		fmt.Printf("  %s%-*s%s  %-*s  %s%s%s\n",
+			ColorCyan, keyWidth, r.key, ColorReset,
+			valueWidth, r.value,
+			color, r.origin, ColorReset)
+	}
+	fmt.Printf("\n%sPrecedence: flag > $%s<KEY> > local file > global file or $%s > default%s\n", ColorGray, configEnvPrefix, configEnvVar, ColorReset)
+}
+
+// newBackupMetadata describes a backup taken now of originalFile
+func newBackupMetadata(comment, originalFile string, size int64, modTime time.Time) *BackupMetadata {
+	return &BackupMetadata{
+		Comment:         comment,
+		Timestamp:       time.Now(),
+		Size:            size,
+		Original:        originalFile,
+		OriginalModTime: modTime,
+		CommitID:        activeCommitID,
+	}
+}
+
+// writeBackupMetadata (re)writes the sidecar of an existing backup as-is
+func writeBackupMetadata(backupPath string, metadata *BackupMetadata) error {
+	metadataPath := backupPath + ".meta.json"
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	err = os.WriteFile(metadataPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	updateBackupIndex(backupPath, metadata)
+	return nil
+}
+
+// backupIndexFile consolidates the metadata sidecars of a store (pt gc) so
+// history listings need a stat per backup instead of a read and a parse. It
+// sits next to the per-file backup folders, so localStore.Dir never hands
+// out its name (see storeReservedNames).
+const backupIndexFile = ".index.json"
+
+// backupIndexJournal holds the index updates made since the last pt gc, one
+// JSON line per written sidecar. Appending keeps a backup O(1) however big
+// the index is; readers replay the journal over the index and gc folds it in.
+const backupIndexJournal = ".index.journal"
+
+// legacyBackupIndexFiles are the names the index used to have, which a
+// backed up file of the same name could collide with. pt gc removes them.
+var legacyBackupIndexFiles = []string{"index.json", "index.journal"}
+
+// backupJournalLine is one line of backupIndexJournal
+type backupJournalLine struct {
+	Key   string           `json:"key"`
+	Entry backupIndexEntry `json:"entry"`
+}
+
+// backupIndexEntry is the cached copy of one sidecar. The sidecar's size and
+// mtime are recorded too: an entry whose sidecar has been rewritten since
+// (by an older pt, a move or a fix) no longer matches and is ignored.
+type backupIndexEntry struct {
+	Metadata    BackupMetadata `json:"metadata"`
+	SidecarSize int64          `json:"sidecar_size"`
+	SidecarTime time.Time      `json:"sidecar_mtime"`
+}
+
+type backupIndex struct {
+	Version int                         `json:"version"`
+	Entries map[string]backupIndexEntry `json:"entries"` // keyed by backup path relative to the store
+}
+
+// Indexes read so far, by store directory. A nil value means the store has
+// no index (pt gc was never run there), which is also cached.
+var (
+	backupIndexMu sync.Mutex
+	backupIndexes = make(map[string]*backupIndex)
+)
+
+// backupStoreOf returns the store directory holding backupPath and the
+// backup's key in that store's index
+func backupStoreOf(backupPath string) (string, string) {
+	absPath, err := filepath.Abs(backupPath)
+	if err != nil {
+		return "", ""
+	}
+	for dir := filepath.Dir(absPath); ; {
+		if filepath.Base(dir) == appConfig.BackupDirName {
+			rel, err := filepath.Rel(dir, absPath)
+			if err != nil {
+				return "", ""
+			}
+			return dir, filepath.ToSlash(rel)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// readBackupIndex reads the index of ptDir from disk; nil if there is none
+func readBackupIndex(ptDir string) *backupIndex {
+	data, err := os.ReadFile(filepath.Join(ptDir, backupIndexFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Printf("Warning: failed to read %s: %v", backupIndexFile, err)
+		}
+		return nil
+	}
+
+	var idx backupIndex
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Entries == nil {
+		logger.Printf("Warning: ignoring unreadable %s in %s: %v", backupIndexFile, ptDir, err)
+		return nil
+	}
+
+	// Later lines win; a line torn by a crash mid-append is skipped
+	journal, err := os.Open(filepath.Join(ptDir, backupIndexJournal))
+	if err != nil {
+		return &idx
+	}
+	defer journal.Close()
+	scanner := bufio.NewScanner(journal)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line backupJournalLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.Key == "" {
+			continue
+		}
+		idx.Entries[line.Key] = line.Entry
+	}
+	return &idx
+}
+
+// writeBackupIndex replaces the index of ptDir. It goes through a temp file
+// so a concurrent reader never sees half of it.
+func writeBackupIndex(ptDir string, idx *backupIndex) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(ptDir, ".index-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	trackTempFile(tmp.Name())
+	defer untrackTempFile(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		// CreateTemp makes it 0600; readable like the sidecars it caches
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(ptDir, backupIndexFile))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// indexedMetadata returns the indexed metadata of backupPath if the index
+// entry still matches the sidecar described by sidecar
+func indexedMetadata(backupPath string, sidecar os.FileInfo) (*BackupMetadata, bool) {
+	ptDir, key := backupStoreOf(backupPath)
+	if ptDir == "" {
+		return nil, false
+	}
+
+	backupIndexMu.Lock()
+	idx, loaded := backupIndexes[ptDir]
+	if !loaded {
+		idx = readBackupIndex(ptDir)
+		backupIndexes[ptDir] = idx
+	}
+	var entry backupIndexEntry
+	found := false
+	if idx != nil {
+		entry, found = idx.Entries[key]
+	}
+	backupIndexMu.Unlock()
+
+	if !found || entry.SidecarSize != sidecar.Size() || !entry.SidecarTime.Equal(sidecar.ModTime()) {
+		return nil, false
+	}
+	metadata := entry.Metadata
+	return &metadata, true
+}
+
+// updateBackupIndex records freshly written metadata in the store's index
+// by appending it to the journal. Stores without an index are left alone;
+// pt gc creates one.
+func updateBackupIndex(backupPath string, metadata *BackupMetadata) {
+	ptDir, key := backupStoreOf(backupPath)
+	if ptDir == "" {
+		return
+	}
+	if info, err := os.Stat(filepath.Join(ptDir, backupIndexFile)); err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	sidecar, err := os.Stat(backupPath + ".meta.json")
+	if err != nil {
+		return
+	}
+
+	entry := backupIndexEntry{
+		Metadata:    *metadata,
+		SidecarSize: sidecar.Size(),
+		SidecarTime: sidecar.ModTime(),
+	}
+	data, err := json.Marshal(backupJournalLine{Key: key, Entry: entry})
+	if err != nil {
+		logger.Printf("Warning: failed to marshal index entry: %v", err)
+		return
+	}
+
+	backupIndexMu.Lock()
+	defer backupIndexMu.Unlock()
+
+	// One write of a whole line, so concurrent pt processes don't interleave
+	journal, err := os.OpenFile(filepath.Join(ptDir, backupIndexJournal), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Printf("Warning: failed to open index journal: %v", err)
+		return
+	}
+	_, err = journal.Write(append(data, '\n'))
+	if closeErr := journal.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Printf("Warning: failed to append to index journal: %v", err)
+		return
+	}
+	if idx := backupIndexes[ptDir]; idx != nil {
+		idx.Entries[key] = entry
+	}
+}
+
+func loadBackupMetadata(backupPath string) (string, error) {
+	metadata, err := readBackupMetadata(backupPath)
+	if err != nil || metadata == nil {
+		return "", err
+	}
 
-	case "show":
-		fmt.Printf("\n%sCurrent PT Configuration:%s\n\n", ColorBold, ColorReset)
-		fmt.Printf("%sMax Clipboard Size:%s %d bytes (%.1f MB)\n",
-			ColorCyan, ColorReset, appConfig.MaxClipboardSize, float64(appConfig.MaxClipboardSize)/(1024*1024))
-		fmt.Printf("%sMax Backup Count:%s %d\n", ColorCyan, ColorReset, appConfig.MaxBackupCount)
-		fmt.Printf("%sMax Filename Length:%s %d characters\n", ColorCyan, ColorReset, appConfig.MaxFilenameLen)
-		fmt.Printf("%sBackup Directory:%s %s/ (Git-like structure)\n", ColorCyan, ColorReset, appConfig.BackupDirName)
-		fmt.Printf("%sMax Search Depth:%s %d levels\n\n", ColorCyan, ColorReset, appConfig.MaxSearchDepth)
+	return metadata.Comment, nil
+}
 
-		configPath := findConfigFile()
-		if configPath != "" {
-			fmt.Printf("%sConfig loaded from:%s %s\n", ColorGray, ColorReset, configPath)
-		} else {
-			fmt.Printf("%sUsing default configuration (no config file found)%s\n", ColorGray, ColorReset)
-		}
+// loadBackupDirConfig reads the per-file settings of a backup directory.
+// A missing or unreadable file yields the zero config (use global settings).
+func loadBackupDirConfig(backupDir string) BackupDirConfig {
+	var config BackupDirConfig
 
-	case "path":
-		configPath := findConfigFile()
-		if configPath != "" {
-			fmt.Printf("📄 Config file: %s%s%s\n", ColorGreen, configPath, ColorReset)
-		} else {
-			fmt.Printf("%sℹ️  No config file found%s\n", ColorGray, ColorReset)
-			fmt.Println("\nSearched in:")
-			fmt.Println("  • ./pt.yml or ./pt.yaml")
-			fmt.Println("  • ~/.config/pt/pt.yml or ~/.config/pt/pt.yaml")
-			fmt.Println("  • ~/pt.yml or ~/pt.yaml")
-			fmt.Printf("\n%sCreate one with:%s pt config init\n", ColorCyan, ColorReset)
-		}
+	data, err := os.ReadFile(filepath.Join(backupDir, backupDirConfigFile))
+	if err != nil {
+		return config
+	}
 
-	default:
-		return fmt.Errorf("unknown config subcommand: %s (use 'init', 'show', or 'path')", subcommand)
+	if err := json.Unmarshal(data, &config); err != nil {
+		logger.Printf("Warning: failed to parse %s in %s: %v", backupDirConfigFile, backupDir, err)
+		return BackupDirConfig{}
 	}
 
-	return nil
+	return config
 }
 
-func saveBackupMetadata(backupPath, comment, originalFile string, size int64) error {
-	metadataPath := backupPath + ".meta.json"
+// maxBackupsFor returns how many backups to keep in a backup directory: its
+// --keep override if set, otherwise the global max_backup_count
+func maxBackupsFor(backupDir string) int {
+	if keep := loadBackupDirConfig(backupDir).Keep; keep > 0 {
+		return keep
+	}
+	return appConfig.MaxBackupCount
+}
 
-	metadata := BackupMetadata{
-		Comment:   comment,
-		Timestamp: time.Now(),
-		Size:      size,
-		Original:  originalFile,
+// setBackupKeep records a per-file retention override (pt <file> --keep N)
+func setBackupKeep(filePath string, keep int) error {
+	ptRoot, err := ensurePTDir(filePath)
+	if err != nil {
+		return err
 	}
 
-	data, err := json.MarshalIndent(metadata, "", "  ")
+	backupDir, err := getBackupDir(ptRoot, filePath)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return err
 	}
 
-	err = os.WriteFile(metadataPath, data, 0644)
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup subdirectory: %w", err)
+	}
+
+	config := loadBackupDirConfig(backupDir)
+	config.Keep = keep
+
+	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to write metadata: %w", err)
+		return fmt.Errorf("failed to marshal backup settings: %w", err)
 	}
 
+	if err := os.WriteFile(filepath.Join(backupDir, backupDirConfigFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup settings: %w", err)
+	}
+
+	logger.Printf("Per-file backup limit for %s set to %d", filePath, keep)
 	return nil
 }
 
-func loadBackupMetadata(backupPath string) (string, error) {
+// readBackupMetadata loads the full metadata sidecar of a backup, from the
+// store's index when it holds an up-to-date copy.
+// Returns nil without error when the backup has no sidecar.
+func readBackupMetadata(backupPath string) (*BackupMetadata, error) {
 	metadataPath := backupPath + ".meta.json"
 
+	sidecar, err := os.Stat(metadataPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if metadata, ok := indexedMetadata(backupPath, sidecar); ok {
+		return metadata, nil
+	}
+
 	data, err := os.ReadFile(metadataPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", nil
+			return nil, nil
 		}
-		return "", err
+		return nil, err
 	}
 
 	var metadata BackupMetadata
 	err = json.Unmarshal(data, &metadata)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return metadata.Comment, nil
+	return &metadata, nil
 }
 
 // loadGitIgnoreAndPtIgnore loads patterns from .gitignore and .ptignore in the root path
@@ -3717,9 +10216,82 @@ func loadGitIgnoreAndPtIgnore(rootPath string) (*GitIgnore, error) {
         }
     }
 
+	gi.root = rootPath
+	gi.includes = loadPtInclude(rootPath)
+
 	return gi, nil
 }
 
+// loadPtInclude reads the .ptinclude whitelist in rootPath. A missing file
+// yields nil, meaning every file is included.
+func loadPtInclude(rootPath string) []string {
+	data, err := os.ReadFile(filepath.Join(rootPath, ".ptinclude"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Printf("Warning: failed to read .ptinclude: %v", err)
+		}
+		return nil
+	}
+
+	var includes []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		includes = append(includes, filepath.ToSlash(line))
+	}
+	return includes
+}
+
+// isIncluded reports whether a file passes the .ptinclude whitelist.
+// Directories are always traversed so nested matches can be found.
+// Patterns ending in / take a whole directory, patterns with a slash are
+// matched against the path relative to the root, and the rest against the
+// basename.
+func (gi *GitIgnore) isIncluded(path string, isDir bool) bool {
+	if gi == nil || len(gi.includes) == 0 || isDir {
+		return true
+	}
+
+	relPath := path
+	if gi.root != "" {
+		absPath, _ := filepath.Abs(path)
+		absRoot, _ := filepath.Abs(gi.root)
+		if rel, err := filepath.Rel(absRoot, absPath); err == nil {
+			relPath = rel
+		}
+	}
+	relPath = filepath.ToSlash(relPath)
+	baseName := filepath.Base(path)
+
+	for _, pattern := range gi.includes {
+		pattern = strings.TrimPrefix(pattern, "/")
+		if strings.HasSuffix(pattern, "/") {
+			if strings.HasPrefix(relPath, pattern) {
+				return true
+			}
+			continue
+		}
+
+		if strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(pattern, relPath); matched {
+				return true
+			}
+			if strings.HasPrefix(relPath, pattern+"/") {
+				return true
+			}
+			continue
+		}
+
+		if matched, _ := filepath.Match(pattern, baseName); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (gi *GitIgnore) shouldIgnore(path string, isDir bool) bool {
 	baseName := filepath.Base(path)
 	
@@ -3809,6 +10381,29 @@ func findPTRoot(startPath string) (string, error) {
 	return "", nil
 }
 
+// predictPTDir returns the .pt directory ensurePTDir would use for filePath,
+// without creating anything (used by move --dry-run)
+func predictPTDir(filePath string) (string, error) {
+	absDir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return "", err
+	}
+
+	ptRootResult, err := findPTRoot(absDir)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case ptRootResult == "":
+		return filepath.Join(absDir, appConfig.BackupDirName), nil
+	case filepath.Base(ptRootResult) == appConfig.BackupDirName:
+		return ptRootResult, nil
+	default:
+		return filepath.Join(ptRootResult, appConfig.BackupDirName), nil
+	}
+}
+
 func findGitRoot(startPath string) string {
 	current := startPath
 	absPath, err := filepath.Abs(current)
@@ -3877,7 +10472,7 @@ func ensurePTDir(filePath string) (string, error) {
 			cwd, _ := os.Getwd()
 			relPath, _ := filepath.Rel(cwd, ptRootResult)
 			if relPath != "" && relPath != "." {
-				fmt.Printf("📁 Using existing %s from: %s%s/%s", appConfig.BackupDirName, ColorCyan, relPath, ColorReset)
+				fmt.Fprintf(infoOut, "📁 Using existing %s from: %s%s/%s", appConfig.BackupDirName, ColorCyan, relPath, ColorReset)
 			}
 			return ptRootResult, nil
 		} else {
@@ -3898,7 +10493,7 @@ func ensurePTDir(filePath string) (string, error) {
 					return "", fmt.Errorf("failed to create %s directory: %w", appConfig.BackupDirName, err)
 				}
 				logger.Printf("Created %s directory: %s", appConfig.BackupDirName, ptDir)
-				fmt.Printf("📁 Created %s directory: %s", appConfig.BackupDirName, ptDir)
+				fmt.Fprintf(infoOut, "📁 Created %s directory: %s", appConfig.BackupDirName, ptDir)
 
 				// Set hidden attribute on Windows
 				if runtime.GOOS == "windows" {
@@ -3941,7 +10536,7 @@ func ensurePTDir(filePath string) (string, error) {
 				return "", fmt.Errorf("failed to create %s directory: %w", appConfig.BackupDirName, err)
 			}
 			logger.Printf("Created %s directory: %s", appConfig.BackupDirName, ptDir)
-			fmt.Printf("📁 Created %s directory: %s", appConfig.BackupDirName, ptDir)
+			fmt.Fprintf(infoOut, "📁 Created %s directory: %s", appConfig.BackupDirName, ptDir)
 
 			// Set hidden attribute on Windows
 			if runtime.GOOS == "windows" {
@@ -4121,40 +10716,417 @@ func findFilesWithRegex(pattern string) ([]string, error) {
 // 	return windows.SetFileAttributes(ptr, newAttributes)
 // }
 
-// createPTGitignore creates/updates .gitignore to exclude .pt directory
-func createPTGitignore(dir string) {
-	gitignorePath := filepath.Join(dir, ".gitignore")
-	
-	// Check if .gitignore exists
-	content, err := os.ReadFile(gitignorePath)
-	if err != nil && !os.IsNotExist(err) {
-		return // Skip on error
+// createPTGitignore creates/updates .gitignore to exclude .pt directory
+func createPTGitignore(dir string) {
+	gitignorePath := filepath.Join(dir, ".gitignore")
+	
+	// Check if .gitignore exists
+	content, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return // Skip on error
+	}
+
+	gitignoreContent := string(content)
+	
+	// Check if .pt is already ignored
+	ptPattern := appConfig.BackupDirName + "/"
+	if strings.Contains(gitignoreContent, ptPattern) || strings.Contains(gitignoreContent, appConfig.BackupDirName+"\n") {
+		return // Already ignored
+	}
+
+	// Append .pt to .gitignore
+	f, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return // Skip on error
+	}
+	defer f.Close()
+
+	// Add newline if file doesn't end with one
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		f.WriteString("\n")
+	}
+
+	f.WriteString("# PT backup directory\n")
+	f.WriteString(ptPattern + "\n")
+
+	logger.Printf("Added %s to .gitignore", ptPattern)
+}
+
+// handleInitCommand creates the backup store up front instead of on the
+// first write (pt init). By default it goes where ensurePTDir would put it,
+// the git root if there is one; with here it goes in the current directory.
+func handleInitCommand(here bool) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	baseDir := cwd
+	if !here {
+		if gitRoot := findGitRoot(cwd); gitRoot != "" {
+			baseDir = gitRoot
+		}
+	}
+	ptDir := filepath.Join(baseDir, appConfig.BackupDirName)
+
+	// The store that files here resolved to until now, if any
+	previous, _ := findPTRoot(cwd)
+	if filepath.Base(previous) != appConfig.BackupDirName {
+		previous = ""
+	}
+
+	info, err := os.Stat(ptDir)
+	switch {
+	case err == nil && !info.IsDir():
+		return fmt.Errorf("%s exists but is not a directory: %s", appConfig.BackupDirName, ptDir)
+	case err == nil:
+		fmt.Printf("📁 Reinitialized existing %s directory: %s%s%s\n", appConfig.BackupDirName, ColorCyan, ptDir, ColorReset)
+	case os.IsNotExist(err):
+		if err := os.Mkdir(ptDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s directory: %w", appConfig.BackupDirName, err)
+		}
+		logger.Printf("Created %s directory: %s", appConfig.BackupDirName, ptDir)
+		fmt.Printf("📁 Initialized empty %s directory: %s%s%s\n", appConfig.BackupDirName, ColorCyan, ptDir, ColorReset)
+		if previous != "" && previous != ptDir {
+			fmt.Printf("%s⚠️  Files under %s now use this store instead of %s%s\n",
+				ColorYellow, baseDir, previous, ColorReset)
+		}
+	default:
+		return fmt.Errorf("failed to check %s directory: %w", appConfig.BackupDirName, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		if err := setWindowsHiddenAttribute(ptDir); err != nil {
+			logger.Printf("Warning: failed to set hidden attribute on Windows: %v", err)
+		}
+	}
+
+	createPTGitignore(baseDir)
+	return nil
+}
+
+// doctorCheck is one line of the pt doctor checklist. A check can pass,
+// warn (works, but probably not as intended) or fail; hint says how to fix it.
+type doctorCheck struct {
+	name   string
+	status string // "ok", "warn" or "fail"
+	detail string
+	hint   string
+}
+
+// handleDoctorCommand checks the environment pt depends on (pt doctor):
+// clipboard, diff tools, config, backup store location and terminal.
+// Returns an error when any check fails so scripts can rely on the exit code.
+func handleDoctorCommand() error {
+	fmt.Printf("\n%s🩺 pt doctor%s\n\n", ColorBold, ColorReset)
+
+	checks := []doctorCheck{doctorClipboard()}
+	checks = append(checks, doctorDiffTools()...)
+	checks = append(checks, doctorConfig()...)
+	checks = append(checks, doctorStore())
+	checks = append(checks, doctorTerminal()...)
+
+	failed, warned := 0, 0
+	for _, check := range checks {
+		symbol := ColorGreen + "✓" + ColorReset
+		switch check.status {
+		case "warn":
+			symbol = ColorYellow + "!" + ColorReset
+			warned++
+		case "fail":
+			symbol = ColorRed + "✗" + ColorReset
+			failed++
+		}
+		fmt.Printf("  %s %s%s:%s %s\n", symbol, ColorBold, check.name, ColorReset, check.detail)
+		if check.hint != "" && check.status != "ok" {
+			fmt.Printf("      %s→ %s%s\n", ColorGray, check.hint, ColorReset)
+		}
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed, %d warning(s)", failed, warned)
+	}
+	if warned > 0 {
+		fmt.Printf("%s✅ No failures, %d warning(s)%s\n", ColorYellow, warned, ColorReset)
+	} else {
+		fmt.Printf("%s✅ Everything looks good%s\n", ColorGreen, ColorReset)
+	}
+	return nil
+}
+
+// doctorClipboard round-trips a marker through the clipboard and puts the
+// previous content back
+func doctorClipboard() doctorCheck {
+	check := doctorCheck{name: "Clipboard"}
+	if clipboard.Unsupported {
+		check.status, check.detail = "fail", "no clipboard tool found"
+		check.hint = "install xclip, xsel or wl-clipboard (Termux: termux-api)"
+		return check
+	}
+
+	previous, err := clipboardProvider.Read()
+	if err != nil {
+		check.status, check.detail = "fail", fmt.Sprintf("read failed: %v", err)
+		check.hint = "make sure a display server is running (DISPLAY or WAYLAND_DISPLAY is set)"
+		return check
+	}
+
+	marker := fmt.Sprintf("pt doctor %d", time.Now().UnixNano())
+	if err := clipboardProvider.Write(marker); err != nil {
+		check.status, check.detail = "fail", fmt.Sprintf("write failed: %v", err)
+		check.hint = "make sure a display server is running (DISPLAY or WAYLAND_DISPLAY is set)"
+		return check
+	}
+	readBack, err := clipboardProvider.Read()
+	clipboardProvider.Write(previous)
+
+	if err != nil || readBack != marker {
+		check.status, check.detail = "warn", "content read back differs from what was written"
+		check.hint = "a clipboard manager may be rewriting the selection; try raising clipboard_retries"
+		return check
+	}
+	check.status, check.detail = "ok", "read/write round-trip works"
+	return check
+}
+
+// doctorDiffTools reports the installed diff tools and whether the
+// configured one is among them
+func doctorDiffTools() []doctorCheck {
+	available := getAvailableTools()
+	sort.Strings(available)
+
+	installed := doctorCheck{name: "Diff tools", status: "ok"}
+	if len(available) == 0 {
+		installed.status, installed.detail = "warn", "none installed, only the built-in pdiff2 is available"
+		installed.hint = "install delta: " + diffTools["delta"].InstallURL
+	} else {
+		installed.detail = strings.Join(available, ", ")
+	}
+
+	toolName := resolveDiffTool("")
+	configured := doctorCheck{name: "Default diff tool", status: "ok", detail: toolName}
+	switch {
+	case toolName == "pdiff" || toolName == "pdiff2":
+		configured.detail += " (built-in)"
+	case !checkToolInstalled(toolName):
+		configured.status = "warn"
+		configured.detail += " is not installed; diffs fall back to pdiff2 where supported"
+		if tool, ok := diffTools[toolName]; ok {
+			configured.hint = "install from " + tool.InstallURL + " or set diff_tool in the config"
+		} else {
+			configured.hint = "unknown tool; set diff_tool to one of: " + strings.Join(available, ", ")
+		}
+	}
+
+	return []doctorCheck{installed, configured}
+}
+
+// doctorConfig checks the config file and that the per-user config
+// directory can be written
+func doctorConfig() []doctorCheck {
+	var checks []doctorCheck
+
+	configPath := findConfigFile()
+	file := doctorCheck{name: "Config file", status: "ok"}
+	if configPath == "" {
+		file.detail = "none found, using defaults"
+	} else if problems, err := validateConfigFile(configPath); err != nil {
+		file.status, file.detail = "fail", err.Error()
+	} else if len(problems) > 0 {
+		file.status = "fail"
+		file.detail = fmt.Sprintf("%s has %d problem(s): %s", configPath, len(problems), strings.Join(problems, "; "))
+		file.hint = "run 'pt config validate' for details, or 'pt config edit' to fix"
+	} else {
+		file.detail = configPath + " is valid"
+	}
+	checks = append(checks, file)
+
+	if localConfigPath != "" {
+		local := doctorCheck{name: "Local config", status: "ok", detail: localConfigPath}
+		if problems, err := validateConfigFile(localConfigPath); err == nil && len(problems) > 0 {
+			local.status = "fail"
+			local.detail = fmt.Sprintf("%s has %d problem(s): %s", localConfigPath, len(problems), strings.Join(problems, "; "))
+			local.hint = "run 'pt config validate " + localConfigPath + "' for details"
+		}
+		checks = append(checks, local)
+	}
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		dir = filepath.Join(dir, "pt")
+		writable := doctorCheck{name: "Config directory", status: "ok", detail: dir + " is writable"}
+		probeDir := dir
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			probeDir = filepath.Dir(dir)
+			writable.detail = dir + " can be created"
+		}
+		if probe, err := os.CreateTemp(probeDir, ".pt-doctor-*"); err != nil {
+			writable.status = "warn"
+			writable.detail = probeDir + " is not writable"
+			writable.hint = "check its permissions, or point $" + configEnvVar + " at a config elsewhere"
+		} else {
+			probe.Close()
+			os.Remove(probe.Name())
+		}
+		checks = append(checks, writable)
+	}
+
+	return checks
+}
+
+// doctorStore reports which backup store the current directory uses and
+// warns when it sits above the git repository, where backups of unrelated
+// projects end up mixed together
+func doctorStore() doctorCheck {
+	check := doctorCheck{name: "Backup store", status: "ok"}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		check.status, check.detail = "fail", fmt.Sprintf("cannot get current directory: %v", err)
+		return check
+	}
+
+	ptRoot, err := findPTRoot(cwd)
+	gitRoot := findGitRoot(cwd)
+	switch {
+	case err != nil:
+		check.status, check.detail = "fail", err.Error()
+	case ptRoot == "":
+		base := cwd
+		if gitRoot != "" {
+			base = gitRoot
+		}
+		check.detail = fmt.Sprintf("none yet; the first backup creates %s", filepath.Join(base, appConfig.BackupDirName))
+	case gitRoot != "" && filepath.Dir(ptRoot) != gitRoot && !strings.HasPrefix(ptRoot, gitRoot+string(filepath.Separator)):
+		check.status = "warn"
+		check.detail = fmt.Sprintf("%s is outside the git repository %s", ptRoot, gitRoot)
+		check.hint = "run 'pt init' in " + gitRoot + " to give this project its own store"
+	default:
+		check.detail = ptRoot
+	}
+	return check
+}
+
+// doctorTerminal reports what output features are available
+func doctorTerminal() []doctorCheck {
+	isTTY := term.IsTerminal(int(os.Stdout.Fd()))
+
+	terminal := doctorCheck{name: "Terminal", status: "ok"}
+	if isTTY {
+		terminal.detail = fmt.Sprintf("%d columns", getTerminalWidth())
+	} else {
+		terminal.status = "warn"
+		terminal.detail = "stdout is not a terminal (progress lines and paging are off)"
+	}
+
+	colors := doctorCheck{name: "Colors", status: "ok"}
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		colors.status, colors.detail = "warn", "NO_COLOR is set"
+		colors.hint = "pt still prints ANSI colors; unset NO_COLOR or pipe through a filter"
+	case colorTerm == "truecolor" || colorTerm == "24bit":
+		colors.detail = "24-bit color"
+	case os.Getenv("TERM") == "dumb":
+		colors.status, colors.detail = "warn", "TERM=dumb, colors will show as escape codes"
+	default:
+		colors.status, colors.detail = "warn", "24-bit color not advertised ($COLORTERM)"
+		colors.hint = "syntax highlighting uses 24-bit color and may look off; set COLORTERM=truecolor if supported"
+	}
+
+	pager := doctorCheck{name: "Pager", status: "ok"}
+	if path, err := exec.LookPath("less"); err == nil {
+		pager.detail = path
+	} else if path, err := exec.LookPath("more"); err == nil {
+		pager.status, pager.detail = "warn", path+" (less is not installed, colors may not page correctly)"
+		pager.hint = "install less"
+	} else {
+		pager.status, pager.detail = "warn", "no pager found, long output is printed directly"
+		pager.hint = "install less"
+	}
+
+	return []doctorCheck{terminal, colors, pager}
+}
+
+// handleWhereCommand explains how a file maps into the backup store
+// (pt where <file>): the .pt root, the flattened backup subdir, and what
+// listBackups will find there
+func handleWhereCommand(filename string) error {
+	filePath, err := resolveFilePath(filename)
+	if err != nil {
+		// Deleted files still have backups: fall back to the literal path
+		filePath = filename
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+
+	label := func(name string) string {
+		return fmt.Sprintf("%s%-14s%s", ColorCyan, name+":", ColorReset)
+	}
+
+	fmt.Printf("\n%s📍 Backup location for %s%s\n\n", ColorBold, filename, ColorReset)
+	if _, err := os.Stat(absPath); err == nil {
+		fmt.Printf("  %s %s\n", label("File"), absPath)
+	} else {
+		fmt.Printf("  %s %s %s(does not exist)%s\n", label("File"), absPath, ColorYellow, ColorReset)
 	}
 
-	gitignoreContent := string(content)
-	
-	// Check if .pt is already ignored
-	ptPattern := appConfig.BackupDirName + "/"
-	if strings.Contains(gitignoreContent, ptPattern) || strings.Contains(gitignoreContent, appConfig.BackupDirName+"\n") {
-		return // Already ignored
+	ptDir, err := predictPTDir(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to find %s root: %w", appConfig.BackupDirName, err)
+	}
+	if info, err := os.Stat(ptDir); err == nil && info.IsDir() {
+		fmt.Printf("  %s %s\n", label("Store"), ptDir)
+	} else {
+		fmt.Printf("  %s %s %s(not created yet, made on first backup)%s\n", label("Store"), ptDir, ColorYellow, ColorReset)
 	}
 
-	// Append .pt to .gitignore
-	f, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	backupDir, err := getBackupDir(ptDir, absPath)
 	if err != nil {
-		return // Skip on error
+		return fmt.Errorf("failed to compute backup directory: %w", err)
 	}
-	defer f.Close()
 
-	// Add newline if file doesn't end with one
-	if len(content) > 0 && content[len(content)-1] != '\n' {
-		f.WriteString("\n")
+	base := filepath.Base(absPath)
+	ext := filepath.Ext(base)
+	pattern := fmt.Sprintf("%s_%s.", strings.TrimSuffix(base, ext), strings.TrimPrefix(ext, "."))
+
+	fmt.Printf("  %s %s\n", label("Backup dir"), backupDir)
+	fmt.Printf("  %s %s*\n", label("Name pattern"), pattern)
+
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		fmt.Printf("  %s %sdoes not exist, no backups yet%s\n", label("Backups"), ColorYellow, ColorReset)
+
+		// listBackups also checks the plain file name, used by older moves
+		alternate := filepath.Join(ptDir, base)
+		if alternate != backupDir && countBackupsIn(alternate) > 0 {
+			fmt.Printf("  %s %s (%d backup(s), used as a fallback)\n", label("Fallback"), alternate, countBackupsIn(alternate))
+		}
+		return nil
 	}
 
-	f.WriteString("# PT backup directory\n")
-	f.WriteString(ptPattern + "\n")
+	matching, other := 0, 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".meta.json") || name == backupDirConfigFile {
+			continue
+		}
+		if strings.HasPrefix(name, pattern) {
+			matching++
+		} else {
+			other++
+		}
+	}
 
-	logger.Printf("Added %s to .gitignore", ptPattern)
+	fmt.Printf("  %s %d\n", label("Backups"), matching)
+	if other > 0 {
+		fmt.Printf("  %s⚠️  %d other file(s) in the backup dir don't match the name pattern and are ignored%s\n",
+			ColorYellow, other, ColorReset)
+	}
+
+	return nil
 }
 
 // getRelativePath gets relative path from .pt root to file
@@ -4182,41 +11154,12 @@ func getRelativePath(ptRoot, filePath string) (string, error) {
 //   ./pt/main.go       -> .pt/pt_main.go/
 //   ./src/lib/util.go  -> .pt/src_lib_util.go/
 func getBackupDir(ptRoot, filePath string) (string, error) {
-	relPath, err := getRelativePath(ptRoot, filePath)
+	backupDir, err := backupStore.Dir(ptRoot, filePath)
 	if err != nil {
 		return "", err
 	}
 
-	// Clean the relative path
-	relPath = filepath.Clean(relPath)
-	
-	// Get the base filename
-	baseName := filepath.Base(relPath)
-	
-	// Get the directory part (if any)
-	dirPart := filepath.Dir(relPath)
-
-	var backupSubdir string
-	
-	// If file is directly in .pt parent (no subdirectory)
-	if dirPart == "." {
-		// Just use the filename
-		backupSubdir = baseName
-	} else {
-		// File is in a subdirectory, preserve the path structure
-		// Replace path separators with underscores
-		// e.g., pt/main.go -> pt_main.go
-		//       src/lib/util.go -> src_lib_util.go
-		fullPath := relPath
-		fullPath = strings.ReplaceAll(fullPath, string(os.PathSeparator), "_")
-		fullPath = strings.ReplaceAll(fullPath, "/", "_")  // Unix
-		fullPath = strings.ReplaceAll(fullPath, "\\", "_") // Windows
-		backupSubdir = fullPath
-	}
-
-	backupDir := filepath.Join(ptRoot, backupSubdir)
-
-	logger.Printf("Backup dir for %s: %s (relative: %s)", filePath, backupDir, relPath)
+	logger.Printf("Backup dir for %s: %s", filePath, backupDir)
 
 	return backupDir, nil
 }
@@ -4244,7 +11187,7 @@ func searchFileRecursive(filename string, maxDepth int) ([]FileSearchResult, err
 		})
 	}
 
-	err = filepath.Walk(cwd, func(path string, info os.FileInfo, err error) error {
+	err = walkFollowingSymlinks(cwd, make(map[string]bool), func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -4301,70 +11244,66 @@ func searchFileRecursive(filename string, maxDepth int) ([]FileSearchResult, err
 	return results, nil
 }
 
-func printFileSearchResults(results []FileSearchResult) {
+// fileSearchColumns lays out search results for a terminal termWidth
+// columns wide, giving Path whatever Modified and Size leave over and
+// dropping Size when that would be too little.
+func fileSearchColumns(termWidth int) []tableColumn {
 	const (
-		col1Width = 60
-		col2Width = 19
-		col3Width = 12
+		sizeWidth    = 12
+		minPathWidth = 20
 	)
+	dateWidth := timeColumnWidth()
 
-	fmt.Printf("\n%s🔍 Found %d file(s):%s\n\n", ColorCyan, len(results), ColorReset)
+	path := tableColumn{header: "Path"}
+	date := tableColumn{header: "Modified", width: dateWidth}
+	size := tableColumn{header: "Size", width: sizeWidth, right: true}
 
-	fmt.Printf("%s┌%s┬%s┬%s┐%s\n",
-		ColorGray,
-		strings.Repeat("─", col1Width+2),
-		strings.Repeat("─", col2Width+2),
-		strings.Repeat("─", col3Width+2),
-		ColorReset)
+	free := termWidth - tableOverhead(3) - dateWidth - sizeWidth
+	if free >= minPathWidth {
+		path.width = free
+		return []tableColumn{path, date, size}
+	}
 
-	fmt.Printf("%s│%s %s%s%-*s%s %s│%s %s%s%-*s%s %s│%s %s%s%*s%s %s│%s\n",
-		ColorGray, ColorReset,
-		ColorBold, ColorYellow, col1Width, "Path", ColorReset,
-		ColorGray, ColorReset,
-		ColorBold, ColorYellow, col2Width, "Modified", ColorReset,
-		ColorGray, ColorReset,
-		ColorBold, ColorYellow, col3Width, "Size", ColorReset,
-		ColorGray, ColorReset)
+	path.width = max(termWidth-tableOverhead(2)-dateWidth, 10)
+	return []tableColumn{path, date}
+}
+
+func printFileSearchResults(results []FileSearchResult) {
+	cols := fileSearchColumns(getTerminalWidth())
 
-	fmt.Printf("%s├%s┼%s┼%s┤%s\n",
-		ColorGray,
-		strings.Repeat("─", col1Width+2),
-		strings.Repeat("─", col2Width+2),
-		strings.Repeat("─", col3Width+2),
-		ColorReset)
+	fmt.Printf("\n%s🔍 Found %d file(s):%s\n\n", ColorCyan, len(results), ColorReset)
+
+	fmt.Println(tableRule("┌", "┬", "┐", cols))
+	fmt.Println(tableHeader(cols))
+	fmt.Println(tableRule("├", "┼", "┤", cols))
 
+	cwd, _ := os.Getwd()
 	for i, result := range results {
-		cwd, _ := os.Getwd()
 		relPath, err := filepath.Rel(cwd, result.Path)
 		if err != nil {
 			relPath = result.Path
 		}
 
-		displayPath := relPath
-		maxPathLen := col1Width - 5
-		if len(displayPath) > maxPathLen {
-			displayPath = "..." + displayPath[len(displayPath)-maxPathLen+3:]
-		}
+		fmt.Println(tableRow(cols, fileSearchCells(cols, i+1, relPath, result)))
+	}
 
-		modTime := result.ModTime.Format("2006-01-02 15:04:05")
-		sizeStr := formatSize(result.Size)
+	fmt.Println(tableRule("└", "┴", "┘", cols))
+	fmt.Println()
+}
 
-		fmt.Printf("%s│%s %s%3d. %-*s%s %s│%s %-*s %s│%s %*s %s│%s\n",
-			ColorGray, ColorReset,
-			ColorGreen, i+1, maxPathLen, displayPath, ColorReset,
-			ColorGray, ColorReset,
-			col2Width, modTime,
-			ColorGray, ColorReset,
-			col3Width, sizeStr,
-			ColorGray, ColorReset)
+// fileSearchCells fills in row number n of the search results, cut to fit
+// cols. relPath is shown in place of result.Path.
+func fileSearchCells(cols []tableColumn, n int, relPath string, result FileSearchResult) []string {
+	number := fmt.Sprintf("%3d. ", n)
+	cells := []string{
+		ColorGreen + number + truncateCellLeft(relPath, cols[0].width-len(number)) + ColorReset,
+		formatTime(result.ModTime),
+		formatSize(result.Size),
 	}
-
-	fmt.Printf("%s└%s┴%s┴%s┘%s\n\n",
-		ColorGray,
-		strings.Repeat("─", col1Width+2),
-		strings.Repeat("─", col2Width+2),
-		strings.Repeat("─", col3Width+2),
-		ColorReset)
+	for j := 1; j < len(cols); j++ {
+		cells[j] = truncateCell(cells[j], cols[j].width)
+	}
+	return cells
 }
 
 func resolveFilePath(filename string) (string, error) {
@@ -4374,7 +11313,7 @@ func resolveFilePath(filename string) (string, error) {
 	}
 
 	logger.Printf("File not found in current directory, searching recursively...")
-	fmt.Printf("%s🔍 Searching for '%s' in subdirectories...%s\n", ColorBlue, filename, ColorReset)
+	fmt.Fprintf(infoOut, "%s🔍 Searching for '%s' in subdirectories...%s\n", ColorBlue, filename, ColorReset)
 
 	results, err := searchFileRecursive(filename, appConfig.MaxSearchDepth)
 	if err != nil {
@@ -4382,11 +11321,11 @@ func resolveFilePath(filename string) (string, error) {
 	}
 
 	if len(results) == 0 {
-		return "", fmt.Errorf("file '%s' not found in current directory or subdirectories", filename)
+		return "", fmt.Errorf("file '%s' %w in current directory or subdirectories", filename, errNotFound)
 	}
 
 	if len(results) == 1 {
-		fmt.Printf("%s✅ Found:%s %s%s%s%s\n", ColorYellow, ColorReset, ColorWhite, ColorCyan, results[0].Path, ColorReset)
+		fmt.Fprintf(infoOut, "%s✅ Found:%s %s%s%s%s\n", ColorYellow, ColorReset, ColorWhite, ColorCyan, results[0].Path, ColorReset)
 		return results[0].Path, nil
 	}
 
@@ -4457,6 +11396,8 @@ func checkDiskSpace(path string, requiredSize int64) error {
 	}
 
 	testFile := filepath.Join(dir, ".pt_test_"+generateShortID())
+	trackTempFile(testFile)
+	defer untrackTempFile(testFile)
 	f, err := os.Create(testFile)
 	if err != nil {
 		return fmt.Errorf("no write permission in directory: %w", err)
@@ -4478,7 +11419,7 @@ func generateUniqueBackupName(filePath string) string {
 	ext := filepath.Ext(baseName)
 	nameWithoutExt := strings.TrimSuffix(baseName, ext)
 
-	timestamp := time.Now().Format("20060102_150405.000000")
+	timestamp := time.Now().In(displayLocation()).Format("20060102_150405.000000")
 	timestamp = strings.ReplaceAll(timestamp, ".", "")
 
 	uniqueID := fmt.Sprintf("%d_%s", os.Getpid(), generateShortID())
@@ -4486,8 +11427,52 @@ func generateUniqueBackupName(filePath string) string {
 	return fmt.Sprintf("%s_%s.%s.%s", nameWithoutExt, strings.TrimPrefix(ext, "."), timestamp, uniqueID)
 }
 
+// ClipboardProvider is the system clipboard as pt sees it. Everything reads
+// through clipboardProvider, so tests and CI can swap in a fake.
+type ClipboardProvider interface {
+	Read() (string, error)
+	Write(text string) error
+}
+
+// systemClipboard is the default ClipboardProvider backed by atotto/clipboard
+type systemClipboard struct{}
+
+func (systemClipboard) Read() (string, error) {
+	return clipboard.ReadAll()
+}
+
+func (systemClipboard) Write(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+var clipboardProvider ClipboardProvider = systemClipboard{}
+
+// clipboardRetryDelay is the backoff step between clipboard reads; the n-th
+// retry waits n times this long
+const clipboardRetryDelay = 100 * time.Millisecond
+
+// getClipboardText reads the clipboard, retrying up to clipboard_retries
+// times when a read fails or comes back empty. Right after a copy, X11 and
+// Wayland can briefly report no selection while ownership changes hands.
+// An empty read without an error on the last attempt means the clipboard
+// really is empty; an error means reading kept failing.
 func getClipboardText() (string, error) {
-	text, err := clipboard.ReadAll()
+	retries := appConfig.ClipboardRetries
+	if _, system := clipboardProvider.(systemClipboard); system && clipboard.Unsupported {
+		// No clipboard tool installed: retrying can't help
+		retries = 0
+	}
+
+	var text string
+	var err error
+	for attempt := 0; ; attempt++ {
+		text, err = clipboardProvider.Read()
+		if (err == nil && text != "") || attempt >= retries {
+			break
+		}
+		logger.Printf("Clipboard read %d/%d came back empty or failed (%v), retrying", attempt+1, retries+1, err)
+		time.Sleep(time.Duration(attempt+1) * clipboardRetryDelay)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to read clipboard: %w", err)
 	}
@@ -4499,12 +11484,32 @@ func getClipboardText() (string, error) {
 	return text, nil
 }
 
+// looksBinary reports whether clipboard data is unlikely to be text: invalid
+// UTF-8 or embedded NUL bytes (e.g. an accidentally copied image)
+func looksBinary(data []byte) bool {
+	return !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0
+}
+
+// checkClipboardText refuses binary-looking clipboard data unless the caller
+// opted in with --binary
+func checkClipboardText(text string, allowBinary bool) error {
+	if allowBinary || !looksBinary([]byte(text)) {
+		return nil
+	}
+	return fmt.Errorf("clipboard content looks binary (%d bytes, not valid UTF-8 text); use --binary to write it verbatim", len(text))
+}
+
 func getBackupPath(filePath string) (string, error) {
 	ptRoot, err := ensurePTDir(filePath)
 	if err != nil {
 		return filePath, err
 	}
+	return getBackupPathIn(ptRoot, filePath)
+}
 
+// getBackupPathIn returns a new backup path for filePath in the store at
+// ptRoot
+func getBackupPathIn(ptRoot, filePath string) (string, error) {
 	backupFileName := generateUniqueBackupName(filePath)
 	
 	// Get backup directory for this file within .pt
@@ -4545,19 +11550,18 @@ func autoRenameIfExists(filePath, comment string, check bool) (string, error) {
 
 		backups, err := listBackups(filePath)
 	    if err != nil {
-	    	fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-	        os.Exit(1)
+	        return filePath, err
 	    }
 
 	    if len(backups) > 0 {
 		    var selectedBackup BackupInfo
 
 		    selectedBackup = backups[0]
-	        fmt.Printf("%s📊 Comparing with last backup: %s%s\n\n", ColorCyan, selectedBackup.Name, ColorReset)
+	        fmt.Fprintf(infoOut, "%s📊 Comparing with last backup: %s%s\n\n", ColorCyan, selectedBackup.Name, ColorReset)
 		    
 			
 			if !checkIfDifferent(filePath, selectedBackup.Path) {
-				fmt.Printf(" ⚠ %sLast backup:%s %s%s%s%s %sand%s %s'%s'%s %sis%s %s%sidentical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, selectedBackup.Name, ColorReset, ColorYellow, ColorReset, ColorCyan, filePath, ColorReset, ColorYellow, ColorReset, ColorWhite, BgMagenta, ColorReset)
+				fmt.Fprintf(infoOut, " ⚠ %sLast backup:%s %s%s%s%s %sand%s %s'%s'%s %sis%s %s%sidentical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, selectedBackup.Name, ColorReset, ColorYellow, ColorReset, ColorCyan, filePath, ColorReset, ColorYellow, ColorReset, ColorWhite, BgMagenta, ColorReset)
 				return filePath, nil
 			}
 		}
@@ -4565,34 +11569,56 @@ func autoRenameIfExists(filePath, comment string, check bool) (string, error) {
 	}
 
 	// Ensure .pt directory exists (searches parent dirs)
-	backupPath, _ := getBackupPath(filePath)
+	ptRoot, err := ensurePTDir(filePath)
+	if err != nil {
+		return filePath, err
+	}
+	return filePath, backupFileInto(ptRoot, filePath, comment, info)
+}
 
-	content, err := os.ReadFile(filePath)
+// backupFileInto backs up filePath into the store at ptRoot. info is the
+// file's current stat.
+func backupFileInto(ptRoot, filePath, comment string, info os.FileInfo) error {
+	backupPath, err := getBackupPathIn(ptRoot, filePath)
 	if err != nil {
-		return filePath, fmt.Errorf("failed to read file for backup: %w", err)
+		return err
 	}
 
-	err = os.WriteFile(backupPath, content, 0644)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return filePath, fmt.Errorf("failed to create backup: %w", err)
+		return fmt.Errorf("failed to read file for backup: %w", err)
 	}
+	if appConfig.NormalizeStoredLineEndings {
+		content = normalizeLineEndings(content, appConfig.NormalizeLineEndings)
+	}
+
+	// A backup interrupted before its metadata is written is incomplete:
+	// let the interrupt handler remove it
+	trackTempFile(backupPath)
+	trackTempFile(backupPath + ".meta.json")
 
-	err = saveBackupMetadata(backupPath, comment, filePath, info.Size())
+	err = backupStore.Save(backupPath, content, newBackupMetadata(comment, filePath, info.Size(), info.ModTime()))
+	untrackTempFile(backupPath)
+	untrackTempFile(backupPath + ".meta.json")
 	if err != nil {
-		logger.Printf("Warning: failed to save backup metadata: %v", err)
+		return err
 	}
 
+	// A new backup becomes the head of the history: forget any undo position
+	resetUndoCursor(filepath.Dir(filepath.Dir(backupPath)), filePath)
+
 	logger.Printf("Backup created: %s -> %s", filePath, backupPath)
 	backupFileName := filepath.Base(backupPath)
+	auditLog("backup", filePath, info.Size(), "backup", backupFileName)
 	if comment != "" {
 		logger.Printf("Backup comment: %s", comment)
-		fmt.Printf("📦 Backup created: %s%s%s\n", ColorBrightYellow, backupFileName, ColorReset)
-		fmt.Printf("💬 Comment: \"%s%s%s\"\n", ColorBrightMagenta, comment, ColorReset)
+		fmt.Fprintf(infoOut, "📦 Backup created: %s%s%s\n", ColorBrightYellow, backupFileName, ColorReset)
+		fmt.Fprintf(infoOut, "💬 Comment: \"%s%s%s\"\n", ColorBrightMagenta, comment, ColorReset)
 	} else {
-		fmt.Printf("📦 Backup created: %s%s%s\n", ColorBrightYellow, backupFileName, ColorReset)
+		fmt.Fprintf(infoOut, "📦 Backup created: %s%s%s\n", ColorBrightYellow, backupFileName, ColorReset)
 	}
 
-	return filePath, nil
+	return nil
 }
 
 func isFileWithTimeout(path string, timeout time.Duration) bool {
@@ -4704,9 +11730,9 @@ func checkIfDifferent(filePath string, data any) bool {
     // Compare normalized content
     if existingContent == inputContent {
         logger.Printf("ℹ️ Content identical, skipping write: %s", filePath)
-        fmt.Printf("ℹ️ %s%sContent identical to%s %s`%s`%s, %s%sno changes needed%s\n", 
+        fmt.Fprintf(infoOut, "ℹ️ %s%sContent identical to%s %s`%s`%s, %s%sno changes needed%s\n", 
             ColorWhite, BgBlue, ColorReset, ColorCyan, filePath, ColorReset, ColorWhite, BgYellow, ColorReset)
-        fmt.Printf("📄 File: %s\n", filePath)
+        fmt.Fprintf(infoOut, "📄 File: %s\n", filePath)
         return false
     }
     
@@ -4742,7 +11768,13 @@ func normalizeDataToString(data any) (string, error) {
 }
 
 
-func writeFile(filePath string, data string, appendMode bool, checkMode bool, comment string) error {
+func writeFile(filePath string, data string, appendMode bool, checkMode bool, comment string, noBackup bool) error {
+	return writeFileBytes(filePath, []byte(data), appendMode, checkMode, comment, noBackup)
+}
+
+// writeFileBytes is the byte-oriented write path behind writeFile, so --binary
+// clipboard content reaches the disk without any text handling
+func writeFileBytes(filePath string, data []byte, appendMode bool, checkMode bool, comment string, noBackup bool) error {
 	if err := validatePath(filePath); err != nil {
 		return err
 	}
@@ -4789,12 +11821,14 @@ func writeFile(filePath string, data string, appendMode bool, checkMode bool, co
 		return err
 	}
 
-	if !appendMode {
+	if !appendMode && !noBackup {
 		var err error
 		filePath, err = autoRenameIfExists(filePath, comment, false)
 		if err != nil {
 			return err
 		}
+	} else if noBackup {
+		logger.Printf("Skipping backup (--no-backup): %s", filePath)
 	}
 
 	var flag int
@@ -4810,7 +11844,7 @@ func writeFile(filePath string, data string, appendMode bool, checkMode bool, co
 	}
 	defer file.Close()
 
-	n, err := file.WriteString(data)
+	n, err := file.Write(data)
 	if err != nil {
 		return fmt.Errorf("failed to write to file: %w", err)
 	}
@@ -4824,46 +11858,82 @@ func writeFile(filePath string, data string, appendMode bool, checkMode bool, co
 	}
 
 	action := "written to"
+	op := "write"
 	if appendMode {
 		action = "appended to"
+		op = "append"
 	}
 
 	logger.Printf("Successfully %s: %s (%d bytes)", action, filePath, len(data))
+	if appendMode && comment != "" {
+		// Appends take no backup, so the comment goes to the audit log
+		auditLog(op, filePath, int64(len(data)), "comment", comment)
+	} else {
+		auditLog(op, filePath, int64(len(data)))
+	}
 	fmt.Printf("✅ Successfully %s: %s\n", action, filePath)
-	fmt.Printf("📄 %sContent size:%s %d characters\n", ColorBrightBlue, ColorReset, len(data))
+	if looksBinary(data) {
+		fmt.Printf("📄 %sContent size:%s %d bytes (binary)\n", ColorBrightBlue, ColorReset, len(data))
+	} else {
+		fmt.Printf("📄 %sContent size:%s %d characters\n", ColorBrightBlue, ColorReset, len(data))
+	}
 
 	return nil
 }
 
 
-func parseWriteArgs(args []string) (filename string, comment string, checkMode bool, err error) {
+func parseWriteArgs(args []string) (filename string, comment string, checkMode bool, noBackup bool, err error) {
 	if len(args) == 0 {
-		return "", "", false, fmt.Errorf("filename required")
+		return "", "", false, false, fmt.Errorf("filename required")
 	}
 
 	filename = args[0]
 	comment = ""
 	checkMode = false
+	noBackup = false
 
 	i := 1
 	for i < len(args) {
 		switch args[i] {
 		case "-m", "--message":
 			if i+1 >= len(args) {
-				return "", "", false, fmt.Errorf("-m/--message requires a value")
+				return "", "", false, false, fmt.Errorf("-m/--message requires a value")
 			}
 			i++
 			comment = args[i]
 		case "-c", "--check":
 			checkMode = true
 			checkBefore = true
+		case "--no-backup":
+			noBackup = true
 		default:
-			return "", "", false, fmt.Errorf("unknown flag: %s", args[i])
+			return "", "", false, false, fmt.Errorf("unknown flag: %s", args[i])
 		}
 		i++
 	}
 
-	return filename, comment, checkMode, nil
+	return filename, comment, checkMode, noBackup, nil
+}
+
+// parseBackupNumber parses a --backup value: a backup number as listed by
+// pt -l, 1 being the newest
+func parseBackupNumber(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid --backup value %q: expected a backup number from pt -l (1 = newest)", value)
+	}
+	return n, nil
+}
+
+// backupNumbered returns backup n (see parseBackupNumber) of filePath's
+// backups, or an error saying how many there are
+func backupNumbered(backups []BackupInfo, filePath string, n int) (BackupInfo, error) {
+	if n > len(backups) {
+		return BackupInfo{}, withHint(fmt.Errorf("%s has only %d backup(s), there is no backup #%d",
+			filepath.Base(filePath), len(backups), n),
+			fmt.Sprintf("list them with: pt -l %s", filepath.Base(filePath)))
+	}
+	return backups[n-1], nil
 }
 
 func readUserChoice(max int) (int, error) {
@@ -4889,6 +11959,126 @@ func readUserChoice(max int) (int, error) {
 	return choice, nil
 }
 
+// confirmAction asks the user to confirm an action and reports whether one of
+// the accepted answers was given. assumeYes (--yes/-y) skips the prompt. When
+// stdin is not a terminal and --yes was not given, an error is returned instead
+// of reading EOF and silently cancelling.
+func confirmAction(prompt string, assumeYes bool, accepted ...string) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("confirmation required but stdin is not a terminal (use --yes to proceed non-interactively)")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+
+	for _, answer := range accepted {
+		if input == answer {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR, split
+// into the program and its arguments (e.g. "code --wait"). A variable that
+// is unset or only whitespace is skipped; nil means neither is usable.
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editorArgs := strings.Fields(os.Getenv(name)); len(editorArgs) > 0 {
+			return editorArgs
+		}
+	}
+	return nil
+}
+
+// runEditor opens path in the editor given by editorArgs and waits for it
+// to exit
+func runEditor(editorArgs []string, path string) error {
+	cmd := exec.Command(editorArgs[0], append(editorArgs[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	editor := strings.Join(editorArgs, " ")
+	logger.Printf("Launching editor: %s %s", editor, path)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return nil
+}
+
+// editFile opens path in $VISUAL or $EDITOR (vi, or notepad on Windows, when
+// neither is set) and waits for the editor to exit
+func editFile(path string) error {
+	editorArgs := editorCommand()
+	if editorArgs == nil {
+		editorArgs = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			editorArgs = []string{"notepad"}
+		}
+	}
+	return runEditor(editorArgs, path)
+}
+
+// editMessage composes a message interactively, like git commit without -m.
+// It opens $VISUAL or $EDITOR on a temp file and returns the non-comment
+// lines; without an editor it falls back to a one-line prompt on stdin.
+func editMessage() (string, error) {
+	editorArgs := editorCommand()
+	if editorArgs == nil {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Print("Commit message: ")
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			return "", fmt.Errorf("failed to read message: %w", err)
+		}
+		return strings.TrimSpace(input), nil
+	}
+
+	tmpFile, err := os.CreateTemp("", "pt-message-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	trackTempFile(tmpPath)
+	defer untrackTempFile(tmpPath)
+	defer os.Remove(tmpPath)
+
+	template := "\n# Please enter the message for your changes. Lines starting\n" +
+		"# with '#' will be ignored, and an empty message aborts.\n"
+	if _, err := tmpFile.WriteString(template); err != nil {
+		tmpFile.Close()
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	tmpFile.Close()
+
+	if err := runEditor(editorArgs, tmpPath); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
 // printShowHeader prints bat-like header
 func printShowHeader(filePath string, info os.FileInfo, status FileStatus, showGrid bool) {
 	relPath, _ := filepath.Rel(".", filePath)
@@ -4907,7 +12097,7 @@ func printShowHeader(filePath string, info os.FileInfo, status FileStatus, showG
 	fmt.Println()
 
 	// Size and modified time
-	modTime := info.ModTime().Format("2006-01-02 15:04:05")
+	modTime := formatTime(info.ModTime())
 	fmt.Printf("%s       │%s %sSize:%s %s  %sModified:%s %s\n",
 		ColorGray, ColorReset,
 		ColorCyan, ColorReset, formatSize(info.Size()),
@@ -5025,68 +12215,156 @@ func printHelp() {
 	fmt.Printf("  %spt <filename>%s               Write clipboard to file\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt <filename> -c%s            Write only if content differs\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt <filename> -m \"msg\"%s      Write with comment\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt <filename> --keep N%s      Keep up to N backups of this file (overrides max_backup_count)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt <filename> --no-backup%s   Write without backing up the old content\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt <filename> --binary%s      Write clipboard bytes verbatim even if not text\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt <filename> --strip-ansi%s  Drop terminal color/escape codes from the clipboard first (also for +)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt + <filename>%s             Append clipboard to file\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt split [-]%s                Write a multi-file paste (=== path === sections or a diff)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt split --marker <regexp>%s  Custom section header; group 1 is the file path\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -b/backup <filename>%s     Backup file with check before\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt backup --all -m \"msg\"%s    Snapshot every new or changed file\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s👁️  VIEW & DISPLAY:%s\n", ColorBold+ColorYellow, ColorReset)
 	fmt.Printf("  %spt show <filename>%s          Display file with syntax highlighting (like bat)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt show <file> -l <lexer>%s   Specify lexer (e.g., go, python, javascript)\n", ColorGreen, ColorReset)
-	fmt.Printf("  %spt show <file> -t <theme>%s   Specify theme (default: monokai)\n", ColorGreen, ColorReset)
-	fmt.Printf("  %spt show <file> --pager%s      Use pager (less) for navigation\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> -t <theme>%s   Specify theme (default: fruity, \"auto\" follows terminal background)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --pager%s      Always use the pager (default: only when taller than the terminal)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --no-pager%s   Never use the pager\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --diff%s       Mark lines added/changed since last backup\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --changes%s    Only the regions changed since last backup (±3 or --context N)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --plain%s      No syntax highlighting (faster for logs/huge files)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %scat <file> | pt show -%s      Highlight stdin (guesses the lexer unless --lexer is given)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --wrap%s       Soft-wrap long lines at terminal width (default: no-wrap)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --tabs N%s     Expand tabs to N-column stops (default: tab_width, 0 = off)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --show-whitespace%s Mark tabs (→) and trailing spaces (·)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --find \"text\"%s Show ±20 lines (or --context N) around the first match\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --output html --out page.html%s Export highlighted HTML (or svg)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --follow%s      Keep printing lines appended to the file, highlighted (like tail -f)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt show <file> --max-lines N%s Stop after N lines (default: unlimited)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -z [options]%s             Show clipboard content\n", ColorGreen, ColorReset)
-	fmt.Printf("    %s-l, --lexer <type>%s        Syntax highlighting (e.g., go, python)\n", ColorGreen, ColorReset)
+	fmt.Printf("    %s-l, --lexer <type>%s        Syntax highlighting (e.g., go, python; auto-detected if omitted)\n", ColorGreen, ColorReset)
 	fmt.Printf("    %s-t, --theme <theme>%s       Color theme (default: monokai)\n", ColorGreen, ColorReset)
 	fmt.Printf("    %s-np, --no-pager%s               Use pager mode (less)\n", ColorGreen, ColorReset)
 	fmt.Printf("    %s--no-line-numbers%s         Disable line numbers\n", ColorGreen, ColorReset)
 	fmt.Printf("    %s--no-grid%s                 Disable grid separators\n", ColorGreen, ColorReset)
+	fmt.Printf("    %s--stats, --count%s          Print size, lines, lexer and binary check only\n", ColorGreen, ColorReset)
+	fmt.Printf("    %s--append <file> [-m msg]%s  Append the previewed content to a file after confirming\n", ColorGreen, ColorReset)
+	fmt.Printf("    %s--save <file> [-m msg]%s    Write the previewed content to a file (backing it up) after confirming\n", ColorGreen, ColorReset)
+	fmt.Printf("    %s--strip-ansi%s              Drop terminal color/escape codes before showing or saving\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s🎯 GIT-LIKE WORKFLOW:%s\n", ColorBold+ColorYellow, ColorReset)
+	fmt.Printf("  %spt init [--here]%s            Create the .pt store at the git root (or here)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt check%s                    Show status of all files (like git status)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt check <filename>%s         Check single file status\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt check <filename> --show%s  If modified, show just the changed regions (--context N)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt check --ignore-whitespace%s Treat trailing-whitespace/line-ending-only edits as unchanged\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt commit -m \"message\"%s      Backup all changed files (like git commit)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt commit src/ \"*.go\" -m \"msg\"%s Only commit changes under these paths/globs\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt commit -m \"msg\" --force%s  Also back up files over max_clipboard_size\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt commit --all -m \"msg\"%s     Snapshot every tracked file, including unchanged\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt commit -m \"msg\" --yes%s      Skip the confirmation prompt (for scripts)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt commit --amend [-m \"msg\"]%s Fold changes into the last commit / reword it\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt commit%s                   Compose message in $VISUAL/$EDITOR (or a prompt)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt commits [show <id>]%s      List past commits, or diff one against the versions before it\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s📦 BACKUP OPERATIONS:%s\n", ColorBold+ColorYellow, ColorReset)
 	fmt.Printf("  %spt -l <filename>%s            List all backups (with comments)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -l <filename> --json%s     List backups as JSON (also --format json)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -l <filename> --oneline%s  One compact line per backup: number, id, age, size, comment\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -l <filename> --preview%s  Add a column with the start of each backup's content\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -l <filename> --since 7d%s List backups in a time range (--since/--until, date or 3d/12h)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -l <filename> --diff-adjacent%s Diff each backup against the one before it (--tool, --pager)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt size [--top N]%s           Show backup store disk usage per file\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt gc%s                       Index backup metadata for faster listings, drop stale sidecars\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt where <filename>%s         Show where a file's backups are stored\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt export --git <dir> <file>%s Replay a file's backups as commits in a new git repo\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt export --git <dir> --all%s Replay every tracked file's backups, interleaved by time\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt pin <filename> <N>%s       Pin backup N (from pt -l) so it is always kept\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt unpin <filename> <N>%s     Remove the pin again\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -r <filename>%s            Restore backup (interactive)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -r <filename> --last/-lt%s     Restore most recent backup\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -r <filename> --backup N%s Restore backup N (as numbered by pt -l) without the prompt\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -r <filename> --last --print-path%s Print the backup's path instead of restoring (or --backup N)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -r <filename> --preview%s  Show the diff and confirm before restoring\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -r <filename> --force%s    Restore over uncommitted changes without asking\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt merge <file> [--last] [--base N]%s Three-way merge a backup into the current file\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -r <filename> --into <path>%s Restore a backup to another file, leaving the original alone\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt restore -r <dir> [--last]%s Restore every file under dir (incl. deleted)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt undo <filename>%s          Step back to the previous backup\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt redo <filename>%s          Step forward again after undo\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt at <file> <time>%s         Print file as it was at a time (e.g. \"2025-11-18 14:30\", 3d)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt at <file> <time> --out f%s Write that version to another file\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s📊 DIFF OPERATIONS:%s\n", ColorBold+ColorYellow, ColorReset)
 	fmt.Printf("  %spt -d <filename>%s            Compare with backup (interactive)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -d <filename> --last/-lt%s     Compare with most recent backup\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d <filename> --backup N%s Compare with backup N (as numbered by pt -l), no prompt\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d a.txt --against b.txt [--backup N]%s Compare a.txt with a backup of b.txt\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d --all [paths...]%s      +added -removed lines of every modified file since its last backup\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -d <filename> -z%s         Diff clipboard with file\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d <filename> -z --last%s  Diff clipboard with the last backup\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -d <filename> -z -T meld%s Diff clipboard with file use meld diff tool\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -d <filename> -z --tool meld%s Diff clipboard with file use meld diff tool\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d <filename> --tool pdiff --word-diff%s Built-in diff, highlighting changed words\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d <filename> --tool pdiff --no-highlight%s Built-in diff without syntax highlighting\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d <filename> --context N%s Show N lines of context (diff, delta, vimdiff, pdiff)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -d <filename> --external%s  Prefer a GUI diff tool (opened in the background when piped)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -dd                         %s Diff with colors and git style \n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt gitdiff [--cached] [path...]%s git diff (or the index with --cached) in PDiff2 style\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt doctor%s                   Check clipboard, diff tools, config, store and terminal\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -dd <filename> -z           %s Diff with colors and git style between filename and clipboard \n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -dd <filename1> <filename1> %s Diff with colors and git style between filename1 and filename2 \n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -dd <filename> --last       %s Diff with colors and git style between filename and last backup \n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s🌳 TREE & UTILITIES:%s\n", ColorBold+ColorYellow, ColorReset)
 	fmt.Printf("  %spt -t [path]%s                Show directory tree\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -t --sort size|mtime|name%s Largest or newest first (dirs by their contents); --reverse flips\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -t [path] -e items,items%s       Tree with exceptions\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -t [path] --follow-symlinks%s Descend into symlinked directories (also check/commit)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -rm <filename>%s           Safe delete (backup first)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -rm <filename> --trash%s   Backup, then move to the OS trash instead of deleting\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -rm -r <dir> [--dry-run]%s Backup every file under dir, then remove the tree\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt move <src> <dst>%s         Move file and adjust backups\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt move <src...> <dst>%s      Move multiple files to directory\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt mv <src...> <dst> -m%s     Move with comment\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt move -r <dir> <dest>%s     Move directory recursively\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt move \"*.py\" dest/%s        Move with wildcard\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt move \"regex:test.*\" dest/%s Move with regex\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt move <src...> <dst> --dry-run%s Show what would move, change nothing\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt move <src> <dst> --merge%s    Overwrite <dst> and merge both backup histories\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt move \"src/*/*.go\" out/ --preserve-structure%s Keep sub-directories (src/a/x.go → out/a/x.go)\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt fix%s                      Detect & fix manual moves\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt fix --yes%s                Auto-fix without asking (ambiguous matches are skipped)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt fix --yes --newest%s       Auto-fix, taking the newest file when ambiguous\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt fix --clean --yes%s        Remove orphaned backups without asking\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s⚙️ CONFIGURATION:%s\n", ColorBold+ColorYellow, ColorReset)
 	fmt.Printf("  %spt config init%s              Create sample config file\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt config show%s              Show current configuration\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt config path%s              Show config file location\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt config validate [path]%s   Check a config file for errors\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt config edit [path]%s       Open the config in $EDITOR, then validate it\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt config show --effective%s  Show every setting with the layer it came from\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt config show --yaml%s       Print the effective settings as YAML (> pt.yml to pin them)\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%sℹ️ INFORMATION:%s\n", ColorBold+ColorYellow, ColorReset)
 	fmt.Printf("  %spt -h, --help%s               Show this help message\n", ColorGreen, ColorReset)
 	fmt.Printf("  %spt -v, --version%s            Show version information\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt version --check%s          Also check GitHub for a newer release\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s🪲 DEBUGGING:%s\n", ColorBold+ColorYellow, ColorReset)
 	fmt.Printf("  %spt --debug%s                  Show debug/logging\n", ColorGreen, ColorReset)
 
 	fmt.Printf("\n%s📺 MONITORING MODE:%s\n", ColorBold+ColorYellow, ColorReset)
-	fmt.Printf("  %spt --monitor/-mt%s            Monitoring change and send notification to growl/gntp (port: 23053)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt --monitor/-mt%s            Monitoring change and send notification (notify-send, toast or growl/gntp, see notification_backend)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -mt --quiet%s              Only print a summary of backups once a minute\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -mt --include-dotpt%s      Also watch directories named .pt (when backup_dir_name is something else)\n", ColorGreen, ColorReset)
+	fmt.Printf("  %spt -mt . --on-change \"cmd\"%s  Run cmd after each change ({} is the changed file)\n", ColorGreen, ColorReset)
+
+	fmt.Printf("\n%s🚦 EXIT CODES:%s\n", ColorBold+ColorYellow, ColorReset)
+	fmt.Printf("  %s0%s success   %s1%s error   %s2%s file not found   %s3%s clipboard empty\n", ColorGreen, ColorReset, ColorGreen, ColorReset, ColorGreen, ColorReset, ColorGreen, ColorReset)
+	fmt.Printf("  %s4%s no backups   %s5%s diff tool not installed   %s130%s interrupted (Ctrl+C)\n", ColorGreen, ColorReset, ColorGreen, ColorReset, ColorGreen, ColorReset)
 	
 	fmt.Printf("\n%s💡 EXAMPLES:%s\n", ColorBold+ColorCyan, ColorReset)
 	fmt.Printf("  %s$%s pt notes.txt                %s# Save clipboard%s\n", ColorGray, ColorReset, ColorGray, ColorReset)
@@ -5117,8 +12395,8 @@ func printHelp() {
 	fmt.Printf("  5. %spt -r <file> --last/-lt%s       - Rollback if needed\n", ColorYellow, ColorReset)
 
 	fmt.Printf("\n%s🎨 THEMES & LEXERS:%s\n", ColorBold+ColorCyan, ColorReset)
-	fmt.Printf("  %sPopular Themes:%s monokai (default), dracula, solarized-dark, solarized-light,\n", ColorBold, ColorReset)
-	fmt.Printf("                 github, vim, xcode, nord, gruvbox, one-dark\n")
+	fmt.Printf("  %sPopular Themes:%s fruity (show default), monokai (-z default), dracula, solarized-dark,\n", ColorBold, ColorReset)
+	fmt.Printf("                 solarized-light, github, vim, xcode, nord, gruvbox, one-dark\n")
 	fmt.Printf("  %sPopular Lexers:%s go, python, javascript, typescript, rust, java, c, cpp,\n", ColorBold, ColorReset)
 	fmt.Printf("                 bash, shell, json, yaml, xml, html, css, sql, markdown\n")
 	fmt.Printf("  %sPager Controls:%s\n", ColorBold, ColorReset)
@@ -5136,7 +12414,7 @@ func printHelp() {
 	
 	fmt.Printf("\n%s📦 COMMIT BEHAVIOR:%s\n", ColorBold+ColorCyan, ColorReset)
 	fmt.Printf("  • Only backs up %smodified%s and %snew%s files\n", ColorYellow, ColorReset, ColorCyan, ColorReset)
-	fmt.Printf("  • Skips %sunchanged%s files (no backup needed)\n", ColorGreen, ColorReset)
+	fmt.Printf("  • Skips %sunchanged%s files (no backup needed) unless --all is given\n", ColorGreen, ColorReset)
 	fmt.Printf("  • All backups tagged with \"commit: message\"\n")
 	fmt.Printf("  • Confirmation prompt before backing up\n")
 	
@@ -5157,6 +12435,7 @@ func printHelp() {
 	fmt.Printf("\n%s📄 IGNORE FILES:%s\n", ColorBold+ColorCyan, ColorReset)
 	fmt.Printf("  • %s.ptignore%s: PT-specific ignore patterns (higher priority)\n", ColorYellow, ColorReset)
 	fmt.Printf("  • %s.gitignore%s: Also respected for recursive search\n", ColorYellow, ColorReset)
+	fmt.Printf("  • %s.ptinclude%s: Whitelist; when present, status/commit/monitor only track matching files\n", ColorYellow, ColorReset)
 	fmt.Printf("  • Format: One pattern per line, # for comments\n")
 	fmt.Printf("  • %s%s/%s directory always excluded from search\n", ColorYellow, appConfig.BackupDirName, ColorReset)
 	
@@ -5209,6 +12488,7 @@ func printHelp() {
 	fmt.Printf("\n%s📄 IGNORE FILES:%s\n", ColorBold+ColorCyan, ColorReset)
 	fmt.Printf("  • %s.ptignore%s: PT-specific ignore patterns (higher priority)\n", ColorYellow, ColorReset)
 	fmt.Printf("  • %s.gitignore%s: Also respected for recursive search\n", ColorYellow, ColorReset)
+	fmt.Printf("  • %s.ptinclude%s: Whitelist; when present, status/commit/monitor only track matching files\n", ColorYellow, ColorReset)
 	fmt.Printf("  • Format: One pattern per line, # for comments\n")
 	fmt.Printf("  • %s%s/%s directory always excluded from search\n", ColorYellow, appConfig.BackupDirName, ColorReset)
 	
@@ -5272,6 +12552,131 @@ func printVersion() {
 	}
 }
 
+// defaultUpdateURL is the release feed pt version --check asks when
+// update_url is not set
+const defaultUpdateURL = "https://api.github.com/repos/cumulus13/pt-go/releases/latest"
+
+// updateCheckTimeout keeps pt version --check from hanging when offline
+const updateCheckTimeout = 5 * time.Second
+
+// isHTTPURL reports whether s is an absolute http or https URL
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// fetchLatestRelease asks a GitHub-style releases endpoint for the newest
+// release and returns its version (without a leading "v") and page URL
+func fetchLatestRelease(feedURL string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "pt/"+Version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		host := feedURL
+		if u, err := url.Parse(feedURL); err == nil {
+			host = u.Host
+		}
+		return "", "", fmt.Errorf("could not reach %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("%s answered %s", feedURL, resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&release); err != nil {
+		return "", "", fmt.Errorf("unexpected answer from %s: %w", feedURL, err)
+	}
+	if release.TagName == "" {
+		return "", "", fmt.Errorf("no release tag in the answer from %s", feedURL)
+	}
+
+	return strings.TrimPrefix(release.TagName, "v"), release.HTMLURL, nil
+}
+
+// compareVersions compares dotted versions such as 1.0.74 part by part.
+// Missing parts count as 0 and anything after a '-' or '+' is ignored.
+func compareVersions(a, b string) int {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(v, "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		var parts []int
+		for _, part := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(part)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	pa, pb := parse(a), parse(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkForUpdate reports whether a newer release than Version exists
+// (pt version --check). Nothing is downloaded.
+func checkForUpdate() error {
+	if appConfig.UpdateCheck != nil && !*appConfig.UpdateCheck {
+		fmt.Printf("%sUpdate checks are turned off (update_check: false)%s\n", ColorGray, ColorReset)
+		return nil
+	}
+
+	feedURL := appConfig.UpdateURL
+	if feedURL == "" {
+		feedURL = defaultUpdateURL
+	}
+	logger.Printf("Checking for updates at %s", feedURL)
+
+	latest, page, err := fetchLatestRelease(feedURL)
+	if err != nil {
+		return withHint(fmt.Errorf("update check failed: %w", err),
+			"check the network connection or proxy; set update_check: false to stop checking")
+	}
+
+	fmt.Println()
+	switch {
+	case Version == "dev":
+		fmt.Printf("%sRunning a development build; the latest release is %s%s\n", ColorYellow, latest, ColorReset)
+	case compareVersions(latest, Version) > 0:
+		fmt.Printf("%s⬆️  Update available: %s → %s%s\n", ColorGreen, Version, latest, ColorReset)
+	default:
+		fmt.Printf("%s✅ Up to date (latest release is %s)%s\n", ColorGreen, latest, ColorReset)
+		return nil
+	}
+	if page != "" {
+		fmt.Printf("   %s\n", page)
+	}
+	return nil
+}
+
 // ============================================================================
 // MAIN
 // ============================================================================
@@ -5689,8 +13094,14 @@ func parseArguments(args []string) *CommandInfo {
 		"backup": true, "-b": true, "commit": true, "config": true,
 		"-t": true, "--tree": true, "-rm": true, "--remove": true,
 		"-l": true, "--list": true, "-d": true, "--diff": true,
-		"-r": true, "--restore": true, "+": true,
+		"-r": true, "--restore": true, "restore": true, "+": true,
 		"-mt": true, "--monitor": true, "-dd": true, "--diff2": true,
+		"undo": true, "redo": true, "at": true,
+		"size": true, "pin": true, "unpin": true,
+		"init": true, "where": true, "split": true,
+		"gitdiff": true, "doctor": true, "commits": true,
+		"merge": true, "gc": true, "export": true,
+		"version": true, "-v": true, "--version": true,
 	}
 
 	// Value flags that take an argument
@@ -5700,6 +13111,26 @@ func parseArguments(args []string) *CommandInfo {
 		"--lexer": true, "-l": true,  // NOTE: "-l" conflict with list command!
 		"--theme": true, "-t": true,  // NOTE: "-t" conflict with tree command!
 		"-e": true, "--exception": true,
+		"--out": true,
+		"--format": true,  // For list: table or json
+		"--top": true,  // For size: number of files to show
+		"--since": true, "--until": true,  // For list: time range filter
+		"--context": true,  // For diff: lines of context
+		"--on-change": true,  // For monitor: command to run after a change
+		"--marker": true,  // For split: section header regexp
+		"--tabs": true,  // For show: expand tabs to N-column stops
+		"--keep": true,  // For write: per-file backup limit
+		"--find": true,  // For show: jump to the first matching line
+		"--output": true,  // For show: export as html or svg
+		"--max-lines": true,  // For show: stop after N lines
+		"--into": true,  // For restore: write the backup to another path
+		"--append": true,  // For -z: append the previewed clipboard to a file
+		"--save": true,    // For -z: write the previewed clipboard to a file
+		"--base": true,  // For merge: backup number of the common ancestor
+		"--sort": true,  // For tree: size, mtime or name
+		"--against": true,  // For diff: take the backups of another file
+		"--backup": true,  // For diff/restore: backup number from pt -l, no prompt
+		"--git": true,  // For export: output directory of the new git repository
 	}
 
 	// Boolean flags (standalone)
@@ -5709,7 +13140,47 @@ func parseArguments(args []string) *CommandInfo {
 		"--last": true, "-lt": true,
 		"--pager": true, "-p": true, "-np": true, "--no-pager": true,
 		"--no-line-numbers": true, "--no-grid": true,
-		"-r": true, "--recursive": true,  // For move command
+		"-r": true, "--recursive": true,  // For move and restore commands
+		"--follow-symlinks": true,
+		"--all": true, "-a": true,  // For commit and backup commands
+		"--yes": true, "-y": true,  // Skip confirmation prompts
+		"--preview": true,  // For restore: diff and confirm first; for -l: content snippets
+		"--amend": true,  // For commit: replace the last commit's backups
+		"--force": true,  // For commit: back up files over max_clipboard_size; for restore: skip the uncommitted-changes prompt
+		"--trash": true, "--no-trash": true,  // For -rm: use the OS trash or not
+		"--diff-adjacent": true,  // For -l: diff each pair of consecutive backups
+		"--oneline": true,  // For -l: one compact line per backup
+		"--follow": true, "-F": true,  // For show: keep printing appended lines
+		"--changes": true,  // For show: only the regions changed since the last backup
+		"--check": true,    // For version: look for a newer release
+		"--strip-ansi": true, // For write/append/-z: drop terminal escape codes from the clipboard
+		"--show": true,     // For check: show the changed regions of a modified file
+		"--ignore-whitespace": true,  // For check: whitespace-only edits count as unchanged
+		"--external": true,  // For diff: prefer a GUI diff tool
+		"--no-highlight": true,  // For diff with the internal renderer: +/- colors only
+		"--effective": true,  // For config show: resolved values and their sources
+		"--yaml": true,  // For config show: the effective config as a loadable pt.yml
+		"--cached": true,  // For gitdiff: diff the index instead of the working tree
+		"--stats": true, "--count": true,  // For -z: clipboard metadata only
+		"--diff": true,  // For show command (gutter markers)
+		"--plain": true,  // For show command (no highlighting)
+		"--wrap": true, "-w": true, "--no-wrap": true,  // For show command (soft-wrap long lines)
+		"--show-whitespace": true, "--highlight-trailing-whitespace": true,  // For show command
+		"--no-backup": true,  // For write: skip the backup step
+		"--binary": true,  // For write/append: allow non-text clipboard data
+		"--dry-run": true,  // For move: preview without touching the filesystem
+		"--merge": true,    // For move: overwrite an existing file and merge backup histories
+		"--preserve-structure": true, // For move: keep each file's directory under the destination
+		"--quiet": true, "-q": true,  // For monitor: periodic summary instead of per-event output
+		"--include-dotpt": true,  // For monitor: watch .pt directories that are not a backup store
+		"--json": true,  // For list: machine-readable output
+		"--word-diff": true,  // For diff with the internal pdiff renderer
+		"--here": true,  // For init: use the current directory, not the git root
+		"--print-path": true,  // For restore: print the backup that would be restored
+		"--summary-all": true,  // For diff: same as --all, a +/- count per modified file
+		"--reverse": true,  // For tree: flip the --sort order
+		"--clean": true,  // For fix: remove orphaned backups instead of reattaching them
+		"--newest": true, // For fix: reattach ambiguous orphans to their newest match
 	}
 
 	// CRITICAL: Flags that are ALSO commands (need special handling)
@@ -5853,6 +13324,11 @@ func parseArguments(args []string) *CommandInfo {
 		}
 	}
 
+	// "-z" alone is the clipboard preview command (pt -z -l python)
+	if info.Command == "" && info.BoolFlags["-z"] && len(info.Files) == 0 {
+		info.Command = "-z"
+	}
+
 	// Normalize flag aliases (use canonical form)
 	if msg, ok := info.Flags["--message"]; ok && info.Flags["-m"] == "" {
 		info.Flags["-m"] = msg
@@ -5895,49 +13371,91 @@ func setGlobalFlags(info *CommandInfo) {
 	if info.BoolFlags["-c"] {
 		checkBefore = true
 	}
+	if appConfig.FollowSymlinks || info.BoolFlags["--follow-symlinks"] {
+		followSymlinks = true
+	}
 	if tool, ok := info.Flags["-T"]; ok {
 		difftool = tool
 	}
 	if tool, ok := info.Flags["--tool"]; ok {
 		difftool = tool
 	}
+	if info.BoolFlags["--external"] {
+		diffExternal = true
+	}
+	if info.BoolFlags["--no-highlight"] {
+		diffNoHighlight = true
+	}
 }
 
 // Handler wrappers using CommandInfo
 
 func handleShowWithInfo(info *CommandInfo) error {
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required for show command%s\n", ColorRed, ColorReset)
-		fmt.Println("\nUsage:")
-		fmt.Println("  pt show <filename>")
-		fmt.Println("  pt <filename> show --lexer <type> --theme <theme>")
-		fmt.Println("  pt show <filename> --pager")
-		os.Exit(1)
+		return withHint(fmt.Errorf("filename required for show command"),
+			"usage: pt show <filename> [--lexer <type>] [--theme <theme>] [--pager]")
 	}
 
 	// Reconstruct args for existing handler
 	args := []string{info.Files[0]}
 	if lexer, ok := info.Flags["--lexer"]; ok {
 		args = append(args, "--lexer", lexer)
+	} else if lexer, ok := info.Flags["-l"]; ok {
+		args = append(args, "--lexer", lexer)
 	}
 	if theme, ok := info.Flags["--theme"]; ok {
 		args = append(args, "--theme", theme)
 	}
-	if info.BoolFlags["--pager"] {
+	if info.BoolFlags["--pager"] || info.BoolFlags["-p"] {
 		args = append(args, "--pager")
 	}
+	if info.BoolFlags["--no-pager"] || info.BoolFlags["-np"] {
+		args = append(args, "--no-pager")
+	}
+	if info.BoolFlags["--diff"] {
+		args = append(args, "--diff")
+	}
+	if info.BoolFlags["--plain"] {
+		args = append(args, "--plain")
+	}
+	if info.BoolFlags["--wrap"] || info.BoolFlags["-w"] {
+		args = append(args, "--wrap")
+	}
+	if n, ok := info.Flags["--tabs"]; ok {
+		args = append(args, "--tabs", n)
+	}
+	if n, ok := info.Flags["--max-lines"]; ok {
+		args = append(args, "--max-lines", n)
+	}
+	if text, ok := info.Flags["--find"]; ok {
+		args = append(args, "--find", text)
+	}
+	if format, ok := info.Flags["--output"]; ok {
+		args = append(args, "--output", format)
+	}
+	if out, ok := info.Flags["--out"]; ok {
+		args = append(args, "--out", out)
+	}
+	if n, ok := info.Flags["--context"]; ok {
+		args = append(args, "--context", n)
+	}
+	if info.BoolFlags["--show-whitespace"] || info.BoolFlags["--highlight-trailing-whitespace"] {
+		args = append(args, "--show-whitespace")
+	}
+	if info.BoolFlags["--follow"] || info.BoolFlags["-F"] {
+		args = append(args, "--follow")
+	}
+	if info.BoolFlags["--changes"] {
+		args = append(args, "--changes")
+	}
 
 	return handleShowCommand(args)
 }
 
 func handleMoveWithInfo(info *CommandInfo) error {
 	if len(info.Files) < 2 {
-		fmt.Printf("%s❌ Error: At least source and destination required%s\n", ColorRed, ColorReset)
-		fmt.Println("\nUsage:")
-		fmt.Println("  pt move <source> <destination>")
-		fmt.Println("  pt <source> move <destination>")
-		fmt.Println("  pt <source1> <source2> move <destination> -m \"comment\"")
-		os.Exit(1)
+		return withHint(fmt.Errorf("at least source and destination required"),
+			"usage: pt move <source>... <destination> [-m \"comment\"]")
 	}
 
 	args := info.Files
@@ -5950,12 +13468,31 @@ func handleMoveWithInfo(info *CommandInfo) error {
 	if info.BoolFlags["-r"] || info.BoolFlags["--recursive"] {
 		args = append(args, "-r")
 	}
+	if info.BoolFlags["--dry-run"] {
+		args = append(args, "--dry-run")
+	}
+	if info.BoolFlags["--merge"] {
+		args = append(args, "--merge")
+	}
+	if info.BoolFlags["--preserve-structure"] {
+		args = append(args, "--preserve-structure")
+	}
 
-	return handleMoveCommand(args)
+	return handleMoveCommand(interruptCtx, args)
 }
 
 func handleFixWithInfo(info *CommandInfo) error {
-	return handleFixCommand(info.Files)
+	args := info.Files
+	if info.BoolFlags["--yes"] || info.BoolFlags["-y"] {
+		args = append(args, "--yes")
+	}
+	if info.BoolFlags["--clean"] {
+		args = append(args, "--clean")
+	}
+	if info.BoolFlags["--newest"] {
+		args = append(args, "--newest")
+	}
+	return handleFixCommand(args)
 }
 
 func handleTempWithInfo(info *CommandInfo) error {
@@ -5969,17 +13506,53 @@ func handleTempWithInfo(info *CommandInfo) error {
 	if info.BoolFlags["--pager"] {
 		args = append(args, "--pager")
 	}
+	if info.BoolFlags["--stats"] || info.BoolFlags["--count"] {
+		args = append(args, "--stats")
+	}
+	if info.BoolFlags["--strip-ansi"] {
+		args = append(args, "--strip-ansi")
+	}
+	for _, flag := range []string{"--append", "--save"} {
+		if path, ok := info.Flags[flag]; ok {
+			args = append(args, flag, path)
+		}
+	}
+	if info.Flags["--append"] != "" || info.Flags["--save"] != "" {
+		if comment := info.Flags["-m"]; comment != "" {
+			args = append(args, "-m", comment)
+		}
+		if info.BoolFlags["--yes"] || info.BoolFlags["-y"] {
+			args = append(args, "--yes")
+		}
+	}
 	return handleTempCommand(args)
 }
 
 func handleCheckWithInfo(info *CommandInfo) error {
-	return handleCheckCommand(info.Files)
+	args := info.Files
+	if info.BoolFlags["--show"] {
+		args = append(args, "--show")
+	}
+	if n, ok := info.Flags["--context"]; ok {
+		args = append(args, "--context", n)
+	}
+	if info.BoolFlags["--ignore-whitespace"] {
+		args = append(args, "--ignore-whitespace")
+	}
+	return handleCheckCommand(args)
 }
 
 func handleBackupWithInfo(info *CommandInfo) error {
+	if info.BoolFlags["--all"] || info.BoolFlags["-a"] {
+		comment := info.Flags["-m"]
+		if comment == "" {
+			comment = info.Flags["--message"]
+		}
+		return handleBackupAllCommand(interruptCtx, comment)
+	}
+
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required%s\n", ColorRed, ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("filename required")
 	}
 
 	filename := info.Files[0]
@@ -5999,14 +13572,12 @@ func handleBackupWithInfo(info *CommandInfo) error {
 		text, err := os.ReadFile(filePath)
 
 		if err != nil {
-			fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-			os.Exit(1)
+			return err
 		}
 
 		backups, err := listBackups(filePath)
 	    if err != nil {
-	    	fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-	        os.Exit(1)
+	        return err
 	    }
 
 	    if len(backups) == 0 {
@@ -6014,8 +13585,7 @@ func handleBackupWithInfo(info *CommandInfo) error {
 	        // err = writeFile(filePath, text, false, checkBefore, comment)
 	        _, err = autoRenameIfExists(filePath, comment, false)
 			if err != nil {
-				fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-				os.Exit(1)
+				return err
 			}
 	    } else {
 		    var selectedBackup BackupInfo
@@ -6025,15 +13595,13 @@ func handleBackupWithInfo(info *CommandInfo) error {
 		    
 			
 			if !checkIfDifferent(selectedBackup.Path, text) {
-				fmt.Printf("⚠ %sLast backup:%s %s%s%s%s %sand clipboard is identical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, selectedBackup.Name, ColorReset, ColorYellow, ColorReset)
-				os.Exit(1)
+				return fmt.Errorf("%s is identical to its last backup %s", filePath, selectedBackup.Name)
 			}
 
 			// err = writeFile(filePath, text, false, checkBefore, comment)
 			_, err = autoRenameIfExists(filePath, comment, false)
 			if err != nil {
-				fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-				os.Exit(1)
+				return err
 			}
 		}
 	} else {
@@ -6052,19 +13620,33 @@ func handleCommitWithInfo(info *CommandInfo) error {
 	if msg, ok := info.Flags["--message"]; ok {
 		args = append(args, "--message", msg)
 	}
-	return handleCommitCommand(args)
+	if info.BoolFlags["--all"] || info.BoolFlags["-a"] {
+		args = append(args, "--all")
+	}
+	if info.BoolFlags["--yes"] || info.BoolFlags["-y"] {
+		args = append(args, "--yes")
+	}
+	if info.BoolFlags["--amend"] {
+		args = append(args, "--amend")
+	}
+	if info.BoolFlags["--force"] {
+		args = append(args, "--force")
+	}
+	return handleCommitCommand(interruptCtx, args)
 }
 
 func handleConfigWithInfo(info *CommandInfo) error {
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Config subcommand required%s\n", ColorRed, ColorReset)
-		fmt.Println("\nAvailable subcommands:")
-		fmt.Println("  pt config init [path]")
-		fmt.Println("  pt config show")
-		fmt.Println("  pt config path")
-		os.Exit(1)
+		return withHint(fmt.Errorf("config subcommand required"),
+			"available: init [path], show [--effective | --yaml], path, validate [path], edit [path]")
+	}
+	args := info.Files
+	if info.BoolFlags["--effective"] {
+		args = append(args, "--effective")
+	} else if info.BoolFlags["--yaml"] {
+		args = append(args, "--yaml")
 	}
-	return handleConfigCommand(info.Files)
+	return handleConfigCommand(args)
 }
 
 func handleTreeWithInfo(info *CommandInfo) error {
@@ -6075,13 +13657,18 @@ func handleTreeWithInfo(info *CommandInfo) error {
 	if exc, ok := info.Flags["--exception"]; ok {
 		args = append(args, "--exception", exc)
 	}
+	if by, ok := info.Flags["--sort"]; ok {
+		args = append(args, "--sort", by)
+	}
+	if info.BoolFlags["--reverse"] {
+		args = append(args, "--reverse")
+	}
 	return handleTreeCommand(args)
 }
 
 func handleRemoveWithInfo(info *CommandInfo) error {
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required%s\n", ColorRed, ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("filename required")
 	}
 	
 	args := info.Files
@@ -6091,14 +13678,76 @@ func handleRemoveWithInfo(info *CommandInfo) error {
 	if msg, ok := info.Flags["--message"]; ok {
 		args = append(args, "--message", msg)
 	}
+	if info.BoolFlags["--trash"] {
+		args = append(args, "--trash")
+	}
+	if info.BoolFlags["--no-trash"] {
+		args = append(args, "--no-trash")
+	}
+	if info.BoolFlags["-r"] || info.BoolFlags["--recursive"] {
+		args = append(args, "-r")
+	}
+	if info.BoolFlags["--dry-run"] {
+		args = append(args, "--dry-run")
+	}
+	if info.BoolFlags["--yes"] || info.BoolFlags["-y"] {
+		args = append(args, "--yes")
+	}
 	
 	return handleRemoveCommand(args)
 }
 
+func handlePinWithInfo(info *CommandInfo) error {
+	if len(info.Files) < 2 {
+		return withHint(fmt.Errorf("filename and backup number required"),
+			fmt.Sprintf("usage: pt %s <filename> <N> (N as numbered by pt -l)", info.Command))
+	}
+
+	filePath, err := resolveFilePath(info.Files[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := strconv.Atoi(info.Files[1])
+	if err != nil {
+		return fmt.Errorf("invalid backup number: %s", info.Files[1])
+	}
+
+	return handlePinCommand(filePath, n, info.Command == "pin")
+}
+
+func handleSplitWithInfo(info *CommandInfo) error {
+	fromStdin := len(info.Files) > 0 && info.Files[0] == "-"
+	comment := info.Flags["-m"]
+	if comment == "" {
+		comment = info.Flags["--message"]
+	}
+	return handleSplitCommand(interruptCtx, fromStdin, info.Flags["--marker"], comment, info.BoolFlags["--dry-run"])
+}
+
+func handleSizeWithInfo(info *CommandInfo) error {
+	top := 0
+	if value, ok := info.Flags["--top"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--top requires a positive number, got %q", value)
+		}
+		top = n
+	}
+	return handleSizeCommand(top)
+}
+
+func handleExportWithInfo(info *CommandInfo) error {
+	outDir := info.Flags["--git"]
+	if outDir == "" {
+		return withHint(fmt.Errorf("output directory required: pt export --git <outdir> <file>"), "git is the only export format")
+	}
+	return handleExportGitCommand(outDir, info.Files, info.BoolFlags["--all"] || info.BoolFlags["-a"])
+}
+
 func handleListWithInfo(info *CommandInfo) error {
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required%s\n", ColorRed, ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("filename required")
 	}
 
 	filePath, err := resolveFilePath(info.Files[0])
@@ -6111,25 +13760,214 @@ func handleListWithInfo(info *CommandInfo) error {
 		return err
 	}
 
-	if len(backups) == 0 {
+	backups, err = filterBackupsByTime(backups, info.Flags["--since"], info.Flags["--until"])
+	if err != nil {
+		return err
+	}
+
+	asJSON := info.BoolFlags["--json"]
+	if format, ok := info.Flags["--format"]; ok {
+		switch format {
+		case "json":
+			asJSON = true
+		case "oneline":
+			info.BoolFlags["--oneline"] = true
+		case "table":
+		default:
+			return fmt.Errorf("unknown list format: %s (use 'table', 'oneline' or 'json')", format)
+		}
+	}
+
+	if asJSON {
+		return printBackupJSON(backups)
+	}
+
+	if info.BoolFlags["--diff-adjacent"] {
+		if len(backups) < 2 {
+			fmt.Printf("ℹ️  Need at least two backups of %s to compare\n", filePath)
+			return nil
+		}
+		toolFlag := info.Flags["--tool"]
+		if toolFlag == "" {
+			toolFlag = info.Flags["-T"]
+		}
+		if n, ok := info.Flags["--context"]; ok {
+			if diffContext, err = parseContextArg(n); err != nil {
+				return err
+			}
+		}
+		return printAdjacentDiffs(filePath, backups, toolFlag, info.BoolFlags["--pager"])
+	}
+
+	if len(backups) == 0 && (info.Flags["--since"] != "" || info.Flags["--until"] != "") {
+		fmt.Printf("ℹ️  No backups of %s in the given time range\n", filePath)
+	} else if len(backups) == 0 {
 		fmt.Printf("ℹ️  No backups found for: %s (check %s/ directory)\n", filePath, appConfig.BackupDirName)
+	} else if info.BoolFlags["--oneline"] {
+		printBackupOneline(backups)
 	} else {
-		printBackupTable(filePath, backups)
+		printBackupTableWithPreview(filePath, backups, info.BoolFlags["--preview"])
+	}
+	return nil
+}
+
+// printAdjacentDiffs shows what changed between each pair of consecutive
+// backups of filePath, newest first, under a header naming both backups as
+// numbered by pt -l. The built-in diff renders into a buffer so it can go
+// through the pager; external tools print directly and page on their own.
+func printAdjacentDiffs(filePath string, backups []BackupInfo, toolFlag string, usePager bool) error {
+	toolName := resolveDiffTool(toolFlag)
+	builtin := toolName == "pdiff" || toolName == "pdiff2"
+
+	var output bytes.Buffer
+	for i := 0; i+1 < len(backups); i++ {
+		if interruptCtx.Err() != nil {
+			return errInterrupted
+		}
+		newer, older := backups[i], backups[i+1]
+
+		header := fmt.Sprintf("\n%s━━━ #%d %s%s", ColorBold+ColorCyan, i+2, formatTime(older.ModTime), ColorReset)
+		if older.Comment != "" {
+			header += fmt.Sprintf(" %s%q%s", ColorMagenta, older.Comment, ColorReset)
+		}
+		header += fmt.Sprintf(" %s→ #%d %s%s", ColorBold+ColorCyan, i+1, formatTime(newer.ModTime), ColorReset)
+		if newer.Comment != "" {
+			header += fmt.Sprintf(" %s%q%s", ColorMagenta, newer.Comment, ColorReset)
+		}
+		header += "\n\n"
+
+		if !builtin {
+			fmt.Print(header)
+			if !checkIfDifferent(older.Path, newer.Path) {
+				fmt.Printf("%sNo changes.%s\n", ColorGray, ColorReset)
+				continue
+			}
+			err := runDiff(toolName, older.Path, newer.Path, false)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, errToolMissing) {
+				return err
+			}
+			// The tool isn't installed: use the built-in diff from here on
+			fmt.Printf("%s⚠️  %v, using the built-in diff%s\n", ColorYellow, err, ColorReset)
+			builtin = true
+			header = ""
+		}
+
+		output.WriteString(header)
+		pdiff := &PDiff2{Context: contextOrDefault(3), Out: &output, NoHighlight: diffNoHighlight, Filename: filePath}
+		diff, err := pdiff.DiffFiles(older.Path, newer.Path)
+		if err != nil {
+			return fmt.Errorf("diff failed: %w", err)
+		}
+		pdiff.PrintDiff(diff)
+	}
+
+	if output.Len() == 0 {
+		return nil
+	}
+	if usePager {
+		return displayWithPager(output.String())
+	}
+	fmt.Print(output.String())
+	return nil
+}
+
+// filterBackupsByTime keeps the backups modified within [since, until].
+// Both bounds are optional and accept anything parseTimeArg does; a bare
+// date as until includes that whole day.
+func filterBackupsByTime(backups []BackupInfo, since, until string) ([]BackupInfo, error) {
+	if since == "" && until == "" {
+		return backups, nil
+	}
+
+	var from, to time.Time
+	if since != "" {
+		t, err := parseTimeArg(since)
+		if err != nil {
+			return nil, fmt.Errorf("--since: %w", err)
+		}
+		from = t
+	}
+	if until != "" {
+		t, err := parseTimeArg(until)
+		if err != nil {
+			return nil, fmt.Errorf("--until: %w", err)
+		}
+		if _, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(until), displayLocation()); err == nil {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		to = t
+	}
+
+	filtered := make([]BackupInfo, 0, len(backups))
+	for _, backup := range backups {
+		if !from.IsZero() && backup.ModTime.Before(from) {
+			continue
+		}
+		if !to.IsZero() && backup.ModTime.After(to) {
+			continue
+		}
+		filtered = append(filtered, backup)
+	}
+	return filtered, nil
+}
+
+// backupListEntry is one element of pt -l --json output
+type backupListEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	ModTime time.Time `json:"modTime"`
+	Size    int64     `json:"size"`
+	Comment string    `json:"comment"`
+	Pinned  bool      `json:"pinned"`
+}
+
+// printBackupJSON writes the backups, newest first, as a JSON array
+// (an empty array when there are none)
+func printBackupJSON(backups []BackupInfo) error {
+	entries := make([]backupListEntry, 0, len(backups))
+	for _, backup := range backups {
+		entries = append(entries, backupListEntry{
+			Name:    backup.Name,
+			Path:    backup.Path,
+			ModTime: backup.ModTime,
+			Size:    backup.Size,
+			Comment: backup.Comment,
+			Pinned:  backup.Pinned,
+		})
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup list: %w", err)
 	}
+	fmt.Println(string(data))
 	return nil
 }
 
 func handleDiffWithInfo(info *CommandInfo) error {
+	// --all summarizes every modified file; the arguments narrow it down
+	if info.BoolFlags["--all"] || info.BoolFlags["-a"] || info.BoolFlags["--summary-all"] {
+		return handleDiffAllCommand(info.Files)
+	}
+
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required%s\n", ColorRed, ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("filename required")
 	}
 
 	fileName := info.Files[0]
 
 	// Check if -z flag is present
 	if info.BoolFlags["-z"] {
-		return handleDiffClipboardToFile(fileName)
+		if n, ok := info.Flags["--context"]; ok {
+			var err error
+			if diffContext, err = parseContextArg(n); err != nil {
+				return err
+			}
+		}
+		return handleDiffClipboardToFile(fileName, info.BoolFlags["--last"] || info.BoolFlags["-lt"])
 	}
 
 	// Regular diff command
@@ -6137,9 +13975,36 @@ func handleDiffWithInfo(info *CommandInfo) error {
 	if info.BoolFlags["--last"] || info.BoolFlags["-lt"] {
 		args = append(args, "--last")
 	}
+	if info.BoolFlags["--word-diff"] {
+		args = append(args, "--word-diff")
+	}
+	if tool, ok := info.Flags["--tool"]; ok {
+		args = append(args, "--tool", tool)
+	} else if tool, ok := info.Flags["-T"]; ok {
+		args = append(args, "--tool", tool)
+	}
+	if n, ok := info.Flags["--context"]; ok {
+		args = append(args, "--context", n)
+	}
+	if other, ok := info.Flags["--against"]; ok {
+		args = append(args, "--against", other)
+	}
+	if n, ok := info.Flags["--backup"]; ok {
+		args = append(args, "--backup", n)
+	}
 	return handleDiffCommand(args)
 }
 
+func handleGitDiffWithInfo(info *CommandInfo) error {
+	if n, ok := info.Flags["--context"]; ok {
+		var err error
+		if diffContext, err = parseContextArg(n); err != nil {
+			return err
+		}
+	}
+	return handleGitDiffCommand(info.Files, info.BoolFlags["--cached"], info.BoolFlags["--word-diff"])
+}
+
 func handleDiffWithInfo2(info *CommandInfo) error {
 	useClipboard := false
 	if info.BoolFlags["-z"] {
@@ -6152,14 +14017,19 @@ func handleDiffWithInfo2(info *CommandInfo) error {
 	if info.BoolFlags["--last"] || info.BoolFlags["-lt"] {
 		args = append(args, "--last")
 	}
+	if info.BoolFlags["--word-diff"] {
+		args = append(args, "--word-diff")
+	}
+	if n, ok := info.Flags["--context"]; ok {
+		args = append(args, "--context", n)
+	}
 	
 	return handleDiffCommand2(args, &useClipboard)
 }
 
 func handleRestoreWithInfo(info *CommandInfo) error {
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required%s\n", ColorRed, ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("filename required")
 	}
 
 	filename := info.Files[0]
@@ -6169,6 +14039,31 @@ func handleRestoreWithInfo(info *CommandInfo) error {
 	}
 	useLast := info.BoolFlags["--last"] || info.BoolFlags["-lt"]
 
+	into := info.Flags["--into"]
+	if into != "" {
+		if absInto, err := filepath.Abs(into); err == nil {
+			into = absInto
+		}
+	}
+
+	if info.BoolFlags["-r"] || info.BoolFlags["--recursive"] {
+		if into != "" {
+			return fmt.Errorf("--into restores a single file and cannot be combined with -r")
+		}
+		if info.BoolFlags["--print-path"] {
+			return fmt.Errorf("--print-path resolves a single file's backup and cannot be combined with -r")
+		}
+		return handleRestoreDirCommand(filename, comment, useLast || info.BoolFlags["--yes"] || info.BoolFlags["-y"])
+	}
+	if st, err := os.Stat(filename); err == nil && st.IsDir() {
+		return fmt.Errorf("%s is a directory, use: pt restore -r %s", filename, filename)
+	}
+
+	// Scripts capture the path from stdout; keep the notes on the way there off it
+	if info.BoolFlags["--print-path"] {
+		infoOut = os.Stderr
+	}
+
 	filePath, err := resolveFilePath(filename)
 	if err != nil {
 		filePath = filename
@@ -6184,16 +14079,75 @@ func handleRestoreWithInfo(info *CommandInfo) error {
 	}
 
 	if len(backups) == 0 {
-		fmt.Printf("%s❌ Error: No backups found for: %s (check %s/ directory)%s\n",
-			ColorRed, filePath, appConfig.BackupDirName, ColorReset)
-		os.Exit(1)
+		return withHint(fmt.Errorf("%w for: %s (check %s/ directory)", errNoBackups, filePath, appConfig.BackupDirName),
+			fmt.Sprintf("nothing to restore yet; back it up with 'pt backup %s' or 'pt commit'", filename))
 	}
 
-	if useLast {
+	// --backup N picks a backup like --last does, without the prompt
+	var numbered *BackupInfo
+	if value, ok := info.Flags["--backup"]; ok {
+		n, err := parseBackupNumber(value)
+		if err != nil {
+			return err
+		}
+		backup, err := backupNumbered(backups, filePath, n)
+		if err != nil {
+			return err
+		}
+		numbered = &backup
+	} else if useLast {
+		numbered = &backups[0]
+	}
+
+	// --print-path only reports which backup would be restored, for scripts
+	if info.BoolFlags["--print-path"] {
+		if numbered == nil {
+			return fmt.Errorf("--print-path needs --last or --backup N to pick a backup without asking")
+		}
+		fmt.Println(numbered.Path)
+		return nil
+	}
+
+	preview := info.BoolFlags["--preview"]
+	toolFlag := info.Flags["--tool"]
+	if toolFlag == "" {
+		toolFlag = info.Flags["-T"]
+	}
+	assumeYes := info.BoolFlags["--yes"] || info.BoolFlags["-y"]
+
+	// With --into, preview against the target if it already exists
+	previewPath := filePath
+	if into != "" {
+		if _, err := os.Stat(into); err == nil {
+			previewPath = into
+		}
+	}
+	force := info.BoolFlags["--force"]
+	restore := func(backup BackupInfo, comment string) error {
+		if into != "" {
+			return restoreBackupInto(backup.Path, filePath, into, comment)
+		}
+		if !force {
+			if ok, err := confirmRestoreOverChanges(filePath, assumeYes); !ok {
+				return err
+			}
+		}
+		return restoreBackup(backup.Path, filePath, comment)
+	}
+
+	if numbered != nil {
+		if preview {
+			if ok, err := previewRestore(*numbered, previewPath, toolFlag, assumeYes); !ok {
+				return err
+			}
+		}
 		if comment == "" {
 			comment = "Restored from last backup"
+			if _, ok := info.Flags["--backup"]; ok {
+				comment = "Restored from backup"
+			}
 		}
-		return restoreBackup(backups[0].Path, filePath, comment)
+		return restore(*numbered, comment)
 	}
 
 	printBackupTable(filePath, backups)
@@ -6204,20 +14158,36 @@ func handleRestoreWithInfo(info *CommandInfo) error {
 
 	if choice == 0 {
 		fmt.Println("❌ Restore cancelled")
-		os.Exit(0)
+		return nil
 	}
 
 	selectedBackup := backups[choice-1]
+	if preview {
+		if ok, err := previewRestore(selectedBackup, previewPath, toolFlag, assumeYes); !ok {
+			return err
+		}
+	}
 	if comment == "" {
 		comment = "Restored from backup"
 	}
-	return restoreBackup(selectedBackup.Path, filePath, comment)
+	return restore(selectedBackup, comment)
+}
+
+func handleAtWithInfo(info *CommandInfo) error {
+	args := info.Files
+	if out, ok := info.Flags["--out"]; ok {
+		args = append(args, "--out", out)
+	}
+	return handleAtCommand(args)
+}
+
+func handleUndoWithInfo(info *CommandInfo) error {
+	return handleUndoCommand(info.Files, info.Command == "redo")
 }
 
 func handleAppendWithInfo(info *CommandInfo) error {
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required%s\n", ColorRed, ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("filename required")
 	}
 
 	text, err := getClipboardText()
@@ -6226,8 +14196,16 @@ func handleAppendWithInfo(info *CommandInfo) error {
 	}
 
 	if text == "" {
-		fmt.Printf("%s⚠️  Warning: Clipboard is empty%s\n", ColorYellow, ColorReset)
-		os.Exit(1)
+		return errClipboardEmpty
+	}
+
+	// Copied terminal output carries its colors along
+	if info.BoolFlags["--strip-ansi"] {
+		text = stripANSI(text)
+	}
+
+	if err := checkClipboardText(text, info.BoolFlags["--binary"]); err != nil {
+		return err
 	}
 
 	filename := info.Files[0]
@@ -6248,7 +14226,7 @@ func handleAppendWithInfo(info *CommandInfo) error {
 
 	if len(backups) == 0 {
 	    fmt.Errorf("no backups found for: %s (check %s/ directory)", filePath, appConfig.BackupDirName)
-	    return writeFile(filePath, text, true, false, comment)
+	    return writeFile(filePath, text, true, false, comment, false)
 		// if err != nil {
 		// 	fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
 		// 	os.Exit(1)
@@ -6261,12 +14239,11 @@ func handleAppendWithInfo(info *CommandInfo) error {
 	    
 		
 		if !checkIfDifferent(selectedBackup.Path, text) {
-			fmt.Printf("⚠️ %sLast backup:%s %s%s%s%s %sand clipboard is identical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, selectedBackup.Name, ColorReset, ColorYellow, ColorReset)
-			os.Exit(1)
+			return fmt.Errorf("last backup %s and clipboard are identical", selectedBackup.Name)
 		}
 
 		// func writeFile(filePath string, data string, appendMode bool, checkMode bool, comment string) 
-		return writeFile(filePath, text, true, false, comment)
+		return writeFile(filePath, text, true, false, comment, false)
 		// if err != nil {
 		// 	fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
 		// 	os.Exit(1)
@@ -6274,21 +14251,29 @@ func handleAppendWithInfo(info *CommandInfo) error {
 	}
 }
 
-func handleDefaultWrite(info *CommandInfo) {
+// handleDefaultWrite writes the clipboard to a file (pt <file>), backing up
+// the previous content first
+func handleDefaultWrite(info *CommandInfo) error {
 	text, err := getClipboardText()
 	if err != nil {
-		fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-		os.Exit(1)
+		return err
 	}
 
 	if text == "" {
-		fmt.Printf("%s⚠️  Warning: Clipboard is empty%s\n", ColorYellow, ColorReset)
-		os.Exit(1)
+		return errClipboardEmpty
+	}
+
+	// Copied terminal output carries its colors along
+	if info.BoolFlags["--strip-ansi"] {
+		text = stripANSI(text)
+	}
+
+	if err := checkClipboardText(text, info.BoolFlags["--binary"]); err != nil {
+		return err
 	}
 
 	if len(info.Files) == 0 {
-		fmt.Printf("%s❌ Error: Filename required%s\n", ColorRed, ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("filename required")
 	}
 
 	filename := info.Files[0]
@@ -6296,59 +14281,34 @@ func handleDefaultWrite(info *CommandInfo) {
 	if comment == "" {
 		comment = info.Flags["--message"]
 	}
+	noBackup := info.BoolFlags["--no-backup"]
 
 	filePath, err := resolveFilePath(filename)
 	if err != nil {
 		filePath = filename
 	}
 
+	// --keep N stores a per-file backup limit, whether or not the content changed
+	if value, ok := info.Flags["--keep"]; ok {
+		keep, err := strconv.Atoi(value)
+		if err != nil || keep < 1 || keep > 10000 {
+			return fmt.Errorf("--keep requires a number from 1 to 10000, got %q", value)
+		}
+		if err := setBackupKeep(filePath, keep); err != nil {
+			return err
+		}
+		fmt.Printf("📌 Keeping up to %s%d%s backups of %s\n", ColorYellow, keep, ColorReset, filename)
+	}
+
 	if checkBefore {
 		fmt.Printf("🔍 Check mode enabled - will skip if content identical\n")
 	}
 
 	if !checkIfDifferent(filePath, text) {
-		fmt.Printf(" ⚠ %sFile:%s %s%s%s%s %sand clipboard is identical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, filePath, ColorReset, ColorYellow, ColorReset)
-		os.Exit(1)
-	} else {
-		err = writeFile(filePath, text, false, checkBefore, comment)
-		if err != nil {
-			fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-		}
-		os.Exit(1)
+		return fmt.Errorf("%s and clipboard are identical", filePath)
 	}
 
-	backups, err := listBackups(filePath)
-    if err != nil {
-    	fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-        os.Exit(1)
-    }
-
-    if len(backups) == 0 {
-        fmt.Printf("no backups found for: %s (check %s/ directory)", filePath, appConfig.BackupDirName)
-        err = writeFile(filePath, text, false, checkBefore, comment)
-		if err != nil {
-			fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-			os.Exit(1)
-		}
-    } else {
-	    var selectedBackup BackupInfo
-
-	    selectedBackup = backups[0]
-        fmt.Printf("%s📊 Comparing with last backup: %s%s\n\n", ColorCyan, selectedBackup.Name, ColorReset)
-	    
-		
-		if !checkIfDifferent(selectedBackup.Path, text) {
-			fmt.Printf(" ⚠ %sLast backup:%s %s%s%s%s %sand clipboard is identical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, selectedBackup.Name, ColorReset, ColorYellow, ColorReset)
-			os.Exit(1)
-		}
-
-		// func writeFile(filePath string, data string, appendMode bool, checkMode bool, comment string) 
-		err = writeFile(filePath, text, false, checkBefore, comment)
-		if err != nil {
-			fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-			os.Exit(1)
-		}
-	}
+	return writeFile(filePath, text, false, checkBefore, comment, noBackup)
 }
 
 
@@ -6378,11 +14338,29 @@ func main() {
 
 	// Setup logger
 	setupLogger()
+	logger.Printf("Config source: %s", configSource)
 
-	// If no command found, treat as default write command
-	if info.Command == "" {
-		handleDefaultWrite(info)
-		return
+	// Monitor mode keeps its own shutdown path (tray menu / Ctrl+C)
+	if info.Command != "-mt" && info.Command != "--monitor" {
+		installInterruptHandler()
+	}
+
+	if err := runCommand(info); err != nil {
+		printError(err)
+		os.Exit(exitCodeFor(err))
+	}
+}
+
+// runCommand routes info to its handler. Commands that modify the backup
+// store hold its lock until the handler returns, so the lock is released on
+// every path that leads back to main's exit.
+func runCommand(info *CommandInfo) error {
+	// Serialize commands that modify the backup store
+	if mutatingCommands[info.Command] || (info.Command == "-z" && (info.Flags["--append"] != "" || info.Flags["--save"] != "")) {
+		if err := acquirePTLock(lockTarget(info)); err != nil {
+			return err
+		}
+		defer releasePTLock()
 	}
 
 	// Route to appropriate handler
@@ -6414,16 +14392,55 @@ func main() {
 		err = handleDiffWithInfo(info)
 	case "-dd", "--diff2":
 		err = handleDiffWithInfo2(info)
-	case "-r", "--restore":
+	case "-r", "--restore", "restore":
 		err = handleRestoreWithInfo(info)
+	case "undo", "redo":
+		err = handleUndoWithInfo(info)
+	case "at":
+		err = handleAtWithInfo(info)
 	case "+":
 		err = handleAppendWithInfo(info)
 	case "-mt", "--monitor":
 		err = handleMonitorWithInfo(info)
+	case "split":
+		err = handleSplitWithInfo(info)
+	case "gitdiff":
+		err = handleGitDiffWithInfo(info)
+	case "doctor":
+		err = handleDoctorCommand()
+	case "commits":
+		err = handleCommitsCommand(info.Files, info.BoolFlags["--pager"])
+	case "merge":
+		if len(info.Files) == 0 {
+			err = fmt.Errorf("filename required: pt merge <file>")
+		} else {
+			err = handleMergeCommand(info.Files[0], info.Flags["-T"], info.Flags["--base"], info.BoolFlags["--last"])
+		}
+	case "size":
+		err = handleSizeWithInfo(info)
+	case "gc":
+		err = handleGCCommand()
+	case "export":
+		err = handleExportWithInfo(info)
+	case "version", "-v", "--version":
+		printVersion()
+		if info.BoolFlags["--check"] {
+			err = checkForUpdate()
+		}
+	case "pin", "unpin":
+		err = handlePinWithInfo(info)
+	case "init":
+		err = handleInitCommand(info.BoolFlags["--here"])
+	case "where":
+		if len(info.Files) == 0 {
+			err = fmt.Errorf("filename required: pt where <file>")
+		} else {
+			err = handleWhereCommand(info.Files[0])
+		}
+	case "":
+		// No command found, treat as default write command
+		err = handleDefaultWrite(info)
 	}
 
-	if err != nil {
-		fmt.Printf("%s❌ Error: %v%s\n", ColorRed, err, ColorReset)
-		os.Exit(1)
-	}
+	return err
 }
\ No newline at end of file
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+// TestMain sets up what main() normally does before any command runs: the
+// built-in configuration and a logger that discards its output
+func TestMain(m *testing.M) {
+	appConfig = getDefaultConfig()
+	logger = log.New(io.Discard, "", 0)
+	os.Exit(m.Run())
+}
@@ -1,16 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/fsnotify/fsnotify"
 	"github.com/getlantern/systray"
-	"github.com/mattn/go-gntp"
 )
 
 var (
@@ -18,6 +22,9 @@ var (
 	watchedDirs    = make(map[string]bool)
 	watchedFiles   = make(map[string]bool)
 	monitorMu      sync.Mutex
+
+	// .ptinclude whitelists keyed by watched root; roots without one are absent
+	watchedIncludes = make(map[string]*GitIgnore)
 	
 	monitorPaused  = false
 	monitorRunning = false
@@ -28,12 +35,42 @@ var (
 	menuPause      *systray.MenuItem
 	menuResume     *systray.MenuItem
 	menuTextNotif  *systray.MenuItem
+	menuWatched    *systray.MenuItem
 	menuQuit       *systray.MenuItem
 	
 	savedArgs       []string
 	savedExceptions []string  // Store exceptions for restart
+
+	// Quiet mode (--quiet): per-event output goes to infoOut, which is
+	// io.Discard, and a periodic summary is printed instead
+	monitorQuiet   = false
+	quietBackups   int
+	quietFailures  int
+	quietFiles     = make(map[string]bool)
+	// When each file was last handled, for monitor_throttle_ms
+	lastHandled    = make(map[string]time.Time)
+
+	// --include-dotpt: watch directories named .pt that are not a backup store
+	monitorIncludeDotPt = false
+	// isBackupStoreDir results by directory, since with --include-dotpt
+	// every event under a .pt directory would stat and glob it again
+	storeDirCache   = make(map[string]bool)
+	storeDirCacheMu sync.Mutex
+
+	// Command run after each change (--on-change or on_change_cmd); "{}"
+	// is replaced by the changed path. onChangeMu keeps runs from overlapping
+	onChangeCmd    string
+	onChangeMu     sync.Mutex
+
+	// File notifications collected during notification_batch_ms and sent as
+	// one, so a checkout touching many files does not flood the tray
+	pendingNotifications []Notification
+	notificationBatch    *time.Timer
 )
 
+// monitorSummaryInterval is how often quiet mode reports activity
+const monitorSummaryInterval = time.Minute
+
 func checkDebug() bool {
 	if os.Getenv("DEBUG") == "1" {
 		return true
@@ -64,8 +101,6 @@ func removeArg(s []string, value string) []string {
 }
 
 func handleMonitorCommand(args []string) error {
-	// savedArgs = args
-
 	if (containsString(os.Args, "-e") && !containsString(args, "-e")) || (containsString(os.Args, "--exception") && !containsString(args, "-e")) {
 		args = os.Args[2:]
 	}
@@ -76,7 +111,14 @@ func handleMonitorCommand(args []string) error {
 	if DEBUG { fmt.Printf("args: %v\n", args) }
 
 	for i := 0; i < len(args); i++ {
-		if (args[i] == "-e" || args[i] == "--exception") && i+1 < len(args) {
+		if args[i] == "--quiet" || args[i] == "-q" {
+			monitorQuiet = true
+		} else if args[i] == "--include-dotpt" {
+			monitorIncludeDotPt = true
+		} else if args[i] == "--on-change" && i+1 < len(args) {
+			onChangeCmd = args[i+1]
+			i++
+		} else if (args[i] == "-e" || args[i] == "--exception") && i+1 < len(args) {
 			// Next arg is the exception pattern
 			next_arg := args[i+1]
 			if DEBUG { fmt.Printf("next_arg: %s", next_arg)}
@@ -90,6 +132,20 @@ func handleMonitorCommand(args []string) error {
 		}
 	}
 
+	// The monitor section of the config is a default profile: its
+	// exceptions always apply, its paths when none are given
+	for _, exc := range appConfig.Monitor.Exceptions {
+		if !containsString(exceptions, exc) {
+			exceptions = append(exceptions, exc)
+		}
+	}
+	if len(paths) == 0 && len(appConfig.Monitor.Paths) > 0 {
+		for _, path := range appConfig.Monitor.Paths {
+			paths = append(paths, expandHome(path))
+		}
+		fmt.Printf("%sℹ️  No files specified, monitoring the paths from the config (monitor.paths)%s\n", ColorYellow, ColorReset)
+	}
+
 	if DEBUG {
 		fmt.Printf("exceptions: %v\n", exceptions)
 		fmt.Printf("paths: %v\n", paths)
@@ -104,11 +160,29 @@ func handleMonitorCommand(args []string) error {
 		fmt.Printf("%sℹ️  No files specified, monitoring current directory%s\n", ColorYellow, ColorReset)
 	}
 
+	// With the default store name every .pt directory is where backups of
+	// its siblings go, so it cannot be watched safely
+	if monitorIncludeDotPt && appConfig.BackupDirName == ".pt" {
+		fmt.Printf("%s⚠️  --include-dotpt has no effect while backup_dir_name is .pt (set another backup_dir_name first)%s\n", ColorYellow, ColorReset)
+	}
+
 	// Display exceptions if any
 	if len(exceptions) > 0 {
 		fmt.Printf("%sℹ️  Exceptions: %v%s\n", ColorYellow, exceptions, ColorReset)
 	}
 
+	if onChangeCmd == "" {
+		onChangeCmd = appConfig.OnChangeCmd
+	}
+	if onChangeCmd != "" {
+		fmt.Printf("%sℹ️  On change: %s%s\n", ColorYellow, onChangeCmd, ColorReset)
+	}
+
+	notifierMu.Lock()
+	notifier = newNotifier(appConfig.NotificationBackend)
+	fmt.Printf("%sℹ️  Notifications: %s%s\n", ColorYellow, notifier.Name(), ColorReset)
+	notifierMu.Unlock()
+
 	var expandedPaths []string
 	for _, pattern := range paths {
 		if strings.ContainsAny(pattern, "*?[]") {
@@ -150,13 +224,64 @@ func handleMonitorCommand(args []string) error {
 		fmt.Printf("   %d. %s\n", i+1, absPath)
 	}
 
+	// Before the tray comes up, so Start works after a Stop
+	saveMonitorArgs(expandedPaths, exceptions)
+
+	// Set once, before anything that prints per event can run. Logger
+	// output is kept.
+	if monitorQuiet {
+		infoOut = io.Discard
+		fmt.Printf("🤫 Quiet mode: printing a summary every %s\n", monitorSummaryInterval)
+	}
+
 	go systray.Run(onReady, onExit)
 
 	return startMonitorMultiple(expandedPaths, exceptions)
 }
 
+// saveMonitorArgs remembers the resolved paths and exceptions of this run for
+// Start in the tray menu, as "path... -e pattern..." in savedArgs (read back
+// by parseMonitorArgs) and the exceptions alone in savedExceptions
+func saveMonitorArgs(paths []string, exceptions []string) {
+	args := make([]string, 0, len(paths)+2*len(exceptions))
+	args = append(args, paths...)
+	for _, exc := range exceptions {
+		args = append(args, "-e", exc)
+	}
+
+	monitorMu.Lock()
+	savedArgs = args
+	savedExceptions = append([]string{}, exceptions...)
+	monitorMu.Unlock()
+}
+
+// parseMonitorArgs splits saved monitor args back into paths and exceptions
+func parseMonitorArgs(args []string) (paths []string, exceptions []string) {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-e" || args[i] == "--exception" {
+			if i+1 < len(args) {
+				exceptions = append(exceptions, args[i+1])
+				i++
+			}
+		} else {
+			paths = append(paths, args[i])
+		}
+	}
+	return paths, exceptions
+}
+
 func handleMonitorWithInfo(info *CommandInfo) error {
-	return handleMonitorCommand(info.Files)
+	args := info.Files
+	if info.BoolFlags["--quiet"] || info.BoolFlags["-q"] {
+		args = append(args, "--quiet")
+	}
+	if info.BoolFlags["--include-dotpt"] {
+		args = append(args, "--include-dotpt")
+	}
+	if cmd, ok := info.Flags["--on-change"]; ok {
+		args = append(args, "--on-change", cmd)
+	}
+	return handleMonitorCommand(args)
 }
 
 func startMonitorMultiple(paths []string, exceptions []string) error {
@@ -168,9 +293,6 @@ func startMonitorMultiple(paths []string, exceptions []string) error {
 
 	monitorRunning = true
 	defer func() { monitorRunning = false }()
-	
-	// Save exceptions for restart
-	savedExceptions = exceptions
 
 	for _, path := range paths {
 		absPath, err := filepath.Abs(path)
@@ -185,8 +307,7 @@ func startMonitorMultiple(paths []string, exceptions []string) error {
 			continue
 		}
 
-		baseName := filepath.Base(absPath)
-		if baseName == ".git" || baseName == ".pt" {
+		if isExcludedDir(absPath) {
 			fmt.Printf("%s⚠️  Skipping excluded directory: %s%s\n", ColorYellow, absPath, ColorReset)
 			continue
 		}
@@ -198,6 +319,13 @@ func startMonitorMultiple(paths []string, exceptions []string) error {
 		}
 
 		if info.IsDir() {
+			if gi, _ := loadGitIgnoreAndPtIgnore(findProjectRoot(absPath)); gi != nil && len(gi.includes) > 0 {
+				monitorMu.Lock()
+				watchedIncludes[absPath] = gi
+				monitorMu.Unlock()
+				fmt.Printf("📋 Tracking only .ptinclude matches under %s\n", absPath)
+			}
+
 			err = addWatchRecursive(watcher, absPath, exceptions)
 			if err != nil {
 				fmt.Printf("%s⚠️  Warning: failed to watch directory %s: %v%s\n", ColorYellow, absPath, err, ColorReset)
@@ -228,6 +356,12 @@ func startMonitorMultiple(paths []string, exceptions []string) error {
 	}
 	fmt.Printf("⌨️  Press Ctrl+C to stop or use system tray menu\n\n")
 
+	if monitorQuiet {
+		done := make(chan struct{})
+		go runQuietSummary(done)
+		defer close(done)
+	}
+
 	for {
 		select {
 		case <-stopMonitorCh:
@@ -249,7 +383,53 @@ func startMonitorMultiple(paths []string, exceptions []string) error {
 			if logger != nil {
 				logger.Printf("Monitor error: %v", err)
 			}
-			fmt.Printf("%s⚠️  Warning: %v%s\n", ColorYellow, err, ColorReset)
+			fmt.Fprintf(infoOut, "%s⚠️  Warning: %v%s\n", ColorYellow, err, ColorReset)
+		}
+	}
+}
+
+// recordQuietActivity counts a handled event for the next quiet summary.
+// Repeated events for the same file within an interval collapse into one.
+func recordQuietActivity(path string, backedUp bool, failed bool) {
+	monitorMu.Lock()
+	defer monitorMu.Unlock()
+
+	quietFiles[path] = true
+	if backedUp {
+		quietBackups++
+	}
+	if failed {
+		quietFailures++
+	}
+}
+
+// runQuietSummary prints one line per interval with any activity until done
+func runQuietSummary(done <-chan struct{}) {
+	ticker := time.NewTicker(monitorSummaryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			monitorMu.Lock()
+			files, backups, failures := len(quietFiles), quietBackups, quietFailures
+			quietFiles = make(map[string]bool)
+			quietBackups = 0
+			quietFailures = 0
+			monitorMu.Unlock()
+
+			if files == 0 {
+				continue
+			}
+
+			line := fmt.Sprintf("📊 [%s] %d backup(s) of %d changed file(s) in last %s",
+				time.Now().Format("15:04:05"), backups, files, monitorSummaryInterval)
+			if failures > 0 {
+				line += fmt.Sprintf(", %s%d failed%s", ColorRed, failures, ColorReset)
+			}
+			fmt.Println(line)
 		}
 	}
 }
@@ -269,7 +449,7 @@ func addWatchRecursive(watcher *fsnotify.Watcher, root string, exceptions []stri
 		if info.IsDir() {
 			name := info.Name()
 
-			if name == ".git" || name == ".pt" {
+			if isExcludedDir(path) {
 				if logger != nil {
 					logger.Printf("Skipping critical directory: %s", path)
 				}
@@ -316,13 +496,6 @@ func addWatchRecursive(watcher *fsnotify.Watcher, root string, exceptions []stri
 }
 
 func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event, monitoredPaths []string, exceptions []string) {
-	eventDir := filepath.Base(filepath.Dir(event.Name))
-	eventBase := filepath.Base(event.Name)
-
-	if eventBase == ".git" || eventBase == ".pt" || eventDir == ".git" || eventDir == ".pt" {
-		return
-	}
-
 	if containsExcludedDir(event.Name) {
 		return
 	}
@@ -334,6 +507,7 @@ func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event,
 
 	absEvent, _ := filepath.Abs(event.Name)
 	isMonitored := false
+	var includes *GitIgnore
 
 	monitorMu.Lock()
 	if watchedFiles[absEvent] {
@@ -344,6 +518,7 @@ func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event,
 			
 			if strings.HasPrefix(absEvent, absPath) {
 				isMonitored = true
+				includes = watchedIncludes[absPath]
 				break
 			}
 			
@@ -362,9 +537,7 @@ func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event,
 	if event.Has(fsnotify.Create) {
 		info, err := os.Stat(event.Name)
 		if err == nil && info.IsDir() {
-			dirName := info.Name()
-
-			if dirName == ".git" || dirName == ".pt" {
+			if isExcludedDir(event.Name) {
 				if logger != nil {
 					logger.Printf("Ignoring excluded directory creation: %s", event.Name)
 				}
@@ -394,7 +567,7 @@ func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event,
 				if logger != nil {
 					logger.Printf("New directory watched: %s", event.Name)
 				}
-				fmt.Printf("📁 New directory: %s\n", event.Name)
+				fmt.Fprintf(infoOut, "📁 New directory: %s\n", event.Name)
 
 				filepath.Walk(event.Name, func(path string, info os.FileInfo, err error) error {
 					if err != nil || path == event.Name {
@@ -402,9 +575,7 @@ func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event,
 					}
 
 					if info.IsDir() {
-						subDirName := info.Name()
-
-						if subDirName == ".git" || subDirName == ".pt" {
+						if isExcludedDir(path) {
 							return filepath.SkipDir
 						}
 						
@@ -442,7 +613,7 @@ func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event,
 			if logger != nil {
 				logger.Printf("Directory removed from watch: %s", event.Name)
 			}
-			fmt.Printf("📁 Directory removed: %s\n", event.Name)
+			fmt.Fprintf(infoOut, "📁 Directory removed: %s\n", event.Name)
 		}
 		if watchedFiles[event.Name] {
 			delete(watchedFiles, event.Name)
@@ -454,16 +625,18 @@ func handleMonitorEventMultiple(watcher *fsnotify.Watcher, event fsnotify.Event,
 	}
 
 	if event.Has(fsnotify.Write) {
-		triggerFileAction(event.Name, "modified")
+		if includes.isIncluded(absEvent, false) {
+			triggerFileAction(event.Name, "modified")
+		}
 	} else if event.Has(fsnotify.Create) {
 		info, err := os.Stat(event.Name)
-		if err == nil && !info.IsDir() {
+		if err == nil && !info.IsDir() && includes.isIncluded(absEvent, false) {
 			triggerFileAction(event.Name, "created")
 		}
 	} else if event.Has(fsnotify.Remove) {
 		info, _ := os.Stat(event.Name)
 		if info == nil || !info.IsDir() {
-			fmt.Printf("🗑️  File deleted: %s\n", event.Name)
+			fmt.Fprintf(infoOut, "🗑️  File deleted: %s\n", event.Name)
 			if logger != nil {
 				logger.Printf("File deleted: %s", event.Name)
 			}
@@ -498,25 +671,61 @@ func matchesException(path string, exceptions []string) bool {
 	return false
 }
 
+// containsExcludedDir reports whether any directory on path is excluded by
+// isExcludedDir. Whole path segments are compared, so my.ptools/ or
+// notes.pt.txt are not mistaken for .pt.
 func containsExcludedDir(path string) bool {
-	normalizedPath := filepath.ToSlash(path)
-	
-	if strings.Contains(normalizedPath, "/.git/") || 
-	   strings.Contains(normalizedPath, "/.pt/") {
+	path = filepath.Clean(path)
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if isExcludedDir(dir) {
+			return true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
+
+// isExcludedDir reports whether the monitor must skip the directory at path:
+// .git always, and the backup store (.pt or backup_dir_name), since backing
+// up its files would write new backups into it. With --include-dotpt a
+// directory named .pt is watched unless it is a store after all.
+func isExcludedDir(path string) bool {
+	name := filepath.Base(path)
+	if name == ".git" {
 		return true
 	}
-	
-	if strings.HasSuffix(normalizedPath, "/.git") || 
-	   strings.HasSuffix(normalizedPath, "/.pt") {
+	if name != ".pt" && name != appConfig.BackupDirName {
+		return false
+	}
+	if !monitorIncludeDotPt {
 		return true
 	}
-	
-	if strings.Contains(path, string(filepath.Separator)+".git"+string(filepath.Separator)) ||
-	   strings.Contains(path, string(filepath.Separator)+".pt"+string(filepath.Separator)) {
+
+	storeDirCacheMu.Lock()
+	defer storeDirCacheMu.Unlock()
+	isStore, ok := storeDirCache[path]
+	if !ok {
+		isStore = isBackupStoreDir(path)
+		storeDirCache[path] = isStore
+	}
+	return isStore
+}
+
+// isBackupStoreDir reports whether dir is a pt backup store: named
+// backup_dir_name (pt stores backups of its siblings there), or holding pt's
+// own files, e.g. a store left from an earlier backup_dir_name
+func isBackupStoreDir(dir string) bool {
+	if filepath.Base(dir) == appConfig.BackupDirName {
 		return true
 	}
-	
-	return false
+	for _, marker := range []string{backupDirConfigFile, backupIndexFile} {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	sidecars, _ := filepath.Glob(filepath.Join(dir, "*", "*.meta.json"))
+	return len(sidecars) > 0
 }
 
 func triggerFileAction(path string, action string) {
@@ -527,7 +736,20 @@ func triggerFileAction(path string, action string) {
 		timer.Stop()
 	}
 
-	debounceTimers[path] = time.AfterFunc(300*time.Millisecond, func() {
+	// A file handled less than monitor_throttle_ms ago waits out the rest
+	// of that window. Events in the meantime replace the timer, so a burst
+	// of writes ends in one backup of the final content.
+	delay := 300 * time.Millisecond
+	if last, ok := lastHandled[path]; ok {
+		throttle := time.Duration(appConfig.MonitorThrottleMs) * time.Millisecond
+		delay = max(delay, time.Until(last.Add(throttle)))
+	}
+
+	debounceTimers[path] = time.AfterFunc(delay, func() {
+		monitorMu.Lock()
+		lastHandled[path] = time.Now()
+		monitorMu.Unlock()
+
 		absPath, _ := filepath.Abs(path)
 		timestamp := time.Now().Format("15:04:05")
 
@@ -535,33 +757,70 @@ func triggerFileAction(path string, action string) {
 		if action == "created" {
 			actionEmoji = "✨"
 		}
-		fmt.Printf("%s [%s] File %s: %s\n", actionEmoji, timestamp, action, absPath)
+		fmt.Fprintf(infoOut, "%s [%s] File %s: %s\n", actionEmoji, timestamp, action, absPath)
 		if logger != nil {
 			logger.Printf("File %s: %s", action, absPath)
 		}
 
 		sendFileNotification(path, action, timestamp)
 
+		backedUp, failed := false, false
 		if appConfig.AutoBackup == nil || *appConfig.AutoBackup {
 			comment := ""
 			status, err := autoBackupFile(absPath, comment)
 			if err != nil {
+				failed = true
 				if logger != nil {
 					logger.Printf("Auto-backup failed: %v", err)
 				}
 			} else {
 				if status != "identical" {
-					fmt.Printf("💾 Auto-backup created: %s\n", filepath.Base(absPath))
+					backedUp = true
+					fmt.Fprintf(infoOut, "💾 Auto-backup created: %s\n", filepath.Base(absPath))
 				}
 			}
 		}
+
+		if monitorQuiet {
+			recordQuietActivity(absPath, backedUp, failed)
+		}
+
+		if onChangeCmd != "" {
+			runOnChangeCommand(absPath)
+		}
 	})
 }
 
+// runOnChangeCommand runs the --on-change command for a changed file and
+// prints its combined output
+func runOnChangeCommand(path string) {
+	onChangeMu.Lock()
+	defer onChangeMu.Unlock()
+
+	line := strings.ReplaceAll(onChangeCmd, "{}", shellQuote(path))
+	fmt.Fprintf(infoOut, "%s▶️  Running: %s%s\n", ColorCyan, line, ColorReset)
+	if logger != nil {
+		logger.Printf("On-change command: %s", line)
+	}
+
+	output, err := shellCommand(line).CombinedOutput()
+	if len(output) > 0 {
+		infoOut.Write(output)
+	}
+	if err != nil {
+		fmt.Fprintf(infoOut, "%s❌ On-change command failed: %v%s\n", ColorRed, err, ColorReset)
+		if logger != nil {
+			logger.Printf("On-change command failed: %v", err)
+		}
+		return
+	}
+	fmt.Fprintf(infoOut, "%s✅ On-change command finished%s\n", ColorGreen, ColorReset)
+}
+
 func autoBackupFile(filePath string, comment string) (string, error) {
 	backups, err := listBackups(filePath)
 	if err != nil {
-		fmt.Printf("%s❌ Error autoBackupFile [1]: %v%s\n", ColorRed, err, ColorReset)
+		fmt.Fprintf(infoOut, "%s❌ Error autoBackupFile [1]: %v%s\n", ColorRed, err, ColorReset)
 		sendFileNotification(filePath, "error", time.Now().Format("15:04:05"), err)
 		return "", err
 	}
@@ -571,30 +830,30 @@ func autoBackupFile(filePath string, comment string) (string, error) {
 	}
 	text, err := os.ReadFile(filePath)
 	if err != nil {
-		fmt.Printf("%s❌ Error autoBackupFile [2]: %v%s\n", ColorRed, err, ColorReset)
+		fmt.Fprintf(infoOut, "%s❌ Error autoBackupFile [2]: %v%s\n", ColorRed, err, ColorReset)
 		sendFileNotification(filePath, "error", time.Now().Format("15:04:05"), err)
 		return "", err
 	}
 
 	if len(backups) == 0 {
-		fmt.Printf("No backups found for: %s (check %s/ directory)\n", filePath, appConfig.BackupDirName)
+		fmt.Fprintf(infoOut, "No backups found for: %s (check %s/ directory)\n", filePath, appConfig.BackupDirName)
 		_, err = autoRenameIfExists(filePath, comment, false)
 		if err != nil {
-			fmt.Printf("%s❌ Error autoBackupFile [3]: %v%s\n", ColorRed, err, ColorReset)
+			fmt.Fprintf(infoOut, "%s❌ Error autoBackupFile [3]: %v%s\n", ColorRed, err, ColorReset)
 			return "", err
 		}
 	} else {
 		selectedBackup := backups[0]
-		fmt.Printf("%s📊 Comparing with last backup: %s%s\n\n", ColorCyan, selectedBackup.Name, ColorReset)
+		fmt.Fprintf(infoOut, "%s📊 Comparing with last backup: %s%s\n\n", ColorCyan, selectedBackup.Name, ColorReset)
 
 		if !checkIfDifferent(selectedBackup.Path, text) {
-			fmt.Printf(" ⚠ %sLast backup:%s %s%s%s%s %sand%s %s'content'%s %sis%s %s%sidentical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, selectedBackup.Name, ColorReset, ColorYellow, ColorReset, ColorCyan, ColorReset, ColorYellow, ColorReset, ColorWhite, BgMagenta, ColorReset)
+			fmt.Fprintf(infoOut, " ⚠ %sLast backup:%s %s%s%s%s %sand%s %s'content'%s %sis%s %s%sidentical%s\n", ColorYellow, ColorReset, ColorWhite, ColorBlue, selectedBackup.Name, ColorReset, ColorYellow, ColorReset, ColorCyan, ColorReset, ColorYellow, ColorReset, ColorWhite, BgMagenta, ColorReset)
 			return "identical", nil
 		}
 
 		_, err = autoRenameIfExists(filePath, comment, false)
 		if err != nil {
-			fmt.Printf("%s❌ Error autoBackupFile [4]: %v%s\n", ColorRed, err, ColorReset)
+			fmt.Fprintf(infoOut, "%s❌ Error autoBackupFile [4]: %v%s\n", ColorRed, err, ColorReset)
 			return "", err
 		}
 	}
@@ -605,60 +864,111 @@ func autoBackupFile(filePath string, comment string) (string, error) {
 func sendFileNotification(path string, action string, timestamp string, optionalErr ...error) {
 	absPath, _ := filepath.Abs(path)
 	title := "File Monitor - pt"
-	message := fmt.Sprintf("[%s] File %s\n%s", timestamp, action, absPath)
 
-	icon := findNotificationIcon()
+	eventType := "file_changed"
+	if action == "created" {
+		eventType = "file_created"
+	}
 
-	client := gntp.NewClient()
-	client.AppName = "pt"
+	queueNotification(Notification{
+		Event: eventType,
+		Title: title,
+		Text:  fmt.Sprintf("[%s] File %s\n%s", timestamp, action, absPath),
+		Icon:  findNotificationIcon(),
+		Path:  absPath,
+	})
 
-	events := []gntp.Notification{
-		{Event: "file_changed", Enabled: true},
-		{Event: "file_created", Enabled: true},
-		{Event: "error", Enabled: true},
+	if len(optionalErr) > 0 && optionalErr[0] != nil {
+		deliverNotification(Notification{
+			Event:  "error",
+			Title:  title,
+			Text:   fmt.Sprintf("pt monitoring Error: %v", optionalErr[0]),
+			Urgent: true,
+		})
 	}
+}
 
-	err := client.Register(events)
-	if err != nil {
-		if logger != nil {
-			logger.Printf("Failed to register notifications: %v", err)
-		}
+// queueNotification holds a file notification until the batch window closes.
+// Errors do not come through here and are sent right away.
+func queueNotification(n Notification) {
+	window := time.Duration(appConfig.NotificationBatchMs) * time.Millisecond
+	if window <= 0 {
+		deliverNotification(n)
 		return
 	}
 
-	eventType := "file_changed"
-	if action == "created" {
-		eventType = "file_created"
+	monitorMu.Lock()
+	defer monitorMu.Unlock()
+
+	pendingNotifications = append(pendingNotifications, n)
+	if notificationBatch == nil {
+		notificationBatch = time.AfterFunc(window, flushNotifications)
 	}
+}
+
+// flushNotifications sends what queueNotification collected: changes to a
+// single file as its latest notification, several files as one "N files
+// changed" notification
+func flushNotifications() {
+	monitorMu.Lock()
+	batch := pendingNotifications
+	pendingNotifications = nil
+	notificationBatch = nil
+	monitorMu.Unlock()
 
-	msg := &gntp.Message{
-		Event:  eventType,
-		Title:  title,
-		Text:   message,
-		Sticky: false,
+	if len(batch) == 0 {
+		return
 	}
 
-	if icon != "" {
-		if _, err := os.Stat(icon); err == nil {
-			msg.Icon = icon
+	var paths []string
+	latest := make(map[string]Notification)
+	for _, n := range batch {
+		if _, seen := latest[n.Path]; !seen {
+			paths = append(paths, n.Path)
 		}
+		latest[n.Path] = n
+	}
+	if len(paths) == 1 {
+		deliverNotification(latest[paths[0]])
+		return
 	}
 
-	err = client.Notify(msg)
-	if err != nil {
-		if logger != nil {
-			logger.Printf("Failed to send notification: %v", err)
-		}
+	const maxListed = 5
+	names := notificationNames(paths)
+
+	listed := names
+	if len(listed) > maxListed {
+		listed = listed[:maxListed]
+	}
+	text := fmt.Sprintf("[%s] %d files changed\n%s", time.Now().Format("15:04:05"), len(names), strings.Join(listed, ", "))
+	if len(names) > maxListed {
+		text += fmt.Sprintf(" and %d more", len(names)-maxListed)
 	}
 
-	if len(optionalErr) > 0 && optionalErr[0] != nil {
-		client.Notify(&gntp.Message{
-			Event:  "error",
-			Title:  title,
-			Text:   fmt.Sprintf("pt monitoring Error: %v", optionalErr[0]),
-			Sticky: true,
-		})
+	deliverNotification(Notification{
+		Event: "file_changed",
+		Title: batch[0].Title,
+		Text:  text,
+		Icon:  batch[0].Icon,
+	})
+}
+
+// notificationNames shortens paths to their base names for a batched
+// notification, keeping the parent directory for names that would
+// otherwise read the same
+func notificationNames(paths []string) []string {
+	count := make(map[string]int)
+	for _, path := range paths {
+		count[filepath.Base(path)]++
 	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+		if count[names[i]] > 1 {
+			names[i] = filepath.Join(filepath.Base(filepath.Dir(path)), names[i])
+		}
+	}
+	return names
 }
 
 func findNotificationIcon() string {
@@ -800,6 +1110,8 @@ func onReady() {
 		menuTextNotif.SetIcon(iconNotif)
 	}
 	
+	menuWatched = systray.AddMenuItem("📋 Show watched paths", "Print the watched directories and files")
+	
 	systray.AddSeparator()
 	
 	menuQuit = systray.AddMenuItem("🚪 Exit", "Exit the application")
@@ -831,6 +1143,8 @@ func onReady() {
 					menuTextNotif.Check()
 					fmt.Println("🔔 Text notifications enabled")
 				}
+			case <-menuWatched.ClickedCh:
+				printWatchedPaths()
 			case <-menuQuit.ClickedCh:
 				fmt.Println("👋 Exiting file monitor...")
 				systray.Quit()
@@ -840,13 +1154,47 @@ func onReady() {
 	}()
 }
 
+// printWatchedPaths lists the directories and files the monitor is watching
+// right now, to check that exceptions and skip rules took effect. It writes
+// to stdout even in quiet mode.
+func printWatchedPaths() {
+	monitorMu.Lock()
+	dirs := make([]string, 0, len(watchedDirs))
+	for dir := range watchedDirs {
+		dirs = append(dirs, dir)
+	}
+	files := make([]string, 0, len(watchedFiles))
+	for file := range watchedFiles {
+		files = append(files, file)
+	}
+	monitorMu.Unlock()
+
+	sort.Strings(dirs)
+	sort.Strings(files)
+
+	fmt.Printf("\n📋 Watching %d directories and %d files:\n", len(dirs), len(files))
+	for _, dir := range dirs {
+		fmt.Printf("  📁 %s\n", dir)
+	}
+	for _, file := range files {
+		fmt.Printf("  📄 %s\n", file)
+	}
+	fmt.Println()
+}
+
 func handleTrayStart() {
 	if monitorRunning {
 		fmt.Println("⚠️  Monitor already running")
 		return
 	}
 
-	if len(savedArgs) == 0 {
+	// savedArgs' -e entries mirror savedExceptions
+	monitorMu.Lock()
+	paths, _ := parseMonitorArgs(savedArgs)
+	exceptions := append([]string{}, savedExceptions...)
+	monitorMu.Unlock()
+
+	if len(paths) == 0 {
 		fmt.Println("❌ No saved configuration. Please restart the program.")
 		return
 	}
@@ -865,21 +1213,6 @@ func handleTrayStart() {
 		watchedFiles = make(map[string]bool)
 		monitorMu.Unlock()
 
-		// Parse args again for exceptions
-		var exceptions []string
-		var paths []string
-		
-		for i := 0; i < len(savedArgs); i++ {
-			if savedArgs[i] == "-e" || savedArgs[i] == "--exception" {
-				if i+1 < len(savedArgs) {
-					exceptions = append(exceptions, savedArgs[i+1])
-					i++
-				}
-			} else {
-				paths = append(paths, savedArgs[i])
-			}
-		}
-
 		err := startMonitorMultiple(paths, exceptions)
 		if err != nil {
 			fmt.Printf("❌ Monitor error: %v\n", err)
@@ -1058,3 +1391,148 @@ func getMenuIcon(menuType string) []byte {
 	
 	return nil
 }
+
+// ============================================================================
+// FOLLOW - pt show --follow, a highlighted tail -f
+// ============================================================================
+
+// followDebounce collects bursts of writes into one render
+const followDebounce = 100 * time.Millisecond
+
+// followOptions carries pt show's rendering settings into follow mode. The
+// lexer is resolved once, so new lines keep the highlighting of the file.
+type followOptions struct {
+	Lexer          chroma.Lexer // nil renders plain text
+	Style          *chroma.Style
+	TabWidth       int
+	LineNumbers    bool
+	Grid           bool
+	ShowWhitespace bool
+	NumWidth       int // line number column width of the initial listing
+	NextLine       int // number of the next line to print
+}
+
+// followFile watches filePath after pt show has printed its first offset
+// bytes and prints lines appended to it until Ctrl+C. A file that shrinks
+// (truncated or rotated) is followed again from its start.
+func followFile(filePath string, offset int64, opts *followOptions) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory so a rotated file is picked up when recreated
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", absPath, err)
+	}
+	fmt.Printf("%s── Following %s (Ctrl+C to stop) ──%s\n", ColorGray, filepath.Base(absPath), ColorReset)
+
+	debounce := time.NewTimer(followDebounce)
+	debounce.Stop()
+	pending := "" // an unterminated last line, printed once it is complete
+
+	for {
+		select {
+		case <-interruptCtx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == absPath && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+				debounce.Reset(followDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if logger != nil {
+				logger.Printf("Follow watcher error: %v", err)
+			}
+		case <-debounce.C:
+			offset, pending = printAppended(absPath, offset, pending, opts)
+		}
+	}
+}
+
+// printAppended prints the complete lines written to path since offset and
+// returns the new offset and the still incomplete last line
+func printAppended(path string, offset int64, pending string, opts *followOptions) (int64, string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return offset, pending
+	}
+	if info.Size() < offset {
+		fmt.Printf("%s── %s was truncated, following from the start ──%s\n", ColorYellow, filepath.Base(path), ColorReset)
+		offset, pending = 0, ""
+		opts.NextLine = 1
+	}
+	if info.Size() == offset {
+		return offset, pending
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return offset, pending
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, pending
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return offset, pending
+	}
+	offset += int64(len(data))
+
+	text := pending + string(data)
+	end := strings.LastIndex(text, "\n")
+	if end < 0 {
+		return offset, text
+	}
+	opts.printLines(text[:end])
+	return offset, text[end+1:]
+}
+
+// printLines highlights and prints text (without its final newline) with
+// the same gutter pt show uses
+func (opts *followOptions) printLines(text string) {
+	text = expandTabs(text, opts.TabWidth)
+
+	var highlighted bytes.Buffer
+	if opts.Lexer != nil {
+		iterator, err := opts.Lexer.Tokenise(nil, text)
+		if err == nil {
+			err = formatters.TTY16m.Format(&highlighted, opts.Style, iterator)
+		}
+		if err != nil {
+			highlighted.Reset()
+			highlighted.WriteString(text)
+		}
+	} else {
+		highlighted.WriteString(text)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(highlighted.String(), "\n"), "\n") {
+		if opts.ShowWhitespace {
+			line = markWhitespace(line)
+		}
+		gutter := ""
+		if opts.LineNumbers {
+			separator := " "
+			if opts.Grid {
+				separator = " │"
+			}
+			width := max(opts.NumWidth, len(fmt.Sprint(opts.NextLine)))
+			gutter = fmt.Sprintf("%s%*d%s%s ", ColorGray, width, opts.NextLine, separator, ColorReset)
+		}
+		fmt.Print(gutter + line + "\n")
+		opts.NextLine++
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContainsExcludedDir(t *testing.T) {
+	root := t.TempDir()
+	// A .pt directory with no pt files in it, and one that is a store
+	plainDotPt := filepath.Join(root, "plain", ".pt")
+	storeDotPt := filepath.Join(root, "store", ".pt")
+	for _, dir := range []string{plainDotPt, filepath.Join(storeDotPt, "main.go")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(storeDotPt, "main.go", "main_go.1.meta.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		backupDirName string
+		includeDotPt  bool
+		want          bool
+	}{
+		{"plain file", "/src/project/main.go", ".pt", false, false},
+		{"inside .pt", "/src/project/.pt/main.go/main_go.1", ".pt", false, true},
+		{"the .pt directory itself", "/src/project/.pt", ".pt", false, true},
+		{".pturbo is not .pt", "/src/project/.pturbo/main.go", ".pt", false, false},
+		{"script.pt.txt is not .pt", "/src/project/script.pt.txt", ".pt", false, false},
+		{"my.ptools is not .pt", "/src/my.ptools/tool.go", ".pt", false, false},
+		{"suffix .pt on a file", "/src/project/notes.pt", ".pt", false, false},
+		{"inside .git", "/src/project/.git/HEAD", ".pt", false, true},
+		{"nested .git", "/src/project/vendor/lib/.git/config", ".pt", false, true},
+		{".github is not .git", "/src/project/.github/workflows/ci.yml", ".pt", false, false},
+		{"custom backup_dir_name", "/src/project/backups/main.go/main_go.1", "backups", false, true},
+		{".pt excluded with custom name", "/src/project/.pt/main.go/main_go.1", "backups", false, true},
+		{"include-dotpt, not a store", filepath.Join(plainDotPt, "notes.txt"), "backups", true, false},
+		{"include-dotpt, a store", filepath.Join(storeDotPt, "main.go", "main_go.1"), "backups", true, true},
+		{"include-dotpt keeps backup_dir_name", "/src/project/backups/main.go/main_go.1", "backups", true, true},
+	}
+
+	saved := appConfig.BackupDirName
+	defer func() {
+		appConfig.BackupDirName = saved
+		monitorIncludeDotPt = false
+	}()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appConfig.BackupDirName = tt.backupDirName
+			monitorIncludeDotPt = tt.includeDotPt
+			if got := containsExcludedDir(tt.path); got != tt.want {
+				t.Errorf("containsExcludedDir(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsExcludedDirCachesStoreCheck(t *testing.T) {
+	dotPt := filepath.Join(t.TempDir(), ".pt")
+	if err := os.MkdirAll(dotPt, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := appConfig.BackupDirName
+	appConfig.BackupDirName = "backups"
+	monitorIncludeDotPt = true
+	defer func() {
+		appConfig.BackupDirName = saved
+		monitorIncludeDotPt = false
+	}()
+
+	if isExcludedDir(dotPt) {
+		t.Fatal("empty .pt excluded with --include-dotpt")
+	}
+	// The answer is remembered: the filesystem is not looked at again
+	os.WriteFile(filepath.Join(dotPt, backupIndexFile), []byte("{}"), 0644)
+	if isExcludedDir(dotPt) {
+		t.Error("isExcludedDir re-checked the filesystem instead of using the cache")
+	}
+}
+
+func TestMonitorArgsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		paths      []string
+		exceptions []string
+	}{
+		{"paths only", []string{"/src/app", "/src/lib"}, nil},
+		{"paths with spaces", []string{"/home/me/My Documents", "/tmp/a b/c"}, nil},
+		{"exceptions", []string{"/src/app"}, []string{"node_modules", "*.log", "build dir"}},
+		{"exception that looks like a flag", []string{"/src/app"}, []string{"-e", "--exception"}},
+		{"empty", nil, nil},
+	}
+
+	defer saveMonitorArgs(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saveMonitorArgs(tt.paths, tt.exceptions)
+
+			monitorMu.Lock()
+			args := append([]string{}, savedArgs...)
+			saved := append([]string{}, savedExceptions...)
+			monitorMu.Unlock()
+
+			paths, exceptions := parseMonitorArgs(args)
+			if !slices.Equal(paths, tt.paths) {
+				t.Errorf("paths = %q, want %q", paths, tt.paths)
+			}
+			if !slices.Equal(exceptions, tt.exceptions) {
+				t.Errorf("exceptions = %q, want %q", exceptions, tt.exceptions)
+			}
+			if !slices.Equal(saved, tt.exceptions) {
+				t.Errorf("savedExceptions = %q, want %q", saved, tt.exceptions)
+			}
+		})
+	}
+}
+
+// recordingNotifier keeps what it is asked to deliver
+type recordingNotifier struct {
+	sent []Notification
+}
+
+func (r *recordingNotifier) Name() string { return "recording" }
+
+func (r *recordingNotifier) Notify(n Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+// useNotifier makes deliverNotification use n for the rest of the test
+func useNotifier(t *testing.T, n Notifier) {
+	t.Helper()
+	notifierMu.Lock()
+	previous := notifier
+	notifier = n
+	notifierMu.Unlock()
+	t.Cleanup(func() {
+		notifierMu.Lock()
+		notifier = previous
+		notifierMu.Unlock()
+	})
+}
+
+// blockingNotifier stands in for a slow backend: Notify waits for release
+type blockingNotifier struct {
+	started chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (b *blockingNotifier) Name() string { return "blocking" }
+
+func (b *blockingNotifier) Notify(Notification) error {
+	close(b.started)
+	<-b.release
+	return b.err
+}
+
+func TestDeliverNotificationDoesNotHoldLock(t *testing.T) {
+	slow := &blockingNotifier{started: make(chan struct{}), release: make(chan struct{})}
+	useNotifier(t, slow)
+
+	done := make(chan struct{})
+	go func() {
+		deliverNotification(Notification{Title: "pt", Text: "changed"})
+		close(done)
+	}()
+	<-slow.started
+
+	locked := make(chan struct{})
+	go func() {
+		notifierMu.Lock()
+		notifierMu.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(2 * time.Second):
+		t.Error("notifierMu is held while the notifier runs")
+	}
+	close(slow.release)
+	<-done
+}
+
+func TestDeliverNotificationFallsBackToStdout(t *testing.T) {
+	failing := &blockingNotifier{started: make(chan struct{}), release: make(chan struct{}), err: errors.New("no daemon")}
+	close(failing.release)
+	useNotifier(t, failing)
+
+	deliverNotification(Notification{Title: "pt", Text: "changed"})
+
+	notifierMu.Lock()
+	defer notifierMu.Unlock()
+	if _, ok := notifier.(stdoutNotifier); !ok {
+		t.Errorf("notifier is %s after a failure, want stdout", notifier.Name())
+	}
+}
+
+func TestFlushNotificationsDedupesByPath(t *testing.T) {
+	recorder := &recordingNotifier{}
+	useNotifier(t, recorder)
+
+	changed := func(path string) Notification {
+		return Notification{Event: "file_changed", Title: "pt", Text: "changed\n" + path, Path: path}
+	}
+	monitorMu.Lock()
+	pendingNotifications = []Notification{
+		changed("/src/a/main.go"),
+		changed("/src/b/main.go"),
+		changed("/src/a/main.go"),
+		changed("/src/util.go"),
+	}
+	monitorMu.Unlock()
+
+	flushNotifications()
+
+	if len(recorder.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(recorder.sent))
+	}
+	text := recorder.sent[0].Text
+	if !strings.Contains(text, "3 files changed") {
+		t.Errorf("text %q does not count 3 files", text)
+	}
+	want := filepath.Join("a", "main.go") + ", " + filepath.Join("b", "main.go") + ", util.go"
+	if !strings.HasSuffix(text, want) {
+		t.Errorf("text %q does not end with %q", text, want)
+	}
+}
+
+func TestFlushNotificationsSingleFile(t *testing.T) {
+	recorder := &recordingNotifier{}
+	useNotifier(t, recorder)
+
+	monitorMu.Lock()
+	pendingNotifications = []Notification{
+		{Event: "file_changed", Title: "pt", Text: "first save", Path: "/src/main.go"},
+		{Event: "file_changed", Title: "pt", Text: "second save", Path: "/src/main.go"},
+	}
+	monitorMu.Unlock()
+
+	flushNotifications()
+
+	if len(recorder.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(recorder.sent))
+	}
+	if text := recorder.sent[0].Text; text != "second save" {
+		t.Errorf("text %q, want the file's own latest notification", text)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-gntp"
+)
+
+// Notification is one desktop notification from the monitor
+type Notification struct {
+	Event  string // file_changed, file_created or error (GNTP event names)
+	Title  string
+	Text   string
+	Icon   string // Path to an icon file, or empty
+	Path   string // Absolute path of the file the notification is about, if any
+	Urgent bool   // Errors: sticky / critical where the backend supports it
+}
+
+// Notifier delivers monitor notifications. The backend is chosen once by
+// notification_backend; see newNotifier.
+type Notifier interface {
+	Name() string
+	Notify(n Notification) error
+}
+
+// notificationBackends are the accepted notification_backend values; empty
+// means "auto"
+var notificationBackends = []string{"auto", "gntp", "libnotify", "toast", "stdout", "none"}
+
+var (
+	notifier   Notifier
+	notifierMu sync.Mutex
+)
+
+// newNotifier returns the notifier for backend. "auto" picks the native one
+// for the platform: toast on Windows and macOS, notify-send where it is
+// installed, and GNTP (Growl) otherwise.
+func newNotifier(backend string) Notifier {
+	switch backend {
+	case "gntp":
+		return &gntpNotifier{}
+	case "libnotify":
+		return libnotifyNotifier{}
+	case "toast":
+		return toastNotifier{}
+	case "stdout":
+		return stdoutNotifier{}
+	case "none":
+		return noneNotifier{}
+	}
+
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return toastNotifier{}
+	}
+	if _, err := exec.LookPath("notify-send"); err == nil {
+		return libnotifyNotifier{}
+	}
+	return &gntpNotifier{}
+}
+
+// deliverNotification sends n through the configured notifier. When the
+// backend fails (no Growl listening, no notification daemon) the rest of the
+// run falls back to printing notifications, instead of failing silently on
+// every event. notifierMu only guards picking the notifier: Notify runs
+// without it, so a slow notify-send or powershell doesn't hold up other
+// events.
+func deliverNotification(n Notification) {
+	notifierMu.Lock()
+	if notifier == nil {
+		notifier = newNotifier(appConfig.NotificationBackend)
+	}
+	current := notifier
+	notifierMu.Unlock()
+
+	err := current.Notify(n)
+	if err == nil {
+		return
+	}
+
+	if logger != nil {
+		logger.Printf("Failed to send notification via %s: %v", current.Name(), err)
+	}
+	notifierMu.Lock()
+	// Only the first failure switches over and says so
+	if notifier == current {
+		fmt.Printf("%s⚠️  %s notifications failed (%v), printing them here instead%s\n",
+			ColorYellow, current.Name(), err, ColorReset)
+		notifier = stdoutNotifier{}
+	}
+	notifierMu.Unlock()
+	stdoutNotifier{}.Notify(n)
+}
+
+// gntpNotifier talks to Growl (or a GNTP-compatible daemon) on port 23053.
+// mu serializes Notify, which registers with the daemon on first use.
+type gntpNotifier struct {
+	mu     sync.Mutex
+	client *gntp.Client
+}
+
+func (g *gntpNotifier) Name() string { return "gntp" }
+
+func (g *gntpNotifier) Notify(n Notification) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.client == nil {
+		client := gntp.NewClient()
+		client.AppName = "pt"
+
+		events := []gntp.Notification{
+			{Event: "file_changed", Enabled: true},
+			{Event: "file_created", Enabled: true},
+			{Event: "error", Enabled: true},
+		}
+		if err := client.Register(events); err != nil {
+			return fmt.Errorf("failed to register notifications: %w", err)
+		}
+		g.client = client
+	}
+
+	msg := &gntp.Message{
+		Event:  n.Event,
+		Title:  n.Title,
+		Text:   n.Text,
+		Sticky: n.Urgent,
+	}
+	if n.Icon != "" {
+		if _, err := os.Stat(n.Icon); err == nil {
+			msg.Icon = n.Icon
+		}
+	}
+	return g.client.Notify(msg)
+}
+
+// libnotifyNotifier uses notify-send, available on most Linux desktops
+type libnotifyNotifier struct{}
+
+func (libnotifyNotifier) Name() string { return "libnotify" }
+
+func (libnotifyNotifier) Notify(n Notification) error {
+	args := []string{"--app-name=pt"}
+	if n.Icon != "" {
+		args = append(args, "--icon="+n.Icon)
+	}
+	if n.Urgent {
+		args = append(args, "--urgency=critical")
+	}
+	args = append(args, n.Title, n.Text)
+
+	out, err := exec.Command("notify-send", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("notify-send: %v: %s", err, out)
+	}
+	return nil
+}
+
+// toastNotifier shows a native notification: a Windows toast, or a
+// Notification Center banner on macOS (see showToast)
+type toastNotifier struct{}
+
+func (toastNotifier) Name() string { return "toast" }
+
+func (toastNotifier) Notify(n Notification) error {
+	return showToast(n.Title, n.Text)
+}
+
+// stdoutNotifier prints notifications in the monitor's output
+type stdoutNotifier struct{}
+
+func (stdoutNotifier) Name() string { return "stdout" }
+
+func (stdoutNotifier) Notify(n Notification) error {
+	color := ColorCyan
+	if n.Urgent {
+		color = ColorRed
+	}
+	fmt.Fprintf(infoOut, "%s🔔 %s: %s%s\n", color, n.Title, strings.ReplaceAll(n.Text, "\n", " "), ColorReset)
+	return nil
+}
+
+// noneNotifier drops notifications
+type noneNotifier struct{}
+
+func (noneNotifier) Name() string { return "none" }
+
+func (noneNotifier) Notify(Notification) error { return nil }
@@ -2,12 +2,21 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
 )
 
 // ANSI color codes
@@ -25,6 +34,11 @@ const (
 	BoldYellow = "\033[1;33m"
 	BrightGreen = "\033[1;38;2;0;203;0m"
 	WhiteOnBlue = "\033[37;44m"
+	WhiteOnRed  = "\033[1;37;41m"
+	BlackOnGreen = "\033[30;42m"
+	// Backgrounds kept under syntax-highlighted removed and added lines
+	RemovedBg = "\033[48;2;63;0;1m"
+	AddedBg   = "\033[48;2;0;40;0m"
 )
 
 type Hunk struct {
@@ -42,7 +56,31 @@ type FileDiff struct {
 	Hunks []Hunk
 }
 
-type PDiff2 struct{}
+type PDiff2 struct {
+	// WordDiff merges each removed/added line pair into one line that only
+	// highlights the words that changed
+	WordDiff bool
+	// Context is the number of unchanged lines shown around each change
+	Context int
+	// Out receives PrintDiff's output; nil means stdout
+	Out io.Writer
+	// NoHighlight keeps the plain +/- colors instead of syntax-highlighting
+	// the code in each hunk
+	NoHighlight bool
+	// Filename picks the lexer when the diff's own file names don't, e.g.
+	// DiffFiles on temp copies; set by DiffFiles from its path arguments
+	Filename string
+	// Theme is the chroma style for highlighting; empty means the theme
+	// from the config, or monokai
+	Theme string
+}
+
+func (p *PDiff2) out() io.Writer {
+	if p.Out == nil {
+		return os.Stdout
+	}
+	return p.Out
+}
 
 func (p *PDiff2) DiffFiles(file1, file2 any) (string, error) {
 	// Helper function to get content from file or data
@@ -75,6 +113,21 @@ func (p *PDiff2) DiffFiles(file1, file2 any) (string, error) {
 	if err != nil {
 		return "", err
 	}
+
+	// The diff is taken between temp files, so remember a real name for
+	// the lexer: the first path argument that one matches
+	if p.Filename == "" {
+		for _, input := range []any{file1, file2} {
+			path, ok := input.(string)
+			if !ok || lexers.Match(filepath.Base(path)) == nil {
+				continue
+			}
+			if _, err := os.Stat(path); err == nil {
+				p.Filename = path
+				break
+			}
+		}
+	}
 	
 	// Create temporary files for diff comparison
 	tmpFile1, err := os.CreateTemp("", "pdiff1-*.tmp")
@@ -103,7 +156,7 @@ func (p *PDiff2) DiffFiles(file1, file2 any) (string, error) {
 	tmpFile2.Close()
 	
 	// Run git diff on the temp files
-	cmd := exec.Command("git", "diff", "--no-index", "-U0", "-p", tmpFile1.Name(), tmpFile2.Name())
+	cmd := exec.Command("git", "diff", "--no-index", fmt.Sprintf("-U%d", p.Context), "-p", tmpFile1.Name(), tmpFile2.Name())
 	output, _ := cmd.CombinedOutput() // git diff returns exit code 1 when there are differences
 	
 	return string(output), nil
@@ -125,7 +178,7 @@ func (p *PDiff2) DiffFiles(file1, file2 any) (string, error) {
 // }
 
 func (p *PDiff2) GetGitDiff(cached bool, filePath ...string) (string, error) {
-	args := []string{"diff", "-U0", "-p"}
+	args := []string{"diff", fmt.Sprintf("-U%d", p.Context), "-p"}
 	if cached {
 		args = append(args, "--cached")
 	}
@@ -200,9 +253,10 @@ func (p *PDiff2) ParseDiff(diffText string) []FileDiff {
 
 func (p *PDiff2) PrintDiff(diffText string) {
 	files := p.ParseDiff(diffText)
+	w := p.out()
 	
 	if len(files) == 0 {
-		fmt.Printf("%s%sNo changes found.%s\n", Bold, Yellow, Reset)
+		fmt.Fprintf(w, "%s%sNo changes found.%s\n", Bold, Yellow, Reset)
 		return
 	}
 	
@@ -211,33 +265,48 @@ func (p *PDiff2) PrintDiff(diffText string) {
 		newFile := f.New
 		
 		if oldFile == "/dev/null" {
-			fmt.Printf("     🆕 ++ %s%s%s%s\n", Bold, Green, newFile, Reset)
+			fmt.Fprintf(w, "     🆕 ++ %s%s%s%s\n", Bold, Green, newFile, Reset)
 		} else if newFile == "/dev/null" {
-			fmt.Printf("  🗑️  -- %s%s%s%s\n", Bold, Red, oldFile, Reset)
+			fmt.Fprintf(w, "  🗑️  -- %s%s%s%s\n", Bold, Red, oldFile, Reset)
 		} else {
-			fmt.Printf("  📝 %s%s%s%s -> %s%s\n", Bold, Yellow, Italic, oldFile, newFile, Reset)
+			fmt.Fprintf(w, "  📝 %s%s%s%s -> %s%s\n", Bold, Yellow, Italic, oldFile, newFile, Reset)
+		}
+		
+		lexer := p.lexerFor(f)
+		var style *chroma.Style
+		if lexer != nil {
+			style = p.style()
 		}
 		
 		for _, h := range f.Hunks {
-			fmt.Printf("     📌 %d,%d -> %d,%d %s%s%s %s %s\n",
+			fmt.Fprintf(w, "     📌 %d,%d -> %d,%d %s%s%s %s %s\n",
 				h.SourceStart, h.SourceLen, h.TargetStart, h.TargetLen,
 				WhiteOnBlue, Italic, h.Section, Reset, Reset)
 			
 			added := 0
 			removed := 0
 			
-			for _, line := range h.Lines {
-				var icon, color, symbol string
+			// Highlighted code for each of h.Lines, or nil
+			var highlighted []string
+			if lexer != nil {
+				highlighted = p.highlightHunk(lexer, style, h)
+			}
+			
+			printLine := func(i int) {
+				line := h.Lines[i]
+				var icon, color, symbol, bg string
 				
 				if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
 					icon = "🟢"
 					color = BrightGreen
 					symbol = "+"
+					bg = AddedBg
 					added++
 				} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
 					icon = "🔴"
 					color = BoldRed
 					symbol = "-"
+					bg = RemovedBg
 					removed++
 				} else {
 					icon = "⚪"
@@ -245,12 +314,238 @@ func (p *PDiff2) PrintDiff(diffText string) {
 					symbol = " "
 				}
 				
-				fmt.Printf("     %s %s%s %s%s\n", icon, color, symbol, strings.TrimRight(line, "\n\r"), Reset)
+				if highlighted != nil && line != "" {
+					code := highlighted[i]
+					if bg != "" {
+						code = tintLine(code, bg)
+					}
+					fmt.Fprintf(w, "     %s %s%s %s%s%s%s\n", icon, color, symbol, line[:1], Reset, code, Reset)
+					return
+				}
+				fmt.Fprintf(w, "     %s %s%s %s%s\n", icon, color, symbol, strings.TrimRight(line, "\n\r"), Reset)
+			}
+			
+			for i := 0; i < len(h.Lines); i++ {
+				if !p.WordDiff || !isRemovedLine(h.Lines[i]) {
+					printLine(i)
+					continue
+				}
+				
+				// A run of removed lines followed by a run of added lines:
+				// pair them up and show each pair as one word-level line
+				var dels, adds []int
+				for i < len(h.Lines) && isRemovedLine(h.Lines[i]) {
+					dels = append(dels, i)
+					i++
+				}
+				for i < len(h.Lines) && isAddedLine(h.Lines[i]) {
+					adds = append(adds, i)
+					i++
+				}
+				i--
+				
+				pairs := min(len(dels), len(adds))
+				for j := 0; j < pairs; j++ {
+					oldLine := strings.TrimRight(h.Lines[dels[j]][1:], "\n\r")
+					newLine := strings.TrimRight(h.Lines[adds[j]][1:], "\n\r")
+					fmt.Fprintf(w, "     🟡 %s~%s %s\n", BoldYellow, Reset, p.renderWordDiff(oldLine, newLine))
+					removed++
+					added++
+				}
+				for _, j := range dels[pairs:] {
+					printLine(j)
+				}
+				for _, j := range adds[pairs:] {
+					printLine(j)
+				}
 			}
 			
-			fmt.Printf("     %s+%d%s %s-%d%s\n\n", BoldGreen, added, Reset, BoldRed, removed, Reset)
+			fmt.Fprintf(w, "     %s+%d%s %s-%d%s\n\n", BoldGreen, added, Reset, BoldRed, removed, Reset)
+		}
+	}
+}
+
+// lexerFor picks the lexer for a file's hunks from Filename or the names in
+// the diff header, or returns nil when highlighting is off or no lexer
+// matches
+func (p *PDiff2) lexerFor(f FileDiff) chroma.Lexer {
+	if p.NoHighlight {
+		return nil
+	}
+	
+	names := []string{p.Filename, f.New, f.Old}
+	if p.Filename != "" {
+		names = names[:1]
+	}
+	for _, name := range names {
+		if name == "" || name == "/dev/null" {
+			continue
+		}
+		if lexer := lexers.Match(filepath.Base(name)); lexer != nil {
+			return chroma.Coalesce(lexer)
+		}
+	}
+	return nil
+}
+
+// highlightHunk syntax-highlights the code of a hunk and returns it line for
+// line with h.Lines (without the +/-/space prefix), or nil if that fails.
+// The old side (context and removed lines) and the new side (context and
+// added lines) are tokenised separately, so strings and comments spanning
+// lines are colored as they read in each version.
+func (p *PDiff2) highlightHunk(lexer chroma.Lexer, style *chroma.Style, h Hunk) []string {
+	var oldSide, newSide []string
+	for _, line := range h.Lines {
+		switch {
+		case isRemovedLine(line):
+			oldSide = append(oldSide, line[1:])
+		case isAddedLine(line):
+			newSide = append(newSide, line[1:])
+		case strings.HasPrefix(line, " "):
+			oldSide = append(oldSide, line[1:])
+			newSide = append(newSide, line[1:])
+		}
+	}
+	
+	oldLines := highlightLines(lexer, style, oldSide)
+	newLines := highlightLines(lexer, style, newSide)
+	if oldLines == nil || newLines == nil {
+		return nil
+	}
+	
+	result := make([]string, len(h.Lines))
+	o, n := 0, 0
+	for i, line := range h.Lines {
+		switch {
+		case isRemovedLine(line):
+			result[i] = oldLines[o]
+			o++
+		case isAddedLine(line):
+			result[i] = newLines[n]
+			n++
+		case strings.HasPrefix(line, " "):
+			result[i] = newLines[n]
+			o++
+			n++
+		case line != "":
+			// "\ No newline at end of file" and the like
+			result[i] = line[1:]
+		}
+	}
+	return result
+}
+
+// style resolves Theme, or the configured theme, to a chroma style,
+// falling back to monokai
+func (p *PDiff2) style() *chroma.Style {
+	theme := p.Theme
+	if theme == "" && appConfig != nil && appConfig.Theme != "" {
+		theme = resolveTheme(appConfig.Theme)
+	}
+	style := styles.Get(theme)
+	if theme == "" || style == nil {
+		style = styles.Get("monokai")
+	}
+	return style
+}
+
+// tintLine puts bg under highlighted code, renewing it after every reset
+// the formatter emits, so a changed line keeps its red or green background
+// like delta's
+func tintLine(code, bg string) string {
+	return bg + strings.ReplaceAll(code, Reset, Reset+bg)
+}
+
+// highlightLines formats lines as one block and splits the result again,
+// returning nil unless every line came back
+func highlightLines(lexer chroma.Lexer, style *chroma.Style, lines []string) []string {
+	if len(lines) == 0 {
+		return []string{}
+	}
+	
+	iterator, err := lexer.Tokenise(nil, strings.Join(lines, "\n"))
+	if err != nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := formatters.TTY16m.Format(&buf, style, iterator); err != nil {
+		return nil
+	}
+	
+	// Lexers may add a final newline; anything beyond len(lines) is that
+	result := strings.Split(buf.String(), "\n")
+	if len(result) < len(lines) {
+		return nil
+	}
+	return result[:len(lines)]
+}
+
+func isRemovedLine(line string) bool {
+	return strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---")
+}
+
+func isAddedLine(line string) bool {
+	return strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")
+}
+
+// splitWords breaks a line into words, runs of whitespace and single
+// punctuation characters, so that joining the tokens gives the line back
+func splitWords(line string) []string {
+	var tokens []string
+	runes := []rune(line)
+	
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		default:
+			return 3
+		}
+	}
+	
+	for i := 0; i < len(runes); {
+		j := i + 1
+		if c := class(runes[i]); c != 3 {
+			for j < len(runes) && class(runes[j]) == c {
+				j++
+			}
 		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
 	}
+	
+	return tokens
+}
+
+// renderWordDiff merges a removed and an added line into one, keeping common
+// words plain and marking deleted words red and inserted words green
+func (p *PDiff2) renderWordDiff(oldLine, newLine string) string {
+	var b strings.Builder
+	kind := lineEqual
+	
+	for _, op := range computeLineDiff(splitWords(oldLine), splitWords(newLine)) {
+		// Open a color only when the kind changes so runs stay one span
+		if op.Kind != kind {
+			if kind != lineEqual {
+				b.WriteString(Reset)
+			}
+			switch op.Kind {
+			case lineDelete:
+				b.WriteString(WhiteOnRed)
+			case lineInsert:
+				b.WriteString(BlackOnGreen)
+			}
+			kind = op.Kind
+		}
+		b.WriteString(op.Text)
+	}
+	if kind != lineEqual {
+		b.WriteString(Reset)
+	}
+	
+	return b.String()
 }
 
 func (p *PDiff2) Main() {
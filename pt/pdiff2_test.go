@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+func TestHighlightHunkAlignment(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{"context only", []string{" a := 1", " b := 2"}},
+		{"replace", []string{" x := 1", "-y := 2", "+y := 3", " z := 4"}},
+		{"more removed than added", []string{"-a()", "-b()", "-c()", "+d()", " e()"}},
+		{"more added than removed", []string{" a()", "-b()", "+c()", "+d()", "+e()"}},
+		{"interleaved runs", []string{"-a()", "+b()", " c()", "-d()", " e()", "+f()"}},
+		{"only additions", []string{"+a()", "+b()"}},
+		{"only removals", []string{"-a()", "-b()"}},
+		{"multi-line string", []string{" s := `one", "-two", "+TWO", " three`"}},
+		{"no newline marker", []string{" a()", "-b()", "\\ No newline at end of file", "+c()", "\\ No newline at end of file"}},
+	}
+
+	lexer := chroma.Coalesce(lexers.Get("go"))
+	p := &PDiff2{Theme: "monokai"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.highlightHunk(lexer, p.style(), Hunk{Lines: tt.lines})
+			if len(got) != len(tt.lines) {
+				t.Fatalf("got %d lines, want %d", len(got), len(tt.lines))
+			}
+			for i, line := range tt.lines {
+				if plain := stripANSI(got[i]); plain != line[1:] {
+					t.Errorf("line %d: got %q, want %q", i, plain, line[1:])
+				}
+			}
+		})
+	}
+}
+
+func TestPrintDiffTintsChangedLines(t *testing.T) {
+	diff := "--- a/f.go\n+++ b/f.go\n@@ -1,3 +1,3 @@\n a := 1\n-b := 2\n+b := 3\n c := 4\n"
+
+	var out bytes.Buffer
+	p := &PDiff2{Out: &out, Theme: "monokai"}
+	p.PrintDiff(diff)
+
+	lines := strings.Split(out.String(), "\n")
+	find := func(marker string) string {
+		for _, line := range lines {
+			if strings.Contains(line, marker) {
+				return line
+			}
+		}
+		t.Fatalf("no line containing %q in:\n%s", marker, out.String())
+		return ""
+	}
+	for _, tt := range []struct {
+		marker, bg string
+	}{
+		{"🔴", RemovedBg},
+		{"🟢", AddedBg},
+	} {
+		line := find(tt.marker)
+		// The background has to come back after every reset inside the code
+		i := strings.Index(line, tt.bg)
+		if i < 0 {
+			t.Fatalf("%s line has no background: %q", tt.marker, line)
+		}
+		code := strings.TrimSuffix(line[i:], Reset)
+		if strings.Count(code, Reset) != strings.Count(code, Reset+tt.bg) {
+			t.Errorf("%s line loses its background after a reset: %q", tt.marker, line)
+		}
+	}
+	if context := find("⚪"); strings.Contains(context, RemovedBg) || strings.Contains(context, AddedBg) {
+		t.Errorf("context line is tinted: %q", context)
+	}
+}
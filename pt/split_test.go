@@ -0,0 +1,148 @@
+package main
+
+import "testing"
+
+// parseHunks parses a single-file unified diff body into hunks
+func parseHunks(t *testing.T, diff string) []Hunk {
+	t.Helper()
+	files := (&PDiff2{}).ParseDiff("--- a/f.txt\n+++ b/f.txt\n" + diff)
+	if len(files) != 1 {
+		t.Fatalf("parsed %d files, want 1", len(files))
+	}
+	return files[0].Hunks
+}
+
+func TestApplyHunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		diff    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "at the stated line",
+			content: "a\nb\nc\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:    "a\nB\nc\n",
+		},
+		{
+			name:    "offset: lines were added above the hunk",
+			content: "x\ny\nz\na\nb\nc\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:    "x\ny\nz\na\nB\nc\n",
+		},
+		{
+			name:    "offset carries over to later hunks",
+			content: "new\na\nb\nc\nd\ne\nf\n",
+			diff:    "@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -5,2 +5,2 @@\n e\n-f\n+F\n",
+			want:    "new\nA\nb\nc\nd\ne\nF\n",
+		},
+		{
+			name:    "pure addition",
+			content: "a\nb\n",
+			diff:    "@@ -1,0 +2,1 @@\n+inserted\n",
+			want:    "a\ninserted\nb\n",
+		},
+		{
+			name:    "context does not match anywhere",
+			content: "a\nb\nc\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n d\n",
+			wantErr: true,
+		},
+		{
+			name:    "removed line differs",
+			content: "a\nb\nc\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-x\n+B\n c\n",
+			wantErr: true,
+		},
+		{
+			name:    "whitespace differences are not fuzzed away",
+			content: "a\n  b\nc\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			wantErr: true,
+		},
+		{
+			name:    "CRLF file keeps its line endings",
+			content: "a\r\nb\r\nc\r\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:    "a\r\nB\r\nc\r\n",
+		},
+		{
+			name:    "CRLF patch on a CRLF file",
+			content: "a\r\nb\r\n",
+			diff:    "@@ -1,2 +1,2 @@\r\n a\r\n-b\r\n+B\r\n",
+			want:    "a\r\nB\r\n",
+		},
+		{
+			name:    "new side has no newline at end",
+			content: "a\nb\n",
+			diff:    "@@ -1,2 +1,2 @@\n a\n-b\n+B\n\\ No newline at end of file\n",
+			want:    "a\nB",
+		},
+		{
+			name:    "old side had no newline at end",
+			content: "a\nb",
+			diff:    "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+B\n",
+			want:    "a\nB\n",
+		},
+		{
+			name:    "neither side has a newline at end",
+			content: "a\nb",
+			diff:    "@@ -1,2 +1,2 @@\n-a\n+A\n b\n\\ No newline at end of file\n",
+			want:    "A\nb",
+		},
+		{
+			name:    "untouched end keeps its missing newline",
+			content: "a\nb\nc\nd\ne\nf\ng",
+			diff:    "@@ -1,2 +1,2 @@\n-a\n+A\n b\n",
+			want:    "A\nb\nc\nd\ne\nf\ng",
+		},
+		{
+			name:    "new file",
+			content: "",
+			diff:    "@@ -0,0 +1,2 @@\n+a\n+b\n",
+			want:    "a\nb\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyHunks(tt.content, parseHunks(t, tt.diff))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("applied as %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindHunk(t *testing.T) {
+	lines := []string{"a", "b", "a", "b", "c"}
+	tests := []struct {
+		old  []string
+		want int
+		at   int
+	}{
+		{[]string{"a", "b"}, 0, 0},
+		{[]string{"a", "b"}, 2, 2},
+		// Ties go to the match nearest the stated line
+		{[]string{"a", "b"}, 3, 2},
+		{[]string{"b", "c"}, 0, 3},
+		{[]string{"c", "d"}, 0, -1},
+		{nil, 4, 4},
+	}
+	for _, tt := range tests {
+		if got := findHunk(lines, tt.old, tt.want); got != tt.at {
+			t.Errorf("findHunk(%q, want %d) = %d, want %d", tt.old, tt.want, got, tt.at)
+		}
+	}
+}
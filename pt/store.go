@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BackupStore is where backups and their metadata live. Command code goes
+// through backupStore for these operations, so another store (remote,
+// in-memory) can be swapped in without touching it. Paths are the store's
+// own: for localStore they are file paths inside the .pt directory.
+//
+// Store-wide maintenance (pt fix, size, commits and gc) walks the .pt
+// directory itself, so those commands only apply to localStore.
+type BackupStore interface {
+	// Dir returns the location of filePath's backups in the store rooted
+	// at ptRoot
+	Dir(ptRoot, filePath string) (string, error)
+	// Save stores content as the backup at backupPath with its metadata
+	Save(backupPath string, content []byte, metadata *BackupMetadata) error
+	// List returns every backup in dir with its metadata, in no particular
+	// order. A dir that does not exist yields an os.ErrNotExist error.
+	List(dir string) ([]BackupInfo, error)
+	// Stat describes a single backup without reading its content
+	Stat(backupPath string) (BackupInfo, error)
+	// Read returns the content of a backup
+	Read(backupPath string) ([]byte, error)
+	// Delete removes a backup and its metadata
+	Delete(backupPath string) error
+	// Move renames a backup, or a whole backup directory, with its metadata
+	Move(src, dst string) error
+}
+
+// storeReservedNames are pt's own files at the top of a .pt store, which a
+// backed up file's folder must not take
+var storeReservedNames = []string{backupIndexFile, backupIndexJournal, lockFileName}
+
+// backupStore is the store used by every command
+var backupStore BackupStore = localStore{}
+
+// localStore keeps backups as plain files in the .pt directory, each with a
+// .meta.json sidecar
+type localStore struct{}
+
+func (localStore) Dir(ptRoot, filePath string) (string, error) {
+	relPath, err := getRelativePath(ptRoot, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	// Clean the relative path
+	relPath = filepath.Clean(relPath)
+
+	// Get the base filename
+	baseName := filepath.Base(relPath)
+
+	// Get the directory part (if any)
+	dirPart := filepath.Dir(relPath)
+
+	var backupSubdir string
+
+	// If file is directly in .pt parent (no subdirectory)
+	if dirPart == "." {
+		// Just use the filename, unless pt keeps a file of its own by that name
+		backupSubdir = baseName
+		if containsString(storeReservedNames, baseName) {
+			backupSubdir = baseName + "_"
+		}
+	} else {
+		// File is in a subdirectory, preserve the path structure
+		// Replace path separators with underscores
+		// e.g., pt/main.go -> pt_main.go
+		//       src/lib/util.go -> src_lib_util.go
+		fullPath := relPath
+		fullPath = strings.ReplaceAll(fullPath, string(os.PathSeparator), "_")
+		fullPath = strings.ReplaceAll(fullPath, "/", "_")  // Unix
+		fullPath = strings.ReplaceAll(fullPath, "\\", "_") // Windows
+		backupSubdir = fullPath
+	}
+
+	return filepath.Join(ptRoot, backupSubdir), nil
+}
+
+func (localStore) Save(backupPath string, content []byte, metadata *BackupMetadata) error {
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
+		return fmt.Errorf("failed to create backup subdirectory: %w", err)
+	}
+	if err := os.WriteFile(backupPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	// The backup itself is usable without its sidecar
+	if metadata != nil {
+		if err := writeBackupMetadata(backupPath, metadata); err != nil {
+			logger.Printf("Warning: failed to save backup metadata: %v", err)
+		}
+	}
+	return nil
+}
+
+func (localStore) List(dir string) ([]BackupInfo, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, &os.PathError{Op: "list", Path: dir, Err: os.ErrNotExist}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]BackupInfo, 0, len(entries)/2)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".meta.json") || name == backupDirConfigFile {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			logger.Printf("Warning: failed to get info for %s: %v", name, err)
+			continue
+		}
+
+		backups = append(backups, localBackupInfo(filepath.Join(dir, name), info))
+	}
+
+	return backups, nil
+}
+
+func (localStore) Stat(backupPath string) (BackupInfo, error) {
+	info, err := os.Stat(backupPath)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	if info.IsDir() {
+		return BackupInfo{}, fmt.Errorf("%s is a directory, not a backup", backupPath)
+	}
+	return localBackupInfo(backupPath, info), nil
+}
+
+// localBackupInfo describes the backup file at backupPath, picking up its
+// comment and pin from the sidecar
+func localBackupInfo(backupPath string, info os.FileInfo) BackupInfo {
+	backup := BackupInfo{
+		Path:    backupPath,
+		Name:    info.Name(),
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+	}
+	metadata, err := readBackupMetadata(backupPath)
+	if err != nil {
+		logger.Printf("Warning: failed to load metadata for %s: %v", backup.Name, err)
+	}
+	if metadata != nil {
+		backup.Comment, backup.Pinned = metadata.Comment, metadata.Pinned
+	}
+	return backup
+}
+
+func (localStore) Read(backupPath string) ([]byte, error) {
+	return os.ReadFile(backupPath)
+}
+
+func (localStore) Delete(backupPath string) error {
+	if err := os.Remove(backupPath); err != nil {
+		return err
+	}
+	if err := os.Remove(backupPath + ".meta.json"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (localStore) Move(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := moveFile(src, dst); err != nil {
+		return err
+	}
+	// A single backup carries its sidecar along; directories already did
+	if _, err := os.Stat(src + ".meta.json"); err == nil {
+		return moveFile(src+".meta.json", dst+".meta.json")
+	}
+	return nil
+}
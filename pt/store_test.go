@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is a BackupStore that keeps everything in memory, laid out like
+// localStore so paths look the same
+type memStore struct {
+	mu      sync.Mutex
+	backups map[string]memBackup
+	reads   int
+}
+
+type memBackup struct {
+	content  []byte
+	metadata BackupMetadata
+	modTime  time.Time
+}
+
+func newMemStore() *memStore {
+	return &memStore{backups: make(map[string]memBackup)}
+}
+
+func (s *memStore) Dir(ptRoot, filePath string) (string, error) {
+	return localStore{}.Dir(ptRoot, filePath)
+}
+
+func (s *memStore) Save(backupPath string, content []byte, metadata *BackupMetadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	backup := memBackup{content: append([]byte(nil), content...), modTime: time.Now()}
+	if metadata != nil {
+		backup.metadata = *metadata
+	}
+	s.backups[backupPath] = backup
+	return nil
+}
+
+func (s *memStore) info(backupPath string, backup memBackup) BackupInfo {
+	return BackupInfo{
+		Path:    backupPath,
+		Name:    filepath.Base(backupPath),
+		ModTime: backup.modTime,
+		Size:    int64(len(backup.content)),
+		Comment: backup.metadata.Comment,
+		Pinned:  backup.metadata.Pinned,
+	}
+}
+
+func (s *memStore) List(dir string) ([]BackupInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var backups []BackupInfo
+	for path, backup := range s.backups {
+		if filepath.Dir(path) == dir {
+			backups = append(backups, s.info(path, backup))
+		}
+	}
+	if backups == nil {
+		return nil, &os.PathError{Op: "list", Path: dir, Err: os.ErrNotExist}
+	}
+	return backups, nil
+}
+
+func (s *memStore) Stat(backupPath string) (BackupInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	backup, ok := s.backups[backupPath]
+	if !ok {
+		return BackupInfo{}, &os.PathError{Op: "stat", Path: backupPath, Err: os.ErrNotExist}
+	}
+	return s.info(backupPath, backup), nil
+}
+
+func (s *memStore) Read(backupPath string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	backup, ok := s.backups[backupPath]
+	if !ok {
+		return nil, &os.PathError{Op: "read", Path: backupPath, Err: os.ErrNotExist}
+	}
+	return append([]byte(nil), backup.content...), nil
+}
+
+func (s *memStore) Delete(backupPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.backups[backupPath]; !ok {
+		return &os.PathError{Op: "remove", Path: backupPath, Err: os.ErrNotExist}
+	}
+	delete(s.backups, backupPath)
+	return nil
+}
+
+func (s *memStore) Move(src, dst string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	moved := false
+	for path, backup := range s.backups {
+		// src is either a single backup or a whole backup directory
+		if path == src || strings.HasPrefix(path, src+string(filepath.Separator)) {
+			delete(s.backups, path)
+			s.backups[dst+strings.TrimPrefix(path, src)] = backup
+			moved = true
+		}
+	}
+	if !moved {
+		return &os.PathError{Op: "rename", Path: src, Err: os.ErrNotExist}
+	}
+	return nil
+}
+
+// useMemStore makes every command go through a fresh memStore for the rest
+// of the test
+func useMemStore(t *testing.T) *memStore {
+	t.Helper()
+	store := newMemStore()
+	previous := backupStore
+	backupStore = store
+	t.Cleanup(func() { backupStore = previous })
+	return store
+}
+
+func TestRestoreFromMemStore(t *testing.T) {
+	store := useMemStore(t)
+	dir := t.TempDir()
+	original := filepath.Join(dir, "notes.txt")
+	backupPath := filepath.Join(dir, ".pt", "notes.txt", "notes_txt.20240101_120000_abcdef")
+	if err := store.Save(backupPath, []byte("saved content\n"), &BackupMetadata{Comment: "first"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The original is gone: restore recreates it
+	if err := restoreBackup(backupPath, original, ""); err != nil {
+		t.Fatalf("restoreBackup: %v", err)
+	}
+	if got, _ := os.ReadFile(original); string(got) != "saved content\n" {
+		t.Errorf("restored %q", got)
+	}
+
+	target := filepath.Join(dir, "copy", "notes.txt")
+	if err := restoreBackupInto(backupPath, original, target, ""); err != nil {
+		t.Fatalf("restoreBackupInto: %v", err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "saved content\n" {
+		t.Errorf("restored into %q", got)
+	}
+
+	err := restoreBackup(backupPath+"x", original, "")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("restoring a missing backup: got %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestPreviewRestoreIdentical(t *testing.T) {
+	store := useMemStore(t)
+	dir := t.TempDir()
+	original := filepath.Join(dir, "same.txt")
+	if err := os.WriteFile(original, []byte("unchanged\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backupPath := filepath.Join(dir, ".pt", "same.txt", "same_txt.20240101_120000_abcdef")
+	if err := store.Save(backupPath, []byte("unchanged\n"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing to confirm, so this must not reach the (non-terminal) prompt
+	backup, _ := store.Stat(backupPath)
+	ok, err := previewRestore(backup, original, "pdiff2", false)
+	if ok || err != nil {
+		t.Errorf("previewRestore = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestRestoreChecksSizeBeforeReading(t *testing.T) {
+	store := useMemStore(t)
+	limit := appConfig.MaxClipboardSize
+	appConfig.MaxClipboardSize = 8
+	t.Cleanup(func() { appConfig.MaxClipboardSize = limit })
+
+	dir := t.TempDir()
+	original := filepath.Join(dir, "big.txt")
+	backupPath := filepath.Join(dir, ".pt", "big.txt", "big_txt.20240101_120000_abcdef")
+	if err := store.Save(backupPath, []byte("more than eight bytes"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, restore := range map[string]func() error{
+		"restoreBackup":     func() error { return restoreBackup(backupPath, original, "") },
+		"restoreBackupInto": func() error { return restoreBackupInto(backupPath, original, filepath.Join(dir, "out.txt"), "") },
+	} {
+		if err := restore(); err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Errorf("%s: got %v, want a too large error", name, err)
+		}
+	}
+	if store.reads != 0 {
+		t.Errorf("an oversized backup was read %d time(s)", store.reads)
+	}
+	if _, err := os.Stat(original); !os.IsNotExist(err) {
+		t.Errorf("original was written: %v", err)
+	}
+}
+
+func TestMemStoreMoveDirectory(t *testing.T) {
+	store := newMemStore()
+	src := filepath.Join("/p", ".pt", "a.go")
+	for _, name := range []string{"a_go.1", "a_go.2"} {
+		if err := store.Save(filepath.Join(src, name), []byte(name), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join("/p", ".pt", "b.go")
+	if err := store.Move(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.List(src); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("List(src) after move: got %v, want os.ErrNotExist", err)
+	}
+	moved, err := store.List(dst)
+	if err != nil || len(moved) != 2 {
+		t.Fatalf("List(dst) = %d backups, %v; want 2", len(moved), err)
+	}
+	if err := store.Delete(moved[0].Path); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := store.List(dst); len(left) != 1 {
+		t.Errorf("%d backups left after delete, want 1", len(left))
+	}
+}
+
+func TestBackupSnippetFromMemStore(t *testing.T) {
+	store := useMemStore(t)
+	dir := t.TempDir()
+	backupPath := filepath.Join(dir, ".pt", "notes.txt", "notes_txt.20240101_120000_abcdef")
+	if err := store.Save(backupPath, []byte("  hello\n\tworld  \n"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := backupSnippet(backupPath); got != "hello world" {
+		t.Errorf("backupSnippet = %q, want %q", got, "hello world")
+	}
+	if got := backupSnippet(backupPath + "x"); got != "(unreadable)" {
+		t.Errorf("backupSnippet of a missing backup = %q", got)
+	}
+}
+
+func TestAtReadsFromMemStore(t *testing.T) {
+	store := useMemStore(t)
+	dir := t.TempDir()
+	original := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(original, []byte("current\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ptRoot := filepath.Join(dir, appConfig.BackupDirName)
+	if err := os.Mkdir(ptRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	backupDir, err := store.Dir(ptRoot, original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupPath := filepath.Join(backupDir, "notes_txt.20240101_120000_abcdef")
+	if err := store.Save(backupPath, []byte("saved content\n"), &BackupMetadata{Original: original}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out.txt")
+	if err := handleAtCommand([]string{original, "2099-01-01", "--out", out}); err != nil {
+		t.Fatalf("handleAtCommand: %v", err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "saved content\n" {
+		t.Errorf("pt at wrote %q", got)
+	}
+}
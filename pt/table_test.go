@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tableLines renders a whole table the way the printers do
+func tableLines(cols []tableColumn, rows [][]string) []string {
+	lines := []string{
+		tableRule("┌", "┬", "┐", cols),
+		tableHeader(cols),
+		tableRule("├", "┼", "┤", cols),
+	}
+	for _, cells := range rows {
+		lines = append(lines, tableRow(cols, cells))
+	}
+	return append(lines, tableRule("└", "┴", "┘", cols))
+}
+
+func checkTableWidth(t *testing.T, lines []string, width int) {
+	t.Helper()
+	for _, line := range lines {
+		if w := cellWidth(line); w > width {
+			t.Errorf("line is %d columns wide, want at most %d:\n%s", w, width, line)
+		}
+	}
+}
+
+func TestBackupTableFitsWidth(t *testing.T) {
+	backupPath := filepath.Join(t.TempDir(), "backup")
+	content := strings.Repeat("a fairly long line of backed up content ", 20)
+	if err := os.WriteFile(backupPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := []BackupInfo{
+		{
+			Path:    backupPath,
+			Name:    "a_rather_long_file_name_for_a_table_cell.go_20240101_120000_abcdef",
+			ModTime: time.Now(),
+			Size:    123456789,
+			Comment: strings.Repeat("a comment that goes on and on ", 5),
+			Pinned:  true,
+		},
+		{Path: backupPath, Name: "x.go_20240101_120000_abcdef", ModTime: time.Now(), Size: 1},
+	}
+
+	for _, width := range []int{40, 80, 200} {
+		for _, withPreview := range []bool{false, true} {
+			cols := backupTableColumns(width, withPreview)
+			var rows [][]string
+			for i, backup := range backups {
+				rows = append(rows, backupTableCells(cols, i+1, backup))
+			}
+			lines := tableLines(cols, rows)
+			checkTableWidth(t, lines, width)
+			if width == 200 && cellWidth(lines[0]) < width-10 {
+				t.Errorf("width %d, preview %v: table is only %d columns wide", width, withPreview, cellWidth(lines[0]))
+			}
+		}
+	}
+}
+
+func TestFileSearchTableFitsWidth(t *testing.T) {
+	result := FileSearchResult{
+		Path:    "/src/some/deeply/nested/project/directory/with/a/long_file_name.go",
+		Size:    98765432,
+		ModTime: time.Now(),
+	}
+
+	for _, width := range []int{40, 80, 200} {
+		cols := fileSearchColumns(width)
+		rows := [][]string{
+			fileSearchCells(cols, 1, result.Path, result),
+			fileSearchCells(cols, 100, "short.go", result),
+		}
+		checkTableWidth(t, tableLines(cols, rows), width)
+	}
+}
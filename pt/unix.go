@@ -3,10 +3,144 @@
 
 package main
 
+import (
+    "errors"
+    "fmt"
+    "net/url"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "syscall"
+    "time"
+)
+
 // setWindowsHiddenAttribute is a no-op on Unix-like systems (Linux, macOS, BSD).
 // On Unix, hidden files/directories use a dot prefix (e.g., .pt),
 // which is already handled by the directory name itself.
 func setWindowsHiddenAttribute(path string) error {
     // No-op: Unix uses dot prefix for hidden files
     return nil
-}
\ No newline at end of file
+}
+
+// processAlive reports whether a process with the given pid is running.
+// Signal 0 performs the existence check without delivering anything.
+func processAlive(pid int) bool {
+    proc, err := os.FindProcess(pid)
+    if err != nil {
+        return false
+    }
+    err = proc.Signal(syscall.Signal(0))
+    return err == nil || err == syscall.EPERM
+}
+
+// shellCommand runs line through /bin/sh so pipes and && work.
+func shellCommand(line string) *exec.Cmd {
+    return exec.Command("sh", "-c", line)
+}
+
+// detachProcess starts cmd in its own session so it outlives pt and
+// ignores the terminal's signals.
+func detachProcess(cmd *exec.Cmd) {
+    cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// shellQuote quotes s as a single sh word.
+func shellQuote(s string) string {
+    return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isCrossDeviceError reports whether a rename failed because source and
+// destination are on different filesystems.
+func isCrossDeviceError(err error) bool {
+    return errors.Is(err, syscall.EXDEV)
+}
+
+// moveToTrash moves path to the user's trash: ~/.Trash on macOS, otherwise
+// the XDG trash ($XDG_DATA_HOME/Trash, usually ~/.local/share/Trash) with a
+// .trashinfo record so file managers can put it back.
+func moveToTrash(path string) error {
+    absPath, err := filepath.Abs(path)
+    if err != nil {
+        return err
+    }
+    home, err := os.UserHomeDir()
+    if err != nil {
+        return fmt.Errorf("failed to locate home directory: %w", err)
+    }
+
+    if runtime.GOOS == "darwin" {
+        trashDir := filepath.Join(home, ".Trash")
+        name := filepath.Base(absPath)
+        for n := 2; ; n++ {
+            if _, err := os.Lstat(filepath.Join(trashDir, name)); os.IsNotExist(err) {
+                break
+            }
+            name = fmt.Sprintf("%s %d", filepath.Base(absPath), n)
+        }
+        return moveFile(absPath, filepath.Join(trashDir, name))
+    }
+
+    dataHome := os.Getenv("XDG_DATA_HOME")
+    if dataHome == "" {
+        dataHome = filepath.Join(home, ".local", "share")
+    }
+    filesDir := filepath.Join(dataHome, "Trash", "files")
+    infoDir := filepath.Join(dataHome, "Trash", "info")
+    if err := os.MkdirAll(filesDir, 0700); err != nil {
+        return fmt.Errorf("failed to create trash directory: %w", err)
+    }
+    if err := os.MkdirAll(infoDir, 0700); err != nil {
+        return fmt.Errorf("failed to create trash directory: %w", err)
+    }
+
+    // Claim a free name by creating its .trashinfo exclusively
+    base := filepath.Base(absPath)
+    name := base
+    var infoPath string
+    for n := 2; ; n++ {
+        infoPath = filepath.Join(infoDir, name+".trashinfo")
+        f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+        if err == nil {
+            info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+                (&url.URL{Path: absPath}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+            _, err = f.WriteString(info)
+            f.Close()
+            if err != nil {
+                os.Remove(infoPath)
+                return fmt.Errorf("failed to write trash info: %w", err)
+            }
+            break
+        }
+        if !os.IsExist(err) {
+            return fmt.Errorf("failed to write trash info: %w", err)
+        }
+        name = fmt.Sprintf("%s.%d", base, n)
+    }
+
+    if err := moveFile(absPath, filepath.Join(filesDir, name)); err != nil {
+        os.Remove(infoPath)
+        return err
+    }
+    return nil
+}
+
+// showToast posts a Notification Center banner through osascript on macOS.
+// Other Unix systems have no toast; use notification_backend: libnotify.
+func showToast(title, text string) error {
+    if runtime.GOOS != "darwin" {
+        return fmt.Errorf("toast notifications need Windows or macOS (try libnotify)")
+    }
+
+    // AppleScript string literals escape \ and "
+    quote := func(s string) string {
+        return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+    }
+    script := "display notification " + quote(text) + " with title " + quote(title)
+    out, err := exec.Command("osascript", "-e", script).CombinedOutput()
+    if err != nil {
+        return fmt.Errorf("osascript: %v: %s", err, strings.TrimSpace(string(out)))
+    }
+    return nil
+}
@@ -4,6 +4,12 @@
 package main
 
 import (
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
     "syscall"
     "golang.org/x/sys/windows"
 )
@@ -28,4 +34,90 @@ func setWindowsHiddenAttribute(path string) error {
 
     // Set the new attributes
     return windows.SetFileAttributes(ptr, newAttributes)
-}
\ No newline at end of file
+}
+
+// processAlive reports whether a process with the given pid is running.
+// On Windows FindProcess opens a handle and fails if the process is gone.
+func processAlive(pid int) bool {
+    handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+    if err != nil {
+        return false
+    }
+    defer windows.CloseHandle(handle)
+
+    var code uint32
+    if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+        return false
+    }
+    return code == 259 // STILL_ACTIVE
+}
+
+// shellCommand runs line through cmd.exe. The command line is passed
+// verbatim because cmd does not follow the usual argv quoting rules.
+func shellCommand(line string) *exec.Cmd {
+    cmd := exec.Command("cmd.exe")
+    cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd.exe /S /C "` + line + `"`}
+    return cmd
+}
+
+// detachProcess starts cmd without a console and in its own process
+// group so it outlives pt.
+func detachProcess(cmd *exec.Cmd) {
+    cmd.SysProcAttr = &syscall.SysProcAttr{
+        CreationFlags: windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP,
+    }
+}
+
+// shellQuote quotes s as a single cmd.exe argument. Paths cannot contain
+// double quotes on Windows, so wrapping is enough.
+func shellQuote(s string) string {
+    return `"` + s + `"`
+}
+
+// isCrossDeviceError reports whether a rename failed because source and
+// destination are on different volumes.
+func isCrossDeviceError(err error) bool {
+    return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
+
+// moveToTrash sends path to the Recycle Bin through PowerShell, using the
+// VisualBasic FileSystem API that Explorer's delete goes through.
+func moveToTrash(path string) error {
+    absPath, err := filepath.Abs(path)
+    if err != nil {
+        return err
+    }
+
+    method := "DeleteFile"
+    if info, err := os.Stat(absPath); err == nil && info.IsDir() {
+        method = "DeleteDirectory"
+    }
+    script := "Add-Type -AssemblyName Microsoft.VisualBasic; " +
+        "[Microsoft.VisualBasic.FileIO.FileSystem]::" + method + "('" +
+        strings.ReplaceAll(absPath, "'", "''") + "', 'OnlyErrorDialogs', 'SendToRecycleBin')"
+    out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput()
+    if err != nil {
+        return fmt.Errorf("failed to move to Recycle Bin: %v: %s", err, strings.TrimSpace(string(out)))
+    }
+    return nil
+}
+
+// showToast shows a Windows toast through PowerShell's WinRT bindings, under
+// PowerShell's own app id (a plain exe cannot post toasts without one). Title
+// and text are passed through the environment to avoid quoting.
+func showToast(title, text string) error {
+    script := "[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null; " +
+        "$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02); " +
+        "$texts = $xml.GetElementsByTagName('text'); " +
+        "$texts.Item(0).AppendChild($xml.CreateTextNode($env:PT_TOAST_TITLE)) > $null; " +
+        "$texts.Item(1).AppendChild($xml.CreateTextNode($env:PT_TOAST_TEXT)) > $null; " +
+        "$appId = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\\WindowsPowerShell\\v1.0\\powershell.exe'; " +
+        "[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($appId).Show([Windows.UI.Notifications.ToastNotification]::new($xml))"
+    cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script)
+    cmd.Env = append(os.Environ(), "PT_TOAST_TITLE="+title, "PT_TOAST_TEXT="+text)
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        return fmt.Errorf("failed to show toast: %v: %s", err, strings.TrimSpace(string(out)))
+    }
+    return nil
+}